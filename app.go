@@ -5,13 +5,28 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cashflow/internal/api"
 	"cashflow/internal/database"
 	"cashflow/internal/db/sqlc"
 	"cashflow/internal/models"
 	"cashflow/internal/services"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// BudgetAlertEvent is emitted with a services.BudgetStatus when a new or
+// changed transaction takes a budget to its alert threshold
+const BudgetAlertEvent = "budget:alert"
+
 // App struct
 type App struct {
 	ctx                  context.Context
@@ -19,7 +34,28 @@ type App struct {
 	transactionService   *services.TransactionService
 	paymentMethodService *services.PaymentMethodService
 	categoryService      *services.CategoryService
+	recurringService     *services.RecurringService
+	currencyService      *services.CurrencyService
+	preferencesService   *services.PreferencesService
+	exchangeRateService  *services.ExchangeRateService
+	templateService      *services.TemplateService
+	filterService        *services.FilterService
+	attachmentService    *services.AttachmentService
+	importService        *services.ImportService
+	exportService        *services.ExportService
+	reportService        *services.ReportService
+	paymentService       *services.PaymentService
+	budgetService        *services.BudgetService
+	contactService       *services.ContactService
+	accountService       *services.AccountService
+	reconcileService     *services.ReconciliationService
+	apiTokenService      *services.APITokenService
+	apiServer            *api.Server
 	db                   *database.Database
+
+	// The user whose books are open; guarded by userMu
+	userMu        sync.RWMutex
+	currentUserID string
 }
 
 // NewApp creates a new App application struct
@@ -30,12 +66,32 @@ func NewApp() *App {
 		panic(fmt.Sprintf("Failed to initialize database: %v", err))
 	}
 
+	recurringService := services.NewRecurringService(database)
+
 	return &App{
-		userService:          services.NewUserService(),
+		userService:          services.NewUserService(database),
 		transactionService:   services.NewTransactionService(database),
 		paymentMethodService: services.NewPaymentMethodService(database),
 		categoryService:      services.NewCategoryService(database),
+		recurringService:     recurringService,
+		currencyService:      services.NewCurrencyService(database),
+		preferencesService:   services.NewPreferencesService(database),
+		exchangeRateService:  services.NewExchangeRateService(database),
+		templateService:      services.NewTemplateService(database),
+		filterService:        services.NewFilterService(database),
+		attachmentService:    services.NewAttachmentService(database),
+		importService:        services.NewImportService(database),
+		exportService:        services.NewExportService(database),
+		reportService:        services.NewReportService(database),
+		paymentService:       services.NewPaymentService(database),
+		budgetService:        services.NewBudgetService(database),
+		contactService:       services.NewContactService(database),
+		accountService:       services.NewAccountService(database),
+		reconcileService:     services.NewReconciliationService(database),
+		apiTokenService:      services.NewAPITokenService(database),
+		apiServer:            api.NewServer(database, recurringService),
 		db:                   database,
+		currentUserID:        services.DefaultUserID,
 	}
 }
 
@@ -43,10 +99,47 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	// Warn about budgets whichever way a transaction was saved: here, by
+	// an import, a recurring occurrence or the HTTP API
+	a.budgetService.WatchTransactions(func(_ context.Context, alert services.BudgetStatus) {
+		runtime.EventsEmit(a.ctx, BudgetAlertEvent, alert)
+	})
+
+	// Materialize recurring transactions, catching up on anything missed
+	// while the app was closed
+	a.recurringService.Start(ctx)
+
+	// Empty the trash of transactions past their retention period
+	if _, err := a.transactionService.PurgeExpiredTransactions(ctx); err != nil {
+		log.Printf("trash: %v", err)
+	}
+
+	// Drop attachment files left behind by purged transactions
+	if _, err := a.attachmentService.CollectGarbage(ctx); err != nil {
+		log.Printf("attachments: %v", err)
+	}
+
+	// Serve the HTTP API right away when a port is configured, e.g.
+	// CASHFLOW_API_PORT=8765
+	if port := os.Getenv("CASHFLOW_API_PORT"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			log.Printf("api: invalid CASHFLOW_API_PORT %q", port)
+		} else if err := a.apiServer.Start(n); err != nil {
+			log.Printf("api: %v", err)
+		}
+	}
 }
 
 // shutdown is called when the app is closing
 func (a *App) shutdown(ctx context.Context) {
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := a.apiServer.Stop(stopCtx); err != nil {
+		log.Printf("api: %v", err)
+	}
+	a.recurringService.Stop()
 	if a.db != nil {
 		a.db.Close()
 	}
@@ -61,16 +154,26 @@ func (a *App) Greet(name string) string {
 
 // CreateTransaction creates a new transaction
 func (a *App) CreateTransaction(params services.CreateTransactionParams) (*TransactionResponse, error) {
-	transaction, err := a.transactionService.CreateTransaction(a.ctx, params)
+	params.CreatedBy = a.currentUser()
+	transaction, err := a.transactionService.CreateTransaction(a.actorContext(), params)
 	if err != nil {
 		return nil, err
 	}
+
+	// Back-dated recurring transactions get their past occurrences right
+	// away; the transaction is saved either way, and the scheduler tries
+	// again later
+	if params.IsRecurring {
+		if _, err := a.recurringService.ProcessDueOccurrences(a.ctx, time.Now()); err != nil {
+			log.Printf("recurring: %v", err)
+		}
+	}
 	return a.convertTransaction(transaction), nil
 }
 
 // GetTransaction retrieves a transaction by ID
 func (a *App) GetTransaction(id string) (*TransactionResponse, error) {
-	transaction, err := a.transactionService.GetTransaction(a.ctx, id)
+	transaction, err := a.transactionService.GetTransaction(a.ctx, a.currentUser(), id)
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +182,7 @@ func (a *App) GetTransaction(id string) (*TransactionResponse, error) {
 
 // ListTransactions lists transactions with filters
 func (a *App) ListTransactions(params services.ListTransactionParams) ([]TransactionResponse, error) {
+	params.CreatedBy = a.currentUser()
 	transactions, err := a.transactionService.ListTransactions(a.ctx, params)
 	if err != nil {
 		return nil, err
@@ -93,7 +197,7 @@ func (a *App) ListTransactions(params services.ListTransactionParams) ([]Transac
 
 // UpdateTransaction updates an existing transaction
 func (a *App) UpdateTransaction(id string, params services.UpdateTransactionParams) (*TransactionResponse, error) {
-	transaction, err := a.transactionService.UpdateTransaction(a.ctx, id, params)
+	transaction, err := a.transactionService.UpdateTransaction(a.actorContext(), a.currentUser(), id, params)
 	if err != nil {
 		return nil, err
 	}
@@ -102,46 +206,92 @@ func (a *App) UpdateTransaction(id string, params services.UpdateTransactionPara
 
 // DeleteTransaction deletes a transaction
 func (a *App) DeleteTransaction(id string) error {
-	return a.transactionService.DeleteTransaction(a.ctx, id)
+	return a.transactionService.DeleteTransaction(a.actorContext(), a.currentUser(), id)
 }
 
-// GetTransactionStats gets transaction statistics
-func (a *App) GetTransactionStats(params services.StatsParams) (*TransactionStats, error) {
-	stats, err := a.transactionService.GetTransactionStats(a.ctx, params)
+// GetTransactionHistory lists the recorded changes to a transaction,
+// oldest first
+func (a *App) GetTransactionHistory(id string) ([]services.TransactionHistoryEntry, error) {
+	return a.transactionService.GetTransactionHistory(a.ctx, a.currentUser(), id)
+}
+
+// RevertTransaction sets a transaction back to a version from its history
+func (a *App) RevertTransaction(id string, version int64) (*TransactionResponse, error) {
+	transaction, err := a.transactionService.RevertTransaction(a.actorContext(), a.currentUser(), id, version)
 	if err != nil {
 		return nil, err
 	}
+	return a.convertTransaction(transaction), nil
+}
 
-	// Helper function to convert interface{} to float64
-	toFloat64 := func(i interface{}) float64 {
-		if i == nil {
-			return 0
-		}
-		switch v := i.(type) {
-		case float64:
-			return v
-		case int64:
-			return float64(v)
-		default:
-			return 0
+// ListDeletedTransactions lists the transactions in the trash, most
+// recently deleted first
+func (a *App) ListDeletedTransactions(limit, offset int) ([]TransactionResponse, error) {
+	transactions, err := a.transactionService.ListDeletedTransactions(a.ctx, a.currentUser(), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TransactionResponse, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, *a.convertTransaction(&t))
+	}
+	return result, nil
+}
+
+// RestoreTransaction takes a transaction back out of the trash
+func (a *App) RestoreTransaction(id string) (*TransactionResponse, error) {
+	transaction, err := a.transactionService.RestoreTransaction(a.actorContext(), a.currentUser(), id)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTransaction(transaction), nil
+}
+
+// PurgeTransactions permanently removes transactions from the trash and
+// returns how many were removed
+func (a *App) PurgeTransactions(params services.PurgeParams) (int, error) {
+	params.CreatedBy = a.currentUser()
+	purged, err := a.transactionService.PurgeTransactions(a.actorContext(), params)
+	if err != nil {
+		return 0, err
+	}
+
+	// Drop the files only purged transactions were using
+	if purged > 0 {
+		if _, err := a.attachmentService.CollectGarbage(a.ctx); err != nil {
+			log.Printf("attachments: %v", err)
 		}
 	}
+	return purged, nil
+}
+
+// GetTransactionStats gets transaction statistics
+func (a *App) GetTransactionStats(params services.StatsParams) (*TransactionStats, error) {
+	params.CreatedBy = a.currentUser()
+	stats, err := a.transactionService.GetTransactionStats(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
 
 	return &TransactionStats{
-		TotalIncome:        toFloat64(stats.TotalIncome),
-		TotalExpenses:      toFloat64(stats.TotalExpenses),
-		NetProfit:          toFloat64(stats.NetProfit),
-		TotalTransactions:  int(stats.TotalTransactions),
-		TotalIncomeCount:   int(stats.TotalIncomeCount),
-		TotalExpenseCount:  int(stats.TotalExpenseCount),
-		AverageTransaction: toFloat64(stats.AverageTransaction),
-		PendingIncome:      toFloat64(stats.PendingIncome),
-		PendingExpenses:    toFloat64(stats.PendingExpenses),
+		Currency:           stats.Currency,
+		TotalIncome:        stats.TotalIncome,
+		TotalExpenses:      stats.TotalExpenses,
+		NetProfit:          stats.NetProfit,
+		TotalTransactions:  stats.TotalTransactions,
+		TotalIncomeCount:   stats.TotalIncomeCount,
+		TotalExpenseCount:  stats.TotalExpenseCount,
+		AverageTransaction: stats.AverageTransaction,
+		PendingIncome:      stats.PendingIncome,
+		PendingExpenses:    stats.PendingExpenses,
+		Unconverted:        stats.Unconverted,
 	}, nil
 }
 
 // GetTransactionsByCategory gets transactions grouped by category
 func (a *App) GetTransactionsByCategory(params services.StatsParams) ([]CategorySummary, error) {
+	params.CreatedBy = a.currentUser()
 	categories, err := a.transactionService.GetTransactionsByCategory(a.ctx, params)
 	if err != nil {
 		return nil, err
@@ -150,17 +300,471 @@ func (a *App) GetTransactionsByCategory(params services.StatsParams) ([]Category
 	result := make([]CategorySummary, 0, len(categories))
 	for _, c := range categories {
 		result = append(result, CategorySummary{
-			Category:    nullStringToString(c.CategoryID),
+			Category:    c.CategoryID,
 			Type:        c.Type,
-			Count:       int(c.Count),
-			TotalAmount: nullFloat64ToFloat64(c.TotalAmount),
+			Count:       c.Count,
+			TotalAmount: c.TotalAmount,
+			Currency:    c.Currency,
 		})
 	}
 	return result, nil
 }
 
+// Bulk Operation Methods
+
+// BulkDelete moves transactions to the trash. Like all bulk operations it
+// changes all of them or, when any fails, none.
+func (a *App) BulkDelete(ids []string) (*services.BulkResult, error) {
+	return a.transactionService.BulkDelete(a.actorContext(), a.currentUser(), ids)
+}
+
+// BulkUpdateCategory moves transactions to a category
+func (a *App) BulkUpdateCategory(ids []string, categoryID string) (*services.BulkResult, error) {
+	return a.transactionService.BulkUpdateCategory(a.actorContext(), a.currentUser(), ids, categoryID)
+}
+
+// BulkSetPaymentStatus sets the payment status of transactions
+func (a *App) BulkSetPaymentStatus(ids []string, status string) (*services.BulkResult, error) {
+	return a.transactionService.BulkSetPaymentStatus(a.actorContext(), a.currentUser(), ids, status)
+}
+
+// BulkAddTags adds tags to transactions
+func (a *App) BulkAddTags(ids []string, tags []string) (*services.BulkResult, error) {
+	return a.transactionService.BulkAddTags(a.actorContext(), a.currentUser(), ids, tags)
+}
+
+// BulkRemoveTags removes tags from transactions
+func (a *App) BulkRemoveTags(ids []string, tags []string) (*services.BulkResult, error) {
+	return a.transactionService.BulkRemoveTags(a.actorContext(), a.currentUser(), ids, tags)
+}
+
+// BulkChangePaymentMethod sets the payment method of transactions
+func (a *App) BulkChangePaymentMethod(ids []string, paymentMethodID string) (*services.BulkResult, error) {
+	return a.transactionService.BulkChangePaymentMethod(a.actorContext(), a.currentUser(), ids, paymentMethodID)
+}
+
 // Category Management Methods
 
+// GetTrendReport totals each transaction type per period, monthly by default
+func (a *App) GetTrendReport(params services.ReportParams) (*services.TrendReport, error) {
+	params.CreatedBy = a.currentUser()
+	return a.reportService.GetTrend(a.ctx, params)
+}
+
+// GetCashFlowReport totals income and expenses per period, daily by default
+func (a *App) GetCashFlowReport(params services.ReportParams) (*services.CashFlowReport, error) {
+	params.CreatedBy = a.currentUser()
+	return a.reportService.GetCashFlow(a.ctx, params)
+}
+
+// GetTopCounterparties ranks customers and vendors by net amount
+func (a *App) GetTopCounterparties(params services.ReportParams) (*services.CounterpartyReport, error) {
+	params.CreatedBy = a.currentUser()
+	return a.reportService.GetTopCounterparties(a.ctx, params)
+}
+
+// GetProfitAndLoss builds a profit and loss statement
+func (a *App) GetProfitAndLoss(params services.ProfitLossParams) (*services.ProfitLossReport, error) {
+	params.CreatedBy = a.currentUser()
+	return a.reportService.GetProfitAndLoss(a.ctx, params)
+}
+
+// ExportProfitAndLoss asks where to save a profit and loss statement and
+// writes it as CSV or PDF. It returns the path, or "" if the dialog is
+// cancelled.
+func (a *App) ExportProfitAndLoss(params services.ProfitLossParams, format string) (string, error) {
+	format = strings.ToLower(format)
+	filters := map[string]runtime.FileFilter{
+		services.ExportFormatCSV: {DisplayName: "CSV Files (*.csv)", Pattern: "*.csv"},
+		services.ExportFormatPDF: {DisplayName: "PDF Documents (*.pdf)", Pattern: "*.pdf"},
+	}
+	filter, ok := filters[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported export format %q", format)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Profit and Loss",
+		DefaultFilename: fmt.Sprintf("profit-and-loss-%s.%s", time.Now().Format("2006-01-02"), format),
+		Filters:         []runtime.FileFilter{filter},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	params.CreatedBy = a.currentUser()
+	err = a.reportService.ExportProfitAndLoss(a.ctx, file, params, format)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// GetAgingReport builds an accounts receivable or payable aging report
+func (a *App) GetAgingReport(params services.AgingParams) (*services.AgingReport, error) {
+	params.CreatedBy = a.currentUser()
+	return a.reportService.GetAging(a.ctx, params)
+}
+
+// GetAgingDetails lists the transactions behind an aging report, for one
+// customer/vendor or bucket when given
+func (a *App) GetAgingDetails(params services.AgingParams) ([]AgingItemResponse, error) {
+	params.CreatedBy = a.currentUser()
+	items, err := a.reportService.GetAgingDetails(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AgingItemResponse, 0, len(items))
+	for _, item := range items {
+		result = append(result, AgingItemResponse{
+			TransactionResponse: *a.convertTransaction(&item.Transaction),
+			EffectiveDueDate:    item.DueDate,
+			DaysOverdue:         item.DaysOverdue,
+			Bucket:              item.Bucket,
+			Outstanding:         item.Outstanding,
+			OutstandingCurrency: item.Currency,
+		})
+	}
+	return result, nil
+}
+
+// Contact Methods
+
+// CreateContact adds a customer or vendor
+func (a *App) CreateContact(params services.ContactParams) (*ContactResponse, error) {
+	params.CreatedBy = a.currentUser()
+	contact, err := a.contactService.CreateContact(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertContact(contact), nil
+}
+
+// GetContact retrieves a contact by ID
+func (a *App) GetContact(id string) (*ContactResponse, error) {
+	contact, err := a.contactService.GetContact(a.ctx, a.currentUser(), id)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertContact(contact), nil
+}
+
+// ListContacts lists the contacts of the current user; contactType may be
+// customer or vendor to list only those
+func (a *App) ListContacts(contactType string) ([]ContactResponse, error) {
+	contacts, err := a.contactService.ListContacts(a.ctx, a.currentUser(), contactType)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertContacts(contacts), nil
+}
+
+// UpdateContact changes a contact, renaming it on its transactions too
+func (a *App) UpdateContact(id string, params services.ContactParams) (*ContactResponse, error) {
+	contact, err := a.contactService.UpdateContact(a.actorContext(), a.currentUser(), id, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertContact(contact), nil
+}
+
+// DeleteContact removes a contact; its transactions keep its name
+func (a *App) DeleteContact(id string) error {
+	return a.contactService.DeleteContact(a.actorContext(), a.currentUser(), id)
+}
+
+// FindDuplicateContacts groups contacts whose names look alike
+func (a *App) FindDuplicateContacts() ([][]ContactResponse, error) {
+	groups, err := a.contactService.FindDuplicateContacts(a.ctx, a.currentUser())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]ContactResponse, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, a.convertContacts(group))
+	}
+	return result, nil
+}
+
+// MergeContacts merges contacts into the target contact, moving their
+// transactions to it
+func (a *App) MergeContacts(targetID string, sourceIDs []string) (*ContactResponse, error) {
+	contact, err := a.contactService.MergeContacts(a.actorContext(), a.currentUser(), targetID, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertContact(contact), nil
+}
+
+// Account Methods
+
+// CreateAccount adds a bank, cash or card account
+func (a *App) CreateAccount(params services.AccountParams) (*AccountResponse, error) {
+	params.CreatedBy = a.currentUser()
+	account, err := a.accountService.CreateAccount(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertAccount(account), nil
+}
+
+// ListAccounts lists the accounts of the current user
+func (a *App) ListAccounts() ([]AccountResponse, error) {
+	accounts, err := a.accountService.ListAccounts(a.ctx, a.currentUser())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		result = append(result, *a.convertAccount(&account))
+	}
+	return result, nil
+}
+
+// UpdateAccount changes an account
+func (a *App) UpdateAccount(id string, params services.AccountParams) (*AccountResponse, error) {
+	account, err := a.accountService.UpdateAccount(a.ctx, a.currentUser(), id, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertAccount(account), nil
+}
+
+// DeleteAccount removes an account that nothing uses
+func (a *App) DeleteAccount(id string) error {
+	return a.accountService.DeleteAccount(a.ctx, a.currentUser(), id)
+}
+
+// CreateTransfer moves money between two accounts
+func (a *App) CreateTransfer(params services.TransferParams) (*TransferResponse, error) {
+	params.CreatedBy = a.currentUser()
+	transfer, err := a.accountService.CreateTransfer(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTransfer(transfer), nil
+}
+
+// ListTransfers lists transfers, newest first; accountID narrows them down
+// to those in or out of one account
+func (a *App) ListTransfers(accountID string) ([]TransferResponse, error) {
+	transfers, err := a.accountService.ListTransfers(a.ctx, a.currentUser(), accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TransferResponse, 0, len(transfers))
+	for _, t := range transfers {
+		result = append(result, *a.convertTransfer(&t))
+	}
+	return result, nil
+}
+
+// DeleteTransfer removes a transfer
+func (a *App) DeleteTransfer(id string) error {
+	return a.accountService.DeleteTransfer(a.ctx, a.currentUser(), id)
+}
+
+// GetAccountBalances computes what each account held at the end of asOf,
+// or today when it is empty
+func (a *App) GetAccountBalances(asOf string) (*services.AccountBalances, error) {
+	return a.accountService.GetAccountBalances(a.ctx, a.currentUser(), asOf)
+}
+
+// Reconciliation Methods
+
+// StartReconciliation opens a reconciliation of an account against a bank
+// statement
+func (a *App) StartReconciliation(params services.ReconciliationParams) (*services.ReconciliationDetail, error) {
+	params.CreatedBy = a.currentUser()
+	reconciliation, err := a.reconcileService.StartReconciliation(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.reconcileService.GetReconciliation(a.ctx, reconciliation.ID)
+}
+
+// GetReconciliation retrieves a reconciliation with its statement lines,
+// cleared balance and difference
+func (a *App) GetReconciliation(id string) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.GetReconciliation(a.ctx, id)
+}
+
+// ListReconciliations lists the reconciliations of an account, latest
+// statement first
+func (a *App) ListReconciliations(accountID string) ([]ReconciliationResponse, error) {
+	account, err := a.accountService.GetAccount(a.ctx, a.currentUser(), accountID)
+	if err != nil {
+		return nil, err
+	}
+	reconciliations, err := a.reconcileService.ListReconciliations(a.ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReconciliationResponse, 0, len(reconciliations))
+	for _, r := range reconciliations {
+		result = append(result, ReconciliationResponse{
+			ID:                 r.ID,
+			AccountID:          r.AccountID,
+			StatementStartDate: r.StatementStartDate.Format("2006-01-02"),
+			StatementEndDate:   r.StatementEndDate.Format("2006-01-02"),
+			EndingBalance:      a.currencyService.FromMinorUnits(a.ctx, r.EndingBalance, account.Currency),
+			Status:             r.Status,
+			CreatedAt:          nullTimeToString(r.CreatedAt),
+			CompletedAt:        nullTimeToString(r.CompletedAt),
+		})
+	}
+	return result, nil
+}
+
+// UpdateReconciliation changes the statement period and ending balance of
+// an open reconciliation
+func (a *App) UpdateReconciliation(id string, params services.ReconciliationParams) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.UpdateReconciliation(a.ctx, id, params)
+}
+
+// AddStatementLines adds statement lines to an open reconciliation by hand
+func (a *App) AddStatementLines(id string, lines []services.StatementLineParams) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.AddStatementLines(a.ctx, id, lines)
+}
+
+// ImportStatementLines adds the lines of an OFX/QFX or QIF statement to an
+// open reconciliation
+func (a *App) ImportStatementLines(id, path string) (*services.ReconciliationDetail, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return a.reconcileService.ImportStatementLines(a.ctx, id, path, file)
+}
+
+// DeleteStatementLine removes a statement line from an open reconciliation
+func (a *App) DeleteStatementLine(lineID string) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.DeleteStatementLine(a.ctx, lineID)
+}
+
+// AutoMatchStatement matches statement lines to transactions by amount,
+// date and reference; windowDays is how far apart their dates may be
+func (a *App) AutoMatchStatement(id string, windowDays int) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.AutoMatch(a.ctx, id, windowDays)
+}
+
+// ListUnmatchedTransactions lists the transactions a statement line could
+// still be matched to by hand
+func (a *App) ListUnmatchedTransactions(id string, windowDays int) ([]TransactionResponse, error) {
+	transactions, err := a.reconcileService.ListUnmatchedTransactions(a.ctx, id, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TransactionResponse, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, *a.convertTransaction(&t))
+	}
+	return result, nil
+}
+
+// MatchStatementLine matches a statement line to a transaction by hand
+func (a *App) MatchStatementLine(lineID, transactionID string) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.MatchStatementLine(a.ctx, a.currentUser(), lineID, transactionID)
+}
+
+// UnmatchStatementLine undoes the match of a statement line
+func (a *App) UnmatchStatementLine(lineID string) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.UnmatchStatementLine(a.ctx, lineID)
+}
+
+// SetTransactionCleared marks a transaction as cleared, or not, without a
+// statement line
+func (a *App) SetTransactionCleared(id string, cleared bool) (*TransactionResponse, error) {
+	transaction, err := a.reconcileService.SetTransactionCleared(a.ctx, a.currentUser(), id, cleared)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTransaction(transaction), nil
+}
+
+// CompleteReconciliation finishes a reconciliation whose difference is
+// zero, locking its transactions
+func (a *App) CompleteReconciliation(id string) (*services.ReconciliationDetail, error) {
+	return a.reconcileService.CompleteReconciliation(a.ctx, id)
+}
+
+// DeleteReconciliation abandons an open reconciliation
+func (a *App) DeleteReconciliation(id string) error {
+	return a.reconcileService.DeleteReconciliation(a.ctx, id)
+}
+
+// UnlockTransaction lets a reconciled transaction be changed again
+func (a *App) UnlockTransaction(id string) (*TransactionResponse, error) {
+	transaction, err := a.reconcileService.UnlockTransaction(a.actorContext(), a.currentUser(), id)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTransaction(transaction), nil
+}
+
+// Budget Methods
+
+// CreateBudget sets a budget for a category
+func (a *App) CreateBudget(params services.BudgetParams) (*BudgetResponse, error) {
+	params.CreatedBy = a.currentUser()
+	budget, err := a.budgetService.CreateBudget(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertBudget(budget), nil
+}
+
+// ListBudgets lists the budgets of the current user
+func (a *App) ListBudgets() ([]BudgetResponse, error) {
+	budgets, err := a.budgetService.ListBudgets(a.ctx, a.currentUser())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]BudgetResponse, 0, len(budgets))
+	for _, b := range budgets {
+		result = append(result, *a.convertBudget(&b))
+	}
+	return result, nil
+}
+
+// UpdateBudget changes a budget
+func (a *App) UpdateBudget(id string, params services.BudgetParams) (*BudgetResponse, error) {
+	budget, err := a.budgetService.UpdateBudget(a.ctx, a.currentUser(), id, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertBudget(budget), nil
+}
+
+// DeleteBudget removes a budget
+func (a *App) DeleteBudget(id string) error {
+	return a.budgetService.DeleteBudget(a.ctx, a.currentUser(), id)
+}
+
+// GetBudgetVsActual compares each budget with the spending of its period
+// containing date, or today when date is empty
+func (a *App) GetBudgetVsActual(date string) ([]services.BudgetStatus, error) {
+	return a.budgetService.GetBudgetVsActual(a.ctx, a.currentUser(), date)
+}
+
 // CreateCategory creates a new category
 func (a *App) CreateCategory(params services.CreateCategoryParams) (*CategoryResponse, error) {
 	category, err := a.categoryService.CreateCategory(a.ctx, params)
@@ -200,189 +804,678 @@ func (a *App) ListActiveCategories() ([]CategoryResponse, error) {
 		return nil, err
 	}
 
-	result := make([]CategoryResponse, 0, len(categories))
-	for _, c := range categories {
-		result = append(result, *convertCategory(&c))
+	result := make([]CategoryResponse, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, *convertCategory(&c))
+	}
+	return result, nil
+}
+
+// ListCategoriesByType lists categories by type
+func (a *App) ListCategoriesByType(categoryType string) ([]CategoryResponse, error) {
+	categories, err := a.categoryService.ListCategoriesByType(a.ctx, categoryType)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CategoryResponse, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, *convertCategory(&c))
+	}
+	return result, nil
+}
+
+// UpdateCategory updates an existing category
+func (a *App) UpdateCategory(id string, params services.UpdateCategoryParams) (*CategoryResponse, error) {
+	category, err := a.categoryService.UpdateCategory(a.ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return convertCategory(category), nil
+}
+
+// DeleteCategory deletes a category
+func (a *App) DeleteCategory(id string) error {
+	return a.categoryService.DeleteCategory(a.ctx, id)
+}
+
+// DeactivateCategory deactivates a category
+func (a *App) DeactivateCategory(id string) error {
+	return a.categoryService.DeactivateCategory(a.ctx, id)
+}
+
+// CheckCategoryDependencies checks if a category has dependent transactions
+func (a *App) CheckCategoryDependencies(id string) (int64, error) {
+	return a.categoryService.CheckCategoryDependencies(a.ctx, id)
+}
+
+// Payment Method Management Methods
+
+// CreatePaymentMethod creates a new payment method
+func (a *App) CreatePaymentMethod(name, description string, isActive bool) (*PaymentMethodResponse, error) {
+	paymentMethod, err := a.paymentMethodService.CreatePaymentMethod(a.ctx, name, description, isActive)
+	if err != nil {
+		return nil, err
+	}
+	return convertPaymentMethod(paymentMethod), nil
+}
+
+// GetPaymentMethod retrieves a payment method by ID
+func (a *App) GetPaymentMethod(id string) (*PaymentMethodResponse, error) {
+	paymentMethod, err := a.paymentMethodService.GetPaymentMethod(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return convertPaymentMethod(paymentMethod), nil
+}
+
+// ListPaymentMethods lists all payment methods
+func (a *App) ListPaymentMethods() ([]PaymentMethodResponse, error) {
+	paymentMethods, err := a.paymentMethodService.ListPaymentMethods(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PaymentMethodResponse, 0, len(paymentMethods))
+	for _, pm := range paymentMethods {
+		result = append(result, *convertPaymentMethod(&pm))
+	}
+	return result, nil
+}
+
+// ListActivePaymentMethods lists only active payment methods
+func (a *App) ListActivePaymentMethods() ([]PaymentMethodResponse, error) {
+	paymentMethods, err := a.paymentMethodService.ListActivePaymentMethods(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PaymentMethodResponse, 0, len(paymentMethods))
+	for _, pm := range paymentMethods {
+		result = append(result, *convertPaymentMethod(&pm))
+	}
+	return result, nil
+}
+
+// UpdatePaymentMethod updates an existing payment method
+func (a *App) UpdatePaymentMethod(id, name, description string, isActive bool) (*PaymentMethodResponse, error) {
+	paymentMethod, err := a.paymentMethodService.UpdatePaymentMethod(a.ctx, id, name, description, isActive)
+	if err != nil {
+		return nil, err
+	}
+	return convertPaymentMethod(paymentMethod), nil
+}
+
+// DeletePaymentMethod deletes a payment method
+func (a *App) DeletePaymentMethod(id string) error {
+	return a.paymentMethodService.DeletePaymentMethod(a.ctx, id)
+}
+
+// DeactivatePaymentMethod deactivates a payment method
+func (a *App) DeactivatePaymentMethod(id string) error {
+	return a.paymentMethodService.DeactivatePaymentMethod(a.ctx, id)
+}
+
+// CheckPaymentMethodDependencies checks if a payment method has dependent transactions
+func (a *App) CheckPaymentMethodDependencies(id string) (int64, error) {
+	return a.paymentMethodService.CheckPaymentMethodDependencies(a.ctx, id)
+}
+
+// SearchTransactions searches transactions with the full-text index, best
+// matches first, or by substring when the build has no FTS5
+func (a *App) SearchTransactions(searchTerm string, limit, offset int) ([]SearchResultResponse, error) {
+	if limit == 0 {
+		limit = 50
+	}
+
+	results, err := a.transactionService.SearchTransactions(a.ctx, a.currentUser(), searchTerm, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]SearchResultResponse, 0, len(results))
+	for _, r := range results {
+		response = append(response, SearchResultResponse{
+			TransactionResponse: *a.convertTransaction(&r.Transaction),
+			Snippet:             r.Snippet,
+			Score:               r.Score,
+		})
+	}
+	return response, nil
+}
+
+// GetRecentTransactions gets recent transactions
+func (a *App) GetRecentTransactions(limit int) ([]TransactionResponse, error) {
+	if limit == 0 {
+		limit = 10
+	}
+
+	transactions, err := a.transactionService.GetRecentTransactions(a.ctx, a.currentUser(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TransactionResponse, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, *a.convertTransaction(&t))
+	}
+	return result, nil
+}
+
+// GetDescriptionSuggestions retrieves description suggestions for auto-complete
+func (a *App) GetDescriptionSuggestions(transactionType, search string) ([]services.SuggestionItem, error) {
+	return a.transactionService.GetDescriptionSuggestions(a.ctx, a.currentUser(), transactionType, search, 10)
+}
+
+// GetCustomerVendorSuggestions retrieves customer/vendor suggestions for auto-complete
+func (a *App) GetCustomerVendorSuggestions(transactionType, search string) ([]services.SuggestionItem, error) {
+	return a.transactionService.GetCustomerVendorSuggestions(a.ctx, a.currentUser(), transactionType, search, 10)
+}
+
+// Saved Filter Methods
+
+// SaveTransactionFilter saves a transaction filter preset under a name
+func (a *App) SaveTransactionFilter(name string, params services.ListTransactionParams, isDefault bool) (*services.SavedFilter, error) {
+	return a.filterService.SaveFilter(a.ctx, a.currentUser(), name, params, isDefault)
+}
+
+// ListTransactionFilters lists the saved filter presets, the default first
+func (a *App) ListTransactionFilters() ([]services.SavedFilter, error) {
+	return a.filterService.ListFilters(a.ctx, a.currentUser())
+}
+
+// GetDefaultTransactionFilter returns the default preset, or nil if none is set
+func (a *App) GetDefaultTransactionFilter() (*services.SavedFilter, error) {
+	return a.filterService.GetDefaultFilter(a.ctx, a.currentUser())
+}
+
+// RenameTransactionFilter renames a saved filter preset
+func (a *App) RenameTransactionFilter(id, name string) error {
+	return a.filterService.RenameFilter(a.ctx, a.currentUser(), id, name)
+}
+
+// SetDefaultTransactionFilter makes a preset the default for ListTransactions
+func (a *App) SetDefaultTransactionFilter(id string) error {
+	return a.filterService.SetDefaultFilter(a.ctx, a.currentUser(), id)
+}
+
+// ClearDefaultTransactionFilter removes the default preset
+func (a *App) ClearDefaultTransactionFilter() error {
+	return a.filterService.ClearDefaultFilter(a.ctx, a.currentUser())
+}
+
+// DeleteTransactionFilter deletes a saved filter preset
+func (a *App) DeleteTransactionFilter(id string) error {
+	return a.filterService.DeleteFilter(a.ctx, a.currentUser(), id)
+}
+
+// Template Management Methods
+
+// CreateTemplate creates a new transaction template
+func (a *App) CreateTemplate(params services.CreateTemplateParams) (*TemplateResponse, error) {
+	params.CreatedBy = a.currentUser()
+	template, err := a.templateService.CreateTemplate(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTemplate(template), nil
+}
+
+// GetTemplate retrieves a template by ID
+func (a *App) GetTemplate(id string) (*TemplateResponse, error) {
+	template, err := a.templateService.GetTemplate(a.ctx, a.currentUser(), id)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTemplate(template), nil
+}
+
+// ListTemplates lists templates, favorites first and then by usage
+func (a *App) ListTemplates(favoritesOnly bool) ([]TemplateResponse, error) {
+	templates, err := a.templateService.ListTemplates(a.ctx, a.currentUser(), favoritesOnly)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTemplates(templates), nil
+}
+
+// ListMostUsedTemplates lists the templates used most often
+func (a *App) ListMostUsedTemplates(limit int) ([]TemplateResponse, error) {
+	templates, err := a.templateService.ListMostUsedTemplates(a.ctx, a.currentUser(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTemplates(templates), nil
+}
+
+// UpdateTemplate updates an existing template
+func (a *App) UpdateTemplate(id string, params services.UpdateTemplateParams) (*TemplateResponse, error) {
+	template, err := a.templateService.UpdateTemplate(a.ctx, a.currentUser(), id, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTemplate(template), nil
+}
+
+// SetTemplateFavorite marks or unmarks a template as a favorite
+func (a *App) SetTemplateFavorite(id string, isFavorite bool) error {
+	return a.templateService.SetTemplateFavorite(a.ctx, a.currentUser(), id, isFavorite)
+}
+
+// DeleteTemplate deletes a template
+func (a *App) DeleteTemplate(id string) error {
+	return a.templateService.DeleteTemplate(a.ctx, a.currentUser(), id)
+}
+
+// CreateTransactionFromTemplate creates a transaction from a template, with
+// any fields set in params overriding the template's
+func (a *App) CreateTransactionFromTemplate(id string, params services.CreateTransactionParams) (*TransactionResponse, error) {
+	params.CreatedBy = a.currentUser()
+	transaction, err := a.templateService.CreateTransactionFromTemplate(a.actorContext(), id, params)
+	if err != nil {
+		return nil, err
+	}
+	return a.convertTransaction(transaction), nil
+}
+
+// Currency Methods
+
+// ListCurrencies lists the supported currencies
+func (a *App) ListCurrencies() ([]CurrencyResponse, error) {
+	currencies, err := a.currencyService.ListCurrencies(a.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CurrencyResponse, 0, len(currencies))
+	for _, c := range currencies {
+		result = append(result, CurrencyResponse{
+			Code:     c.Code,
+			Name:     c.Name,
+			Exponent: int(c.Exponent),
+		})
+	}
+	return result, nil
+}
+
+// GetPreferences returns the user's preferences
+func (a *App) GetPreferences() (*services.UserPreferences, error) {
+	return a.preferencesService.GetPreferences(a.ctx, a.currentUser())
+}
+
+// UpdatePreferences saves the user's preferences
+func (a *App) UpdatePreferences(prefs services.UserPreferences) (*services.UserPreferences, error) {
+	return a.preferencesService.UpdatePreferences(a.ctx, a.currentUser(), prefs)
+}
+
+// Exchange Rate Methods
+
+// SetExchangeRate creates or replaces the rate of a currency pair on a date
+func (a *App) SetExchangeRate(params services.SetExchangeRateParams) (*ExchangeRateResponse, error) {
+	rate, err := a.exchangeRateService.SetExchangeRate(a.ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return convertExchangeRate(rate), nil
+}
+
+// ListExchangeRates lists stored rates, optionally only those involving a currency
+func (a *App) ListExchangeRates(currency string) ([]ExchangeRateResponse, error) {
+	rates, err := a.exchangeRateService.ListExchangeRates(a.ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ExchangeRateResponse, 0, len(rates))
+	for _, r := range rates {
+		result = append(result, *convertExchangeRate(&r))
 	}
 	return result, nil
 }
 
-// ListCategoriesByType lists categories by type
-func (a *App) ListCategoriesByType(categoryType string) ([]CategoryResponse, error) {
-	categories, err := a.categoryService.ListCategoriesByType(a.ctx, categoryType)
-	if err != nil {
+// DeleteExchangeRate deletes a stored rate
+func (a *App) DeleteExchangeRate(id string) error {
+	return a.exchangeRateService.DeleteExchangeRate(a.ctx, id)
+}
+
+// ImportExchangeRates asks for a CSV file and imports the rates in it.
+// It returns nil if the dialog is cancelled.
+func (a *App) ImportExchangeRates() (*services.ExchangeRateImportResult, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Exchange Rates",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV Files (*.csv)", Pattern: "*.csv"},
+		},
+	})
+	if err != nil || path == "" {
 		return nil, err
 	}
 
-	result := make([]CategoryResponse, 0, len(categories))
-	for _, c := range categories {
-		result = append(result, *convertCategory(&c))
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
 	}
-	return result, nil
+	defer file.Close()
+
+	return a.exchangeRateService.ImportExchangeRatesCSV(a.ctx, file, filepath.Base(path))
 }
 
-// UpdateCategory updates an existing category
-func (a *App) UpdateCategory(id string, params services.UpdateCategoryParams) (*CategoryResponse, error) {
-	category, err := a.categoryService.UpdateCategory(a.ctx, id, params)
+// Import Methods
+
+// SelectImportFile asks for a bank statement to import and returns its path,
+// or an empty string if the dialog is cancelled
+func (a *App) SelectImportFile() (string, error) {
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import Transactions",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Bank Statements (*.csv, *.ofx, *.qfx, *.qif)", Pattern: "*.csv;*.txt;*.ofx;*.qfx;*.qif"},
+			{DisplayName: "All Files", Pattern: "*"},
+		},
+	})
+}
+
+// PreviewCSVImport parses a CSV file and validates its rows without
+// importing anything
+func (a *App) PreviewCSVImport(path string, opts services.CSVImportOptions) (*services.ImportPreview, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
 	}
-	return convertCategory(category), nil
-}
+	defer file.Close()
 
-// DeleteCategory deletes a category
-func (a *App) DeleteCategory(id string) error {
-	return a.categoryService.DeleteCategory(a.ctx, id)
+	opts.CreatedBy = a.currentUser()
+	return a.importService.PreviewCSV(a.ctx, file, opts)
 }
 
-// DeactivateCategory deactivates a category
-func (a *App) DeactivateCategory(id string) error {
-	return a.categoryService.DeactivateCategory(a.ctx, id)
-}
+// ImportCSV imports a CSV file. Nothing is imported if any row has errors.
+func (a *App) ImportCSV(path string, opts services.CSVImportOptions) (*services.ImportResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
 
-// CheckCategoryDependencies checks if a category has dependent transactions
-func (a *App) CheckCategoryDependencies(id string) (int64, error) {
-	return a.categoryService.CheckCategoryDependencies(a.ctx, id)
+	opts.CreatedBy = a.currentUser()
+	return a.importService.ImportCSV(a.actorContext(), file, opts)
 }
 
-// Payment Method Management Methods
-
-// CreatePaymentMethod creates a new payment method
-func (a *App) CreatePaymentMethod(name, description string, isActive bool) (*PaymentMethodResponse, error) {
-	paymentMethod, err := a.paymentMethodService.CreatePaymentMethod(a.ctx, name, description, isActive)
+// PreviewStatementImport parses an OFX/QFX or QIF file without importing
+// anything. Transactions imported before are marked as duplicates.
+func (a *App) PreviewStatementImport(path string, opts services.StatementImportOptions) (*services.ImportPreview, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
 	}
-	return convertPaymentMethod(paymentMethod), nil
+	defer file.Close()
+
+	opts.CreatedBy = a.currentUser()
+	return a.importService.PreviewStatement(a.ctx, path, file, opts)
 }
 
-// GetPaymentMethod retrieves a payment method by ID
-func (a *App) GetPaymentMethod(id string) (*PaymentMethodResponse, error) {
-	paymentMethod, err := a.paymentMethodService.GetPaymentMethod(a.ctx, id)
+// ImportStatement imports an OFX/QFX or QIF file, skipping transactions
+// imported before. Nothing is imported if any row has errors.
+func (a *App) ImportStatement(path string, opts services.StatementImportOptions) (*services.ImportResult, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
 	}
-	return convertPaymentMethod(paymentMethod), nil
+	defer file.Close()
+
+	opts.CreatedBy = a.currentUser()
+	return a.importService.ImportStatement(a.actorContext(), path, file, opts)
 }
 
-// ListPaymentMethods lists all payment methods
-func (a *App) ListPaymentMethods() ([]PaymentMethodResponse, error) {
-	paymentMethods, err := a.paymentMethodService.ListPaymentMethods(a.ctx)
-	if err != nil {
+// Export Methods
+
+// ExportTransactions asks where to save and writes every transaction
+// matching params there as csv, xlsx or json. It returns nil if the dialog
+// is cancelled.
+func (a *App) ExportTransactions(params services.ListTransactionParams, format string) (*services.ExportResult, error) {
+	format = strings.ToLower(format)
+	filters := map[string]runtime.FileFilter{
+		services.ExportFormatCSV:  {DisplayName: "CSV Files (*.csv)", Pattern: "*.csv"},
+		services.ExportFormatXLSX: {DisplayName: "Excel Workbooks (*.xlsx)", Pattern: "*.xlsx"},
+		services.ExportFormatJSON: {DisplayName: "JSON Files (*.json)", Pattern: "*.json"},
+	}
+	filter, ok := filters[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export Transactions",
+		DefaultFilename: fmt.Sprintf("transactions-%s.%s", time.Now().Format("2006-01-02"), format),
+		Filters:         []runtime.FileFilter{filter},
+	})
+	if err != nil || path == "" {
 		return nil, err
 	}
 
-	result := make([]PaymentMethodResponse, 0, len(paymentMethods))
-	for _, pm := range paymentMethods {
-		result = append(result, *convertPaymentMethod(&pm))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
 	}
-	return result, nil
-}
 
-// ListActivePaymentMethods lists only active payment methods
-func (a *App) ListActivePaymentMethods() ([]PaymentMethodResponse, error) {
-	paymentMethods, err := a.paymentMethodService.ListActivePaymentMethods(a.ctx)
+	params.CreatedBy = a.currentUser()
+	rows, err := a.exportService.ExportTransactions(a.ctx, file, params, format)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+	}
 	if err != nil {
+		os.Remove(path)
 		return nil, err
 	}
+	return &services.ExportResult{Path: path, Rows: rows}, nil
+}
 
-	result := make([]PaymentMethodResponse, 0, len(paymentMethods))
-	for _, pm := range paymentMethods {
-		result = append(result, *convertPaymentMethod(&pm))
+// Attachment Methods
+
+// AddAttachments asks for files and attaches them to a transaction.
+// It returns nil if the dialog is cancelled.
+func (a *App) AddAttachments(transactionID string) ([]AttachmentResponse, error) {
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Attach Files",
+	})
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+
+	result := make([]AttachmentResponse, 0, len(paths))
+	for _, path := range paths {
+		attachment, err := a.attachmentService.AddAttachment(a.ctx, a.currentUser(), transactionID, path)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, *convertAttachment(attachment))
 	}
 	return result, nil
 }
 
-// UpdatePaymentMethod updates an existing payment method
-func (a *App) UpdatePaymentMethod(id, name, description string, isActive bool) (*PaymentMethodResponse, error) {
-	paymentMethod, err := a.paymentMethodService.UpdatePaymentMethod(a.ctx, id, name, description, isActive)
+// ListAttachments lists the attachments of a transaction
+func (a *App) ListAttachments(transactionID string) ([]AttachmentResponse, error) {
+	attachments, err := a.attachmentService.ListAttachments(a.ctx, a.currentUser(), transactionID)
 	if err != nil {
 		return nil, err
 	}
-	return convertPaymentMethod(paymentMethod), nil
-}
-
-// DeletePaymentMethod deletes a payment method
-func (a *App) DeletePaymentMethod(id string) error {
-	return a.paymentMethodService.DeletePaymentMethod(a.ctx, id)
-}
 
-// DeactivatePaymentMethod deactivates a payment method
-func (a *App) DeactivatePaymentMethod(id string) error {
-	return a.paymentMethodService.DeactivatePaymentMethod(a.ctx, id)
+	result := make([]AttachmentResponse, 0, len(attachments))
+	for _, at := range attachments {
+		result = append(result, *convertAttachment(&at))
+	}
+	return result, nil
 }
 
-// CheckPaymentMethodDependencies checks if a payment method has dependent transactions
-func (a *App) CheckPaymentMethodDependencies(id string) (int64, error) {
-	return a.paymentMethodService.CheckPaymentMethodDependencies(a.ctx, id)
+// RemoveAttachment removes an attachment from its transaction
+func (a *App) RemoveAttachment(id string) error {
+	return a.attachmentService.RemoveAttachment(a.ctx, a.currentUser(), id)
 }
 
-// SearchTransactions searches transactions
-func (a *App) SearchTransactions(searchTerm string, limit, offset int) ([]TransactionResponse, error) {
-	if limit == 0 {
-		limit = 50
-	}
+// Payment Ledger Methods
 
-	transactions, err := a.transactionService.SearchTransactions(a.ctx, searchTerm, limit, offset)
+// RecordPayment records a payment against a transaction
+func (a *App) RecordPayment(params services.RecordPaymentParams) (*PaymentResponse, error) {
+	params.CreatedBy = a.currentUser()
+	payment, err := a.paymentService.RecordPayment(a.actorContext(), params)
 	if err != nil {
 		return nil, err
 	}
-
-	result := make([]TransactionResponse, 0, len(transactions))
-	for _, t := range transactions {
-		result = append(result, *a.convertTransaction(&t))
+	transaction, err := a.transactionService.GetTransaction(a.ctx, a.currentUser(), payment.TransactionID)
+	if err != nil {
+		return nil, err
 	}
-	return result, nil
+	return a.convertPayment(payment, transaction.Currency.String), nil
 }
 
-// GetRecentTransactions gets recent transactions
-func (a *App) GetRecentTransactions(limit int) ([]TransactionResponse, error) {
-	if limit == 0 {
-		limit = 10
+// ListPayments lists the payments of a transaction, voided ones included
+func (a *App) ListPayments(transactionID string) ([]PaymentResponse, error) {
+	transaction, err := a.transactionService.GetTransaction(a.ctx, a.currentUser(), transactionID)
+	if err != nil {
+		return nil, err
 	}
-
-	transactions, err := a.transactionService.GetRecentTransactions(a.ctx, limit)
+	payments, err := a.paymentService.ListPayments(a.ctx, a.currentUser(), transactionID)
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]TransactionResponse, 0, len(transactions))
-	for _, t := range transactions {
-		result = append(result, *a.convertTransaction(&t))
+	result := make([]PaymentResponse, 0, len(payments))
+	for _, p := range payments {
+		result = append(result, *a.convertPayment(&p, transaction.Currency.String))
 	}
 	return result, nil
 }
 
-// GetDescriptionSuggestions retrieves description suggestions for auto-complete
-func (a *App) GetDescriptionSuggestions(transactionType, search string) ([]services.SuggestionItem, error) {
-	return a.transactionService.GetDescriptionSuggestions(a.ctx, "default", transactionType, search, 10)
+// VoidPayment voids a payment so it no longer counts towards its transaction
+func (a *App) VoidPayment(id string) error {
+	return a.paymentService.VoidPayment(a.actorContext(), a.currentUser(), id)
 }
 
-// GetCustomerVendorSuggestions retrieves customer/vendor suggestions for auto-complete
-func (a *App) GetCustomerVendorSuggestions(transactionType, search string) ([]services.SuggestionItem, error) {
-	return a.transactionService.GetCustomerVendorSuggestions(a.ctx, "default", transactionType, search, 10)
+// assetHandler serves what is not part of the embedded frontend assets
+func (a *App) assetHandler() http.Handler {
+	return a.attachmentService.Handler(a.currentUser)
+}
+
+// User Management Methods
+
+// currentUser returns the ID of the user whose books are open
+func (a *App) currentUser() string {
+	a.userMu.RLock()
+	defer a.userMu.RUnlock()
+	return a.currentUserID
+}
+
+// actorContext is the context for changes made on behalf of the current
+// user, so the transaction history records who made them
+func (a *App) actorContext() context.Context {
+	return services.WithActor(a.ctx, a.currentUser())
+}
+
+// GetCurrentUser returns the user whose books are open
+func (a *App) GetCurrentUser() (*models.User, error) {
+	return a.userService.GetUser(a.ctx, a.currentUser())
+}
+
+// SwitchUser opens the books of another user
+func (a *App) SwitchUser(id string) (*models.User, error) {
+	user, err := a.userService.GetUser(a.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	a.userMu.Lock()
+	a.currentUserID = user.ID
+	a.userMu.Unlock()
+	return user, nil
 }
 
-// User Management Methods (keeping existing)
+// ListUsers lists all users
+func (a *App) ListUsers() ([]models.User, error) {
+	return a.userService.ListUsers(a.ctx)
+}
 
 // GetUser retrieves a user by ID
 func (a *App) GetUser(id string) (*models.User, error) {
-	return a.userService.GetUser(id)
+	return a.userService.GetUser(a.ctx, id)
 }
 
 // CreateUser creates a new user
 func (a *App) CreateUser(req models.UserCreateRequest) (*models.User, error) {
-	return a.userService.CreateUser(&req)
+	return a.userService.CreateUser(a.ctx, &req)
 }
 
 // UpdateUser updates an existing user
 func (a *App) UpdateUser(id string, req models.UserUpdateRequest) (*models.User, error) {
-	return a.userService.UpdateUser(id, &req)
+	return a.userService.UpdateUser(a.ctx, id, &req)
 }
 
-// DeleteUser deletes a user by ID
+// DeleteUser deletes a user by ID. Deleting the current user switches back
+// to the default user.
 func (a *App) DeleteUser(id string) error {
-	return a.userService.DeleteUser(id)
+	if err := a.userService.DeleteUser(a.ctx, id); err != nil {
+		return err
+	}
+
+	a.userMu.Lock()
+	if a.currentUserID == id {
+		a.currentUserID = services.DefaultUserID
+	}
+	a.userMu.Unlock()
+	return nil
+}
+
+// HTTP API Methods
+
+// StartAPIServer serves the HTTP API on 127.0.0.1 at port, 8765 when it is 0
+func (a *App) StartAPIServer(port int) (*APIServerStatus, error) {
+	if err := a.apiServer.Start(port); err != nil {
+		return nil, err
+	}
+	return a.GetAPIServerStatus(), nil
+}
+
+// StopAPIServer stops serving the HTTP API
+func (a *App) StopAPIServer() error {
+	ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+	defer cancel()
+	return a.apiServer.Stop(ctx)
+}
+
+// GetAPIServerStatus tells whether the HTTP API is being served
+func (a *App) GetAPIServerStatus() *APIServerStatus {
+	addr := a.apiServer.Addr()
+	if addr == "" {
+		return &APIServerStatus{}
+	}
+	return &APIServerStatus{Running: true, Address: addr, URL: "http://" + addr + "/api"}
+}
+
+// CreateAPIToken creates an API token for the current user. The token is
+// only returned this once.
+func (a *App) CreateAPIToken(name string) (*APITokenResponse, error) {
+	created, err := a.apiTokenService.CreateAPIToken(a.ctx, a.currentUser(), name)
+	if err != nil {
+		return nil, err
+	}
+	response := convertAPIToken(&created.ApiToken)
+	response.Token = created.Token
+	return response, nil
+}
+
+// ListAPITokens lists the API tokens of the current user
+func (a *App) ListAPITokens() ([]APITokenResponse, error) {
+	tokens, err := a.apiTokenService.ListAPITokens(a.ctx, a.currentUser())
+	if err != nil {
+		return nil, err
+	}
+	result := make([]APITokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, *convertAPIToken(&t))
+	}
+	return result, nil
+}
+
+// RevokeAPIToken deletes an API token of the current user
+func (a *App) RevokeAPIToken(id string) error {
+	return a.apiTokenService.RevokeAPIToken(a.ctx, a.currentUser(), id)
 }
 
 // Response types for frontend
@@ -397,8 +1490,12 @@ type TransactionResponse struct {
 	CategoryID          string   `json:"category_id"`
 	Tags                []string `json:"tags"`
 	CustomerVendor      string   `json:"customer_vendor"`
+	ContactID           string   `json:"contact_id"`
+	ClearedStatus       string   `json:"cleared_status"`
 	PaymentMethod       string   `json:"payment_method"`
 	PaymentMethodID     string   `json:"payment_method_id"`
+	Account             string   `json:"account"`
+	AccountID           string   `json:"account_id"`
 	PaymentStatus       string   `json:"payment_status"`
 	ReferenceNumber     string   `json:"reference_number"`
 	InvoiceNumber       string   `json:"invoice_number"`
@@ -414,12 +1511,36 @@ type TransactionResponse struct {
 	RecurringFrequency  string   `json:"recurring_frequency"`
 	RecurringEndDate    string   `json:"recurring_end_date"`
 	ParentTransactionID string   `json:"parent_transaction_id"`
+	DueDate             string   `json:"due_date"`
 	CreatedBy           string   `json:"created_by"`
 	CreatedAt           string   `json:"created_at"`
 	UpdatedAt           string   `json:"updated_at"`
+	DeletedAt           string   `json:"deleted_at"`
+}
+
+// SearchResultResponse is a search match. Snippet is HTML with the matched
+// words in <mark> elements.
+type SearchResultResponse struct {
+	TransactionResponse
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// AgingItemResponse is an outstanding transaction in an aging report.
+// EffectiveDueDate falls back to the transaction date plus payment terms;
+// Outstanding is in OutstandingCurrency, the reporting currency unless the
+// transaction has no exchange rate into it.
+type AgingItemResponse struct {
+	TransactionResponse
+	EffectiveDueDate    string  `json:"effective_due_date"`
+	DaysOverdue         int     `json:"days_overdue"`
+	Bucket              string  `json:"bucket"`
+	Outstanding         float64 `json:"outstanding"`
+	OutstandingCurrency string  `json:"outstanding_currency"`
 }
 
 type TransactionStats struct {
+	Currency           string  `json:"currency"`
 	TotalIncome        float64 `json:"total_income"`
 	TotalExpenses      float64 `json:"total_expenses"`
 	NetProfit          float64 `json:"net_profit"`
@@ -429,6 +1550,8 @@ type TransactionStats struct {
 	AverageTransaction float64 `json:"average_transaction"`
 	PendingIncome      float64 `json:"pending_income"`
 	PendingExpenses    float64 `json:"pending_expenses"`
+
+	Unconverted []services.UnconvertedAmount `json:"unconverted,omitempty"`
 }
 
 type CategorySummary struct {
@@ -436,6 +1559,166 @@ type CategorySummary struct {
 	Type        string  `json:"type"`
 	Count       int     `json:"count"`
 	TotalAmount float64 `json:"total_amount"`
+	Currency    string  `json:"currency"`
+}
+
+type TemplateResponse struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	Description     string   `json:"description"`
+	Amount          float64  `json:"amount"`
+	Category        string   `json:"category"`
+	CategoryID      string   `json:"category_id"`
+	Tags            []string `json:"tags"`
+	CustomerVendor  string   `json:"customer_vendor"`
+	PaymentMethod   string   `json:"payment_method"`
+	PaymentMethodID string   `json:"payment_method_id"`
+	TaxAmount       float64  `json:"tax_amount"`
+	DiscountAmount  float64  `json:"discount_amount"`
+	Currency        string   `json:"currency"`
+	Notes           string   `json:"notes"`
+	UsageCount      int      `json:"usage_count"`
+	IsFavorite      bool     `json:"is_favorite"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+type CurrencyResponse struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Exponent int    `json:"exponent"`
+}
+
+type ExchangeRateResponse struct {
+	ID           string  `json:"id"`
+	FromCurrency string  `json:"from_currency"`
+	ToCurrency   string  `json:"to_currency"`
+	Rate         float64 `json:"rate"`
+	RateDate     string  `json:"rate_date"`
+	Source       string  `json:"source"`
+}
+
+// AttachmentResponse describes a stored file; URL is served by the asset server
+type AttachmentResponse struct {
+	ID            string `json:"id"`
+	TransactionID string `json:"transaction_id"`
+	FileName      string `json:"file_name"`
+	MimeType      string `json:"mime_type"`
+	Size          int64  `json:"size"`
+	URL           string `json:"url"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// PaymentResponse is a payment in the currency of its transaction
+type PaymentResponse struct {
+	ID              string  `json:"id"`
+	TransactionID   string  `json:"transaction_id"`
+	Amount          float64 `json:"amount"`
+	Currency        string  `json:"currency"`
+	PaymentDate     string  `json:"payment_date"`
+	PaymentMethod   string  `json:"payment_method"`
+	PaymentMethodID string  `json:"payment_method_id"`
+	Reference       string  `json:"reference"`
+	Notes           string  `json:"notes"`
+	Voided          bool    `json:"voided"`
+	VoidedAt        string  `json:"voided_at"`
+	CreatedBy       string  `json:"created_by"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// BudgetResponse is a budget with its amount in the budget's currency
+type BudgetResponse struct {
+	ID             string  `json:"id"`
+	CategoryID     string  `json:"category_id"`
+	CategoryName   string  `json:"category_name"`
+	Period         string  `json:"period"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+	Rollover       bool    `json:"rollover"`
+	AlertThreshold float64 `json:"alert_threshold"`
+	StartDate      string  `json:"start_date"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+// AccountResponse is an account with its opening balance in the account's
+// currency
+type AccountResponse struct {
+	ID             string  `json:"id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"opening_balance"`
+	OpeningDate    string  `json:"opening_date"`
+	IsActive       bool    `json:"is_active"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+// TransferResponse is a transfer; Amount is in the currency of the account
+// it left and ToAmount in that of the account it reached
+type TransferResponse struct {
+	ID            string  `json:"id"`
+	FromAccountID string  `json:"from_account_id"`
+	FromAccount   string  `json:"from_account"`
+	ToAccountID   string  `json:"to_account_id"`
+	ToAccount     string  `json:"to_account"`
+	Amount        float64 `json:"amount"`
+	FromCurrency  string  `json:"from_currency"`
+	ToAmount      float64 `json:"to_amount"`
+	ToCurrency    string  `json:"to_currency"`
+	TransferDate  string  `json:"transfer_date"`
+	Description   string  `json:"description"`
+	Reference     string  `json:"reference"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// ReconciliationResponse is a reconciliation without its statement lines;
+// EndingBalance is in the account's currency
+type ReconciliationResponse struct {
+	ID                 string  `json:"id"`
+	AccountID          string  `json:"account_id"`
+	StatementStartDate string  `json:"statement_start_date"`
+	StatementEndDate   string  `json:"statement_end_date"`
+	EndingBalance      float64 `json:"ending_balance"`
+	Status             string  `json:"status"`
+	CreatedAt          string  `json:"created_at"`
+	CompletedAt        string  `json:"completed_at"`
+}
+
+// APIServerStatus tells whether the HTTP API is being served, and where
+type APIServerStatus struct {
+	Running bool   `json:"running"`
+	Address string `json:"address"`
+	URL     string `json:"url"`
+}
+
+// APITokenResponse is an API token; Token is only set when it is created,
+// and Prefix tells tokens apart afterwards
+type APITokenResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	Token      string `json:"token,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+// ContactResponse is a customer or vendor
+type ContactResponse struct {
+	ID                     string `json:"id"`
+	Name                   string `json:"name"`
+	Type                   string `json:"type"`
+	Email                  string `json:"email"`
+	Phone                  string `json:"phone"`
+	Address                string `json:"address"`
+	TaxID                  string `json:"tax_id"`
+	DefaultCategoryID      string `json:"default_category_id"`
+	DefaultPaymentMethodID string `json:"default_payment_method_id"`
+	TransactionCount       int64  `json:"transaction_count"`
+	CreatedAt              string `json:"created_at"`
+	UpdatedAt              string `json:"updated_at"`
 }
 
 type CategoryResponse struct {
@@ -488,37 +1771,113 @@ func (a *App) convertTransaction(t *db.Transaction) *TransactionResponse {
 		}
 	}
 
+	accountName := ""
+	if t.AccountID.Valid && t.AccountID.String != "" {
+		if account, err := a.db.Queries().GetAccount(a.ctx, t.AccountID.String); err == nil {
+			accountName = account.Name
+		}
+	}
+
+	// Amounts are stored in minor units of the transaction currency
+	currency := nullStringToString(t.Currency)
+	toAmount := func(minor int64) float64 {
+		return a.currencyService.FromMinorUnits(a.ctx, minor, currency)
+	}
+
 	return &TransactionResponse{
 		ID:                  t.ID,
 		Type:                t.Type,
 		Description:         t.Description,
-		Amount:              t.Amount,
+		Amount:              toAmount(t.Amount),
 		TransactionDate:     t.TransactionDate.Format("2006-01-02"),
 		Category:            categoryName,
 		CategoryID:          nullStringToString(t.CategoryID),
 		Tags:                tags,
 		CustomerVendor:      nullStringToString(t.CustomerVendor),
+		ContactID:           nullStringToString(t.ContactID),
+		ClearedStatus:       t.ClearedStatus,
 		PaymentMethod:       paymentMethodName,
 		PaymentMethodID:     nullStringToString(t.PaymentMethodID),
+		Account:             accountName,
+		AccountID:           nullStringToString(t.AccountID),
 		PaymentStatus:       nullStringToString(t.PaymentStatus),
 		ReferenceNumber:     nullStringToString(t.ReferenceNumber),
 		InvoiceNumber:       nullStringToString(t.InvoiceNumber),
 		Notes:               nullStringToString(t.Notes),
 		Attachments:         attachments,
-		TaxAmount:           nullFloat64ToFloat64(t.TaxAmount),
-		DiscountAmount:      nullFloat64ToFloat64(t.DiscountAmount),
-		DueAmount:           nullFloat64ToFloat64(t.DueAmount),
-		NetAmount:           nullFloat64ToFloat64(t.NetAmount),
+		TaxAmount:           toAmount(nullInt64ToInt64(t.TaxAmount)),
+		DiscountAmount:      toAmount(nullInt64ToInt64(t.DiscountAmount)),
+		DueAmount:           toAmount(nullInt64ToInt64(t.DueAmount)),
+		NetAmount:           toAmount(nullInt64ToInt64(t.NetAmount)),
 		Currency:            nullStringToString(t.Currency),
 		ExchangeRate:        nullFloat64ToFloat64(t.ExchangeRate),
 		IsRecurring:         nullBoolToBool(t.IsRecurring),
 		RecurringFrequency:  nullStringToString(t.RecurringFrequency),
 		RecurringEndDate:    nullTimeToString(t.RecurringEndDate),
 		ParentTransactionID: nullStringToString(t.ParentTransactionID),
+		DueDate:             nullTimeToString(t.DueDate),
 		CreatedBy:           t.CreatedBy,
 		CreatedAt:           nullTimeToString(t.CreatedAt),
 		UpdatedAt:           nullTimeToString(t.UpdatedAt),
+		DeletedAt:           nullTimeToString(t.DeletedAt),
+	}
+}
+
+func (a *App) convertTemplate(t *db.TransactionTemplate) *TemplateResponse {
+	var tags []string
+	if t.Tags.Valid && t.Tags.String != "" {
+		json.Unmarshal([]byte(t.Tags.String), &tags)
+	}
+
+	categoryName := ""
+	if t.Category.Valid && t.Category.String != "" {
+		if name, err := a.db.Queries().GetCategoryName(a.ctx, t.Category.String); err == nil {
+			categoryName = name
+		}
+	}
+
+	paymentMethodName := ""
+	if t.PaymentMethod.Valid && t.PaymentMethod.String != "" {
+		if name, err := a.db.Queries().GetPaymentMethodName(a.ctx, t.PaymentMethod.String); err == nil {
+			paymentMethodName = name
+		}
+	}
+
+	// Amounts are stored in minor units of the template currency
+	currency := nullStringToString(t.Currency)
+	toAmount := func(minor int64) float64 {
+		return a.currencyService.FromMinorUnits(a.ctx, minor, currency)
+	}
+
+	return &TemplateResponse{
+		ID:              t.ID,
+		Name:            t.Name,
+		Type:            t.Type,
+		Description:     nullStringToString(t.Description),
+		Amount:          toAmount(nullInt64ToInt64(t.Amount)),
+		Category:        categoryName,
+		CategoryID:      nullStringToString(t.Category),
+		Tags:            tags,
+		CustomerVendor:  nullStringToString(t.CustomerVendor),
+		PaymentMethod:   paymentMethodName,
+		PaymentMethodID: nullStringToString(t.PaymentMethod),
+		TaxAmount:       toAmount(nullInt64ToInt64(t.TaxAmount)),
+		DiscountAmount:  toAmount(nullInt64ToInt64(t.DiscountAmount)),
+		Currency:        currency,
+		Notes:           nullStringToString(t.Notes),
+		UsageCount:      int(nullInt64ToInt64(t.UsageCount)),
+		IsFavorite:      nullBoolToBool(t.IsFavorite),
+		CreatedAt:       nullTimeToString(t.CreatedAt),
+		UpdatedAt:       nullTimeToString(t.UpdatedAt),
+	}
+}
+
+func (a *App) convertTemplates(templates []db.TransactionTemplate) []TemplateResponse {
+	result := make([]TemplateResponse, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, *a.convertTemplate(&t))
 	}
+	return result
 }
 
 func nullStringToString(ns sql.NullString) string {
@@ -535,6 +1894,13 @@ func nullFloat64ToFloat64(nf sql.NullFloat64) float64 {
 	return 0
 }
 
+func nullInt64ToInt64(ni sql.NullInt64) int64 {
+	if ni.Valid {
+		return ni.Int64
+	}
+	return 0
+}
+
 func nullBoolToBool(nb sql.NullBool) bool {
 	if nb.Valid {
 		return nb.Bool
@@ -563,6 +1929,147 @@ func convertCategory(c *db.Category) *CategoryResponse {
 	}
 }
 
+func convertExchangeRate(r *db.ExchangeRate) *ExchangeRateResponse {
+	return &ExchangeRateResponse{
+		ID:           r.ID,
+		FromCurrency: r.FromCurrency,
+		ToCurrency:   r.ToCurrency,
+		Rate:         r.Rate,
+		RateDate:     r.RateDate.Format("2006-01-02"),
+		Source:       nullStringToString(r.Source),
+	}
+}
+
+func convertAttachment(at *db.Attachment) *AttachmentResponse {
+	return &AttachmentResponse{
+		ID:            at.ID,
+		TransactionID: at.TransactionID,
+		FileName:      at.FileName,
+		MimeType:      at.MimeType,
+		Size:          at.SizeBytes,
+		URL:           services.AttachmentURL(at.ID),
+		CreatedAt:     nullTimeToString(at.CreatedAt),
+	}
+}
+
+func (a *App) convertPayment(p *db.Payment, currency string) *PaymentResponse {
+	paymentMethodName := ""
+	if p.PaymentMethodID.Valid && p.PaymentMethodID.String != "" {
+		if name, err := a.db.Queries().GetPaymentMethodName(a.ctx, p.PaymentMethodID.String); err == nil {
+			paymentMethodName = name
+		}
+	}
+
+	return &PaymentResponse{
+		ID:              p.ID,
+		TransactionID:   p.TransactionID,
+		Amount:          a.currencyService.FromMinorUnits(a.ctx, p.Amount, currency),
+		Currency:        currency,
+		PaymentDate:     p.PaymentDate.Format("2006-01-02"),
+		PaymentMethod:   paymentMethodName,
+		PaymentMethodID: nullStringToString(p.PaymentMethodID),
+		Reference:       nullStringToString(p.Reference),
+		Notes:           nullStringToString(p.Notes),
+		Voided:          p.VoidedAt.Valid,
+		VoidedAt:        nullTimeToString(p.VoidedAt),
+		CreatedBy:       p.CreatedBy,
+		CreatedAt:       nullTimeToString(p.CreatedAt),
+	}
+}
+
+func (a *App) convertBudget(b *db.Budget) *BudgetResponse {
+	categoryName := ""
+	if name, err := a.db.Queries().GetCategoryName(a.ctx, b.CategoryID); err == nil {
+		categoryName = name
+	}
+
+	return &BudgetResponse{
+		ID:             b.ID,
+		CategoryID:     b.CategoryID,
+		CategoryName:   categoryName,
+		Period:         b.Period,
+		Amount:         a.currencyService.FromMinorUnits(a.ctx, b.Amount, b.Currency),
+		Currency:       b.Currency,
+		Rollover:       b.Rollover,
+		AlertThreshold: b.AlertThreshold,
+		StartDate:      b.StartDate.Format("2006-01-02"),
+		CreatedAt:      nullTimeToString(b.CreatedAt),
+		UpdatedAt:      nullTimeToString(b.UpdatedAt),
+	}
+}
+
+func (a *App) convertAccount(account *db.Account) *AccountResponse {
+	return &AccountResponse{
+		ID:             account.ID,
+		Name:           account.Name,
+		Type:           account.Type,
+		Currency:       account.Currency,
+		OpeningBalance: a.currencyService.FromMinorUnits(a.ctx, account.OpeningBalance, account.Currency),
+		OpeningDate:    account.OpeningDate.Format("2006-01-02"),
+		IsActive:       account.IsActive,
+		CreatedAt:      nullTimeToString(account.CreatedAt),
+		UpdatedAt:      nullTimeToString(account.UpdatedAt),
+	}
+}
+
+func (a *App) convertTransfer(t *db.Transfer) *TransferResponse {
+	from, _ := a.db.Queries().GetAccount(a.ctx, t.FromAccountID)
+	to, _ := a.db.Queries().GetAccount(a.ctx, t.ToAccountID)
+
+	return &TransferResponse{
+		ID:            t.ID,
+		FromAccountID: t.FromAccountID,
+		FromAccount:   from.Name,
+		ToAccountID:   t.ToAccountID,
+		ToAccount:     to.Name,
+		Amount:        a.currencyService.FromMinorUnits(a.ctx, t.Amount, from.Currency),
+		FromCurrency:  from.Currency,
+		ToAmount:      a.currencyService.FromMinorUnits(a.ctx, t.ToAmount, to.Currency),
+		ToCurrency:    to.Currency,
+		TransferDate:  t.TransferDate.Format("2006-01-02"),
+		Description:   nullStringToString(t.Description),
+		Reference:     nullStringToString(t.Reference),
+		CreatedAt:     nullTimeToString(t.CreatedAt),
+	}
+}
+
+func convertAPIToken(t *db.ApiToken) *APITokenResponse {
+	return &APITokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.TokenPrefix,
+		CreatedAt:  nullTimeToString(t.CreatedAt),
+		LastUsedAt: nullTimeToString(t.LastUsedAt),
+	}
+}
+
+func (a *App) convertContact(c *db.Contact) *ContactResponse {
+	count, _ := a.db.Queries().CountTransactionsByContact(a.ctx, sql.NullString{String: c.ID, Valid: true})
+
+	return &ContactResponse{
+		ID:                     c.ID,
+		Name:                   c.Name,
+		Type:                   c.Type,
+		Email:                  nullStringToString(c.Email),
+		Phone:                  nullStringToString(c.Phone),
+		Address:                nullStringToString(c.Address),
+		TaxID:                  nullStringToString(c.TaxID),
+		DefaultCategoryID:      nullStringToString(c.DefaultCategoryID),
+		DefaultPaymentMethodID: nullStringToString(c.DefaultPaymentMethodID),
+		TransactionCount:       count,
+		CreatedAt:              nullTimeToString(c.CreatedAt),
+		UpdatedAt:              nullTimeToString(c.UpdatedAt),
+	}
+}
+
+func (a *App) convertContacts(contacts []db.Contact) []ContactResponse {
+	result := make([]ContactResponse, 0, len(contacts))
+	for _, c := range contacts {
+		result = append(result, *a.convertContact(&c))
+	}
+	return result
+}
+
 func convertPaymentMethod(pm *db.PaymentMethod) *PaymentMethodResponse {
 	return &PaymentMethodResponse{
 		ID:          pm.ID,
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"cashflow/internal/api"
+	db "cashflow/internal/db/sqlc"
+	"cashflow/internal/services"
+)
+
+func (c *cli) categoryCommand(args []string) error {
+	if len(args) == 0 {
+		return c.usageError("categories needs a subcommand: list, add, update or delete")
+	}
+	switch args[0] {
+	case "list", "ls":
+		return c.listCategories(args[1:])
+	case "add", "create":
+		return c.addCategory(args[1:])
+	case "update", "edit":
+		return c.updateCategory(args[1:])
+	case "delete", "rm":
+		return c.deleteCategory(args[1:])
+	}
+	return c.usageError("unknown categories subcommand %q", args[0])
+}
+
+func (c *cli) listCategories(args []string) error {
+	fs := c.flags("categories list")
+	categoryType := fs.String("type", "", "only categories of a type: income, expense or both")
+	active := fs.Bool("active", false, "only active categories")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var categories []db.Category
+	var err error
+	switch {
+	case *categoryType != "":
+		categories, err = c.categories.ListCategoriesByType(c.ctx, *categoryType)
+	case *active:
+		categories, err = c.categories.ListActiveCategories(c.ctx)
+	default:
+		categories, err = c.categories.ListCategories(c.ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := make([]api.Category, 0, len(categories))
+	for _, category := range categories {
+		result = append(result, *c.convert.Category(&category))
+	}
+	return c.printCategories(result...)
+}
+
+func (c *cli) addCategory(args []string) error {
+	fs := c.flags("categories add")
+	categoryType := fs.String("type", "", "income, expense or both")
+	color := fs.String("color", "", "color, e.g. #10b981")
+	icon := fs.String("icon", "", "icon name")
+	parent := fs.String("parent", "", "parent category name or ID")
+	inactive := fs.Bool("inactive", false, "create the category inactive")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if err := checkCategoryType(*categoryType); err != nil {
+		return err
+	}
+	parentID, err := c.categoryID(*parent)
+	if err != nil {
+		return err
+	}
+
+	category, err := c.categories.CreateCategory(c.ctx, services.CreateCategoryParams{
+		Name:     args[0],
+		Type:     *categoryType,
+		Color:    *color,
+		Icon:     *icon,
+		ParentID: parentID,
+		IsActive: !*inactive,
+	})
+	if err != nil {
+		return err
+	}
+	return c.printCategories(*c.convert.Category(category))
+}
+
+func (c *cli) updateCategory(args []string) error {
+	fs := c.flags("categories update")
+	name := fs.String("name", "", "new name")
+	categoryType := fs.String("type", "", "income, expense or both")
+	color := fs.String("color", "", "color, e.g. #10b981")
+	icon := fs.String("icon", "", "icon name")
+	parent := fs.String("parent", "", "parent category name or ID, \"\" for none")
+	active := fs.Bool("active", true, "whether the category is active")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	current, err := c.category(args[0])
+	if err != nil {
+		return err
+	}
+
+	params := services.UpdateCategoryParams{
+		Name:     current.Name,
+		Type:     current.Type,
+		Color:    current.Color.String,
+		Icon:     current.Icon.String,
+		ParentID: current.ParentID.String,
+		IsActive: current.IsActive.Bool,
+	}
+	set := setFlags(fs)
+	if set["name"] {
+		params.Name = *name
+	}
+	if set["type"] {
+		if err := checkCategoryType(*categoryType); err != nil {
+			return err
+		}
+		params.Type = *categoryType
+	}
+	if set["color"] {
+		params.Color = *color
+	}
+	if set["icon"] {
+		params.Icon = *icon
+	}
+	if set["parent"] {
+		if params.ParentID, err = c.categoryID(*parent); err != nil {
+			return err
+		}
+	}
+	if set["active"] {
+		params.IsActive = *active
+	}
+
+	category, err := c.categories.UpdateCategory(c.ctx, current.ID, params)
+	if err != nil {
+		return err
+	}
+	return c.printCategories(*c.convert.Category(category))
+}
+
+func (c *cli) deleteCategory(args []string) error {
+	fs := c.flags("categories delete")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	category, err := c.category(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := c.categories.DeleteCategory(c.ctx, category.ID); err != nil {
+		return err
+	}
+	return c.message("Deleted category %s", category.Name)
+}
+
+func (c *cli) printCategories(categories ...api.Category) error {
+	rows := make([][]string, 0, len(categories))
+	for _, category := range categories {
+		rows = append(rows, []string{category.ID, category.Name, category.Type, category.ParentID, formatBool(category.IsActive)})
+	}
+	return c.print(categories, []string{"ID", "NAME", "TYPE", "PARENT", "ACTIVE"}, rows)
+}
+
+// category finds a category by ID or name, inactive ones included
+func (c *cli) category(nameOrID string) (*db.Category, error) {
+	if category, err := c.categories.GetCategory(c.ctx, nameOrID); err == nil {
+		return category, nil
+	}
+	categories, err := c.categories.ListCategories(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		if strings.EqualFold(category.Name, nameOrID) {
+			return &category, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown category %q", nameOrID)
+}
+
+// categoryID is the ID of a category given by ID or name, "" for none
+func (c *cli) categoryID(nameOrID string) (string, error) {
+	if nameOrID == "" {
+		return "", nil
+	}
+	category, err := c.category(nameOrID)
+	if err != nil {
+		return "", err
+	}
+	return category.ID, nil
+}
+
+func checkCategoryType(categoryType string) error {
+	switch categoryType {
+	case "income", "expense", "both":
+		return nil
+	}
+	return fmt.Errorf("category type must be income, expense or both")
+}
+
+func (c *cli) paymentMethodCommand(args []string) error {
+	if len(args) == 0 {
+		return c.usageError("payment-methods needs a subcommand: list, add, update or delete")
+	}
+	switch args[0] {
+	case "list", "ls":
+		return c.listPaymentMethods(args[1:])
+	case "add", "create":
+		return c.addPaymentMethod(args[1:])
+	case "update", "edit":
+		return c.updatePaymentMethod(args[1:])
+	case "delete", "rm":
+		return c.deletePaymentMethod(args[1:])
+	}
+	return c.usageError("unknown payment-methods subcommand %q", args[0])
+}
+
+func (c *cli) listPaymentMethods(args []string) error {
+	fs := c.flags("payment-methods list")
+	active := fs.Bool("active", false, "only active payment methods")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	var paymentMethods []db.PaymentMethod
+	var err error
+	if *active {
+		paymentMethods, err = c.paymentMethods.ListActivePaymentMethods(c.ctx)
+	} else {
+		paymentMethods, err = c.paymentMethods.ListPaymentMethods(c.ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	result := make([]api.PaymentMethod, 0, len(paymentMethods))
+	for _, pm := range paymentMethods {
+		result = append(result, *c.convert.PaymentMethod(&pm))
+	}
+	return c.printPaymentMethods(result...)
+}
+
+func (c *cli) addPaymentMethod(args []string) error {
+	fs := c.flags("payment-methods add")
+	description := fs.String("description", "", "description")
+	inactive := fs.Bool("inactive", false, "create the payment method inactive")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	paymentMethod, err := c.paymentMethods.CreatePaymentMethod(c.ctx, args[0], *description, !*inactive)
+	if err != nil {
+		return err
+	}
+	return c.printPaymentMethods(*c.convert.PaymentMethod(paymentMethod))
+}
+
+func (c *cli) updatePaymentMethod(args []string) error {
+	fs := c.flags("payment-methods update")
+	name := fs.String("name", "", "new name")
+	description := fs.String("description", "", "description")
+	active := fs.Bool("active", true, "whether the payment method is active")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	current, err := c.paymentMethod(args[0])
+	if err != nil {
+		return err
+	}
+
+	newName, newDescription, isActive := current.Name, current.Description.String, current.IsActive.Bool
+	set := setFlags(fs)
+	if set["name"] {
+		newName = *name
+	}
+	if set["description"] {
+		newDescription = *description
+	}
+	if set["active"] {
+		isActive = *active
+	}
+
+	paymentMethod, err := c.paymentMethods.UpdatePaymentMethod(c.ctx, current.ID, newName, newDescription, isActive)
+	if err != nil {
+		return err
+	}
+	return c.printPaymentMethods(*c.convert.PaymentMethod(paymentMethod))
+}
+
+func (c *cli) deletePaymentMethod(args []string) error {
+	fs := c.flags("payment-methods delete")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	paymentMethod, err := c.paymentMethod(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := c.paymentMethods.DeletePaymentMethod(c.ctx, paymentMethod.ID); err != nil {
+		return err
+	}
+	return c.message("Deleted payment method %s", paymentMethod.Name)
+}
+
+func (c *cli) printPaymentMethods(paymentMethods ...api.PaymentMethod) error {
+	rows := make([][]string, 0, len(paymentMethods))
+	for _, pm := range paymentMethods {
+		rows = append(rows, []string{pm.ID, pm.Name, truncate(pm.Description, 40), formatBool(pm.IsActive)})
+	}
+	return c.print(paymentMethods, []string{"ID", "NAME", "DESCRIPTION", "ACTIVE"}, rows)
+}
+
+// paymentMethod finds a payment method by ID or name, inactive ones
+// included
+func (c *cli) paymentMethod(nameOrID string) (*db.PaymentMethod, error) {
+	if paymentMethod, err := c.paymentMethods.GetPaymentMethod(c.ctx, nameOrID); err == nil {
+		return paymentMethod, nil
+	}
+	paymentMethods, err := c.paymentMethods.ListPaymentMethods(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pm := range paymentMethods {
+		if strings.EqualFold(pm.Name, nameOrID) {
+			return &pm, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown payment method %q", nameOrID)
+}
+
+// paymentMethodID is the ID of a payment method given by ID or name, "" for
+// none
+func (c *cli) paymentMethodID(nameOrID string) (string, error) {
+	if nameOrID == "" {
+		return "", nil
+	}
+	paymentMethod, err := c.paymentMethod(nameOrID)
+	if err != nil {
+		return "", err
+	}
+	return paymentMethod.ID, nil
+}
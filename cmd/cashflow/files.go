@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cashflow/internal/services"
+)
+
+// defaultCSVMapping reads the columns of the app's own CSV export, so an
+// export can be imported again without -map
+var defaultCSVMapping = map[string]string{
+	"transaction_date": "Date",
+	"type":             "Type",
+	"description":      "Description",
+	"category":         "Category",
+	"customer_vendor":  "Customer/Vendor",
+	"payment_method":   "Payment Method",
+	"payment_status":   "Payment Status",
+	"reference_number": "Reference Number",
+	"invoice_number":   "Invoice Number",
+	"tags":             "Tags",
+	"notes":            "Notes",
+	"currency":         "Currency",
+	"amount":           "Amount",
+	"tax_amount":       "Tax Amount",
+	"discount_amount":  "Discount Amount",
+	"due_amount":       "Due Amount",
+}
+
+func (c *cli) importFile(args []string) error {
+	fs := c.flags("import")
+	dryRun := fs.Bool("dry-run", false, "check the file and show what would be imported")
+	currency := fs.String("currency", "", "currency of the statement when it does not say")
+	dateFormat := fs.String("date-format", "", "date format, e.g. DD.MM.YYYY (CSV and QIF)")
+	account := fs.String("account", "", "account name or ID to put the transactions in (OFX/QFX and QIF)")
+	delimiter := fs.String("delimiter", ",", "CSV column delimiter")
+	decimal := fs.String("decimal", ".", "CSV decimal separator, . or ,")
+	noHeader := fs.Bool("no-header", false, "the CSV file has no header row")
+	mapping := fs.String("map", "", "CSV columns as field=column pairs, e.g. transaction_date=Date,amount=Amount,description=Memo (default the app's export columns)")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	path := args[0]
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var preview *services.ImportPreview
+	var result *services.ImportResult
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		opts := services.CSVImportOptions{
+			Delimiter:        *delimiter,
+			HasHeader:        !*noHeader,
+			DateFormat:       *dateFormat,
+			DecimalSeparator: *decimal,
+			Mapping:          defaultCSVMapping,
+			Currency:         *currency,
+			CreatedBy:        c.user,
+		}
+		if *mapping != "" {
+			if opts.Mapping, err = parseMapping(*mapping); err != nil {
+				return err
+			}
+		}
+		if *dryRun {
+			preview, err = c.imports.PreviewCSV(c.ctx, file, opts)
+		} else {
+			result, err = c.imports.ImportCSV(c.ctx, file, opts)
+		}
+	} else {
+		opts := services.StatementImportOptions{
+			Currency:   *currency,
+			DateFormat: *dateFormat,
+			CreatedBy:  c.user,
+		}
+		if opts.Account, err = c.accountID(*account); err != nil {
+			return err
+		}
+		if *dryRun {
+			preview, err = c.imports.PreviewStatement(c.ctx, path, file, opts)
+		} else {
+			result, err = c.imports.ImportStatement(c.ctx, path, file, opts)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if preview != nil {
+		return c.printPreview(preview)
+	}
+	if c.json {
+		return c.print(result, nil, nil)
+	}
+	return c.message("Imported %d transaction(s), skipped %d imported before", result.Imported, result.Skipped)
+}
+
+func (c *cli) printPreview(preview *services.ImportPreview) error {
+	rows := make([][]string, 0, len(preview.Rows)+2)
+	for _, row := range preview.Rows {
+		status := "ok"
+		switch {
+		case len(row.Errors) > 0:
+			status = strings.Join(row.Errors, "; ")
+		case row.Duplicate:
+			status = "imported before"
+		}
+		t := row.Transaction
+		rows = append(rows, []string{
+			fmt.Sprint(row.Line), t.TransactionDate, t.Type, truncate(t.Description, 40),
+			formatAmount(t.Amount), t.Currency, status,
+		})
+	}
+	if err := c.print(preview, []string{"LINE", "DATE", "TYPE", "DESCRIPTION", "AMOUNT", "CURRENCY", "STATUS"}, rows); err != nil {
+		return err
+	}
+	if c.json {
+		return nil
+	}
+	_, err := fmt.Fprintf(c.out, "\n%d to import, %d with errors, %d imported before\n", preview.ValidRows, preview.ErrorRows, preview.DuplicateRows)
+	return err
+}
+
+// parseMapping parses field=column pairs
+func parseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range splitList(value) {
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected field=column", pair)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+func (c *cli) export(args []string) error {
+	fs := c.flags("export")
+	filters := addListFlags(fs)
+	format := fs.String("format", "", "csv, xlsx or json (default from the file name, csv for -)")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	path := args[0]
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if path == "-" {
+			*format = services.ExportFormatCSV
+		}
+	}
+	switch *format {
+	case services.ExportFormatCSV, services.ExportFormatJSON:
+	case services.ExportFormatXLSX:
+		if path == "-" {
+			return fmt.Errorf("xlsx cannot be written to standard output")
+		}
+	default:
+		return fmt.Errorf("unsupported export format %q; use -format csv, xlsx or json", *format)
+	}
+	params, err := filters.params(c)
+	if err != nil {
+		return err
+	}
+
+	// "-" writes the export itself to standard output, for pipes
+	if path == "-" {
+		_, err := c.exports.ExportTransactions(c.ctx, c.out, params, *format)
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	rows, err := c.exports.ExportTransactions(c.ctx, file, params, *format)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", path, closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	result := &services.ExportResult{Path: path, Rows: rows}
+	if c.json {
+		return c.print(result, nil, nil)
+	}
+	return c.message("Exported %d transaction(s) to %s", rows, path)
+}
@@ -0,0 +1,230 @@
+// Command cashflow uses the ledger without the desktop app, e.g. over SSH
+// or from scripts. It works on the same database as the app, in
+// ~/.cashflow, through the same services.
+//
+// Usage:
+//
+//	cashflow <command> [subcommand] [arguments] [flags]
+//
+// Every command takes -user, the ID of the user whose books to use, and
+// -json, to print JSON instead of a table. Run "cashflow help" for the list
+// of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"cashflow/internal/api"
+	"cashflow/internal/database"
+	"cashflow/internal/services"
+)
+
+const usage = `Usage: cashflow <command> [subcommand] [arguments] [flags]
+
+Transactions:
+  tx list [filters]              list transactions, newest first
+  tx get ID                      show a transaction
+  tx search QUERY                full-text search
+  tx add -type T -description D -amount N [-date YYYY-MM-DD] [fields]
+  tx update ID [fields]          change the given fields of a transaction
+  tx delete ID...                move transactions to the trash
+  stats [-from D] [-to D]        income, expenses and profit for a period
+
+Categories and payment methods:
+  categories list [-type T] [-active]
+  categories add NAME -type income|expense|both [-color C] [-icon I] [-parent P]
+  categories update ID|NAME [-name N] [-type T] [-color C] [-icon I] [-parent P] [-active=B]
+  categories delete ID|NAME
+  payment-methods list [-active]
+  payment-methods add NAME [-description D]
+  payment-methods update ID|NAME [-name N] [-description D] [-active=B]
+  payment-methods delete ID|NAME
+
+Files:
+  import FILE                    import a CSV, OFX/QFX or QIF statement
+  export FILE|- [-format F]      export transactions as csv, xlsx or json
+
+HTTP API:
+  serve [-port N]                serve the HTTP API on 127.0.0.1
+  tokens list|create NAME|revoke ID
+
+Flags of every command:
+  -user ID                       whose books to use (default "default")
+  -json                          print JSON instead of a table
+
+Run "cashflow <command> -h" for the flags of a command.
+`
+
+// errUsage is returned when the command line is wrong and has been
+// explained already
+var errUsage = errors.New("usage")
+
+func main() {
+	err := run(os.Args[1:])
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "cashflow:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return nil
+	}
+
+	c, err := newCLI()
+	if err != nil {
+		return err
+	}
+	defer c.db.Close()
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "tx", "transactions":
+		return c.transactionCommand(args)
+	case "stats":
+		return c.stats(args)
+	case "categories":
+		return c.categoryCommand(args)
+	case "payment-methods":
+		return c.paymentMethodCommand(args)
+	case "import":
+		return c.importFile(args)
+	case "export":
+		return c.export(args)
+	case "serve":
+		return c.serve(args)
+	case "tokens":
+		return c.tokenCommand(args)
+	}
+	return c.usageError("unknown command %q", command)
+}
+
+// cli runs commands on behalf of a user
+type cli struct {
+	ctx  context.Context
+	db   *database.Database
+	out  io.Writer
+	user string
+	json bool
+
+	users          *services.UserService
+	transactions   *services.TransactionService
+	categories     *services.CategoryService
+	paymentMethods *services.PaymentMethodService
+	accounts       *services.AccountService
+	recurring      *services.RecurringService
+	imports        *services.ImportService
+	exports        *services.ExportService
+	tokens         *services.APITokenService
+	convert        *api.Converter
+}
+
+func newCLI() (*cli, error) {
+	database, err := database.New()
+	if err != nil {
+		return nil, err
+	}
+
+	// Budget alerts go to standard error so they do not mix with output
+	// meant for pipes
+	services.NewBudgetService(database).WatchTransactions(func(_ context.Context, alert services.BudgetStatus) {
+		fmt.Fprintf(os.Stderr, "cashflow: budget for %s has used %.0f%% of %.2f %s in %s\n",
+			alert.CategoryName, alert.PercentUsed, alert.Available, alert.Currency, alert.Label)
+	})
+
+	return &cli{
+		ctx:            context.Background(),
+		db:             database,
+		out:            os.Stdout,
+		user:           services.DefaultUserID,
+		users:          services.NewUserService(database),
+		transactions:   services.NewTransactionService(database),
+		categories:     services.NewCategoryService(database),
+		paymentMethods: services.NewPaymentMethodService(database),
+		accounts:       services.NewAccountService(database),
+		recurring:      services.NewRecurringService(database),
+		imports:        services.NewImportService(database),
+		exports:        services.NewExportService(database),
+		tokens:         services.NewAPITokenService(database),
+		convert:        api.NewConverter(database),
+	}, nil
+}
+
+// flags makes the flag set of a command, with the flags every command has
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("cashflow "+name, flag.ContinueOnError)
+	fs.StringVar(&c.user, "user", c.user, "ID of the user whose books to use")
+	fs.BoolVar(&c.json, "json", false, "print JSON instead of a table")
+	return fs
+}
+
+// parse parses the flags of a command, which may come before or after its
+// arguments, and checks that it got n arguments; n < 0 means at least one.
+// It returns the arguments.
+func (c *cli) parse(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		// Everything after "--" is an argument
+		if i := len(args) - fs.NArg() - 1; i >= 0 && args[i] == "--" {
+			rest = append(rest, fs.Args()...)
+			break
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	switch {
+	case n < 0 && len(rest) == 0:
+		return nil, c.usageError("%s needs an argument", fs.Name())
+	case n >= 0 && len(rest) != n:
+		return nil, c.usageError("%s takes %d argument(s), got %d", fs.Name(), n, len(rest))
+	}
+
+	// Check the user, so a typo doesn't start books of its own
+	if _, err := c.users.GetUser(c.ctx, c.user); err != nil {
+		return nil, fmt.Errorf("user %q: %w", c.user, err)
+	}
+	c.ctx = services.WithActor(context.Background(), c.user)
+	return rest, nil
+}
+
+// setFlags tells which flags were given on the command line
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set
+}
+
+// usageError reports a wrong command line
+func (c *cli) usageError(format string, args ...any) error {
+	fmt.Fprintf(os.Stderr, "cashflow: "+format+"\n", args...)
+	fmt.Fprintln(os.Stderr, `Run "cashflow help" for usage.`)
+	return errUsage
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"cashflow/internal/api"
+)
+
+// print writes v as indented JSON with -json, and otherwise the table made
+// of header and rows. A nil header prints the rows alone.
+func (c *cli) print(v any, header []string, rows [][]string) error {
+	if c.json {
+		encoder := json.NewEncoder(c.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	if header != nil {
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// message prints the outcome of a command that has no result to show,
+// as {"message": ...} with -json
+func (c *cli) message(format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	if c.json {
+		return c.print(map[string]string{"message": message}, nil, nil)
+	}
+	_, err := fmt.Fprintln(c.out, message)
+	return err
+}
+
+var transactionHeader = []string{"ID", "DATE", "TYPE", "DESCRIPTION", "CATEGORY", "AMOUNT", "CURRENCY", "STATUS", "CLEARED"}
+
+func transactionRow(t *api.Transaction) []string {
+	return []string{
+		t.ID, t.TransactionDate, t.Type, truncate(t.Description, 40), t.Category,
+		formatAmount(t.Amount), t.Currency, t.PaymentStatus, t.ClearedStatus,
+	}
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// truncate shortens s to n characters for a table cell; tabs and newlines
+// would break the table
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
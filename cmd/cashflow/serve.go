@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cashflow/internal/api"
+)
+
+// serve runs the HTTP API without the app until interrupted, materializing
+// recurring transactions as the app would
+func (c *cli) serve(args []string) error {
+	fs := c.flags("serve")
+	port := fs.Int("port", api.DefaultPort, "port to listen on at 127.0.0.1")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := api.NewServer(c.db, c.recurring)
+	if err := server.Start(*port); err != nil {
+		return err
+	}
+	c.recurring.Start(ctx)
+	defer c.recurring.Stop()
+	fmt.Fprintf(os.Stderr, "Serving the API at http://%s/api; press Ctrl+C to stop\n", server.Addr())
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Stop(shutdownCtx)
+}
+
+func (c *cli) tokenCommand(args []string) error {
+	if len(args) == 0 {
+		return c.usageError("tokens needs a subcommand: list, create or revoke")
+	}
+	switch args[0] {
+	case "list", "ls":
+		return c.listTokens(args[1:])
+	case "create", "add":
+		return c.createToken(args[1:])
+	case "revoke", "delete", "rm":
+		return c.revokeToken(args[1:])
+	}
+	return c.usageError("unknown tokens subcommand %q", args[0])
+}
+
+// apiToken is an API token as the CLI prints it; Token is only set when it
+// is created
+type apiToken struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Prefix     string `json:"prefix"`
+	Token      string `json:"token,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+func (c *cli) listTokens(args []string) error {
+	fs := c.flags("tokens list")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	tokens, err := c.tokens.ListAPITokens(c.ctx, c.user)
+	if err != nil {
+		return err
+	}
+	result := make([]apiToken, 0, len(tokens))
+	rows := make([][]string, 0, len(tokens))
+	for _, t := range tokens {
+		token := apiToken{ID: t.ID, Name: t.Name, Prefix: t.TokenPrefix, CreatedAt: formatTime(t.CreatedAt.Time), LastUsedAt: formatTime(t.LastUsedAt.Time)}
+		result = append(result, token)
+		rows = append(rows, []string{token.ID, token.Name, token.Prefix + "…", token.CreatedAt, token.LastUsedAt})
+	}
+	return c.print(result, []string{"ID", "NAME", "TOKEN", "CREATED", "LAST USED"}, rows)
+}
+
+func (c *cli) createToken(args []string) error {
+	fs := c.flags("tokens create")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	created, err := c.tokens.CreateAPIToken(c.ctx, c.user, args[0])
+	if err != nil {
+		return err
+	}
+	token := apiToken{ID: created.ID, Name: created.Name, Prefix: created.TokenPrefix, Token: created.Token, CreatedAt: formatTime(created.CreatedAt.Time)}
+	if c.json {
+		return c.print(token, nil, nil)
+	}
+	return c.message("%s\n\nThis is the only time the token is shown. Send it as \"Authorization: Bearer <token>\".", created.Token)
+}
+
+func (c *cli) revokeToken(args []string) error {
+	fs := c.flags("tokens revoke")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if err := c.tokens.RevokeAPIToken(c.ctx, c.user, args[0]); err != nil {
+		return err
+	}
+	return c.message("Revoked token %s", args[0])
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cashflow/internal/api"
+	"cashflow/internal/services"
+)
+
+func (c *cli) transactionCommand(args []string) error {
+	if len(args) == 0 {
+		return c.usageError("tx needs a subcommand: list, get, search, add, update or delete")
+	}
+	switch args[0] {
+	case "list", "ls":
+		return c.listTransactions(args[1:])
+	case "get", "show":
+		return c.getTransaction(args[1:])
+	case "search":
+		return c.searchTransactions(args[1:])
+	case "add", "create":
+		return c.addTransaction(args[1:])
+	case "update", "edit":
+		return c.updateTransaction(args[1:])
+	case "delete", "rm":
+		return c.deleteTransactions(args[1:])
+	}
+	return c.usageError("unknown tx subcommand %q", args[0])
+}
+
+// listFlags are the filters of tx list and export
+type listFlags struct {
+	from, to, types, categories, statuses, paymentMethods string
+	account, contact, customer, search                    string
+	minDue, maxDue                                        float64
+}
+
+func addListFlags(fs *flag.FlagSet) *listFlags {
+	f := &listFlags{}
+	fs.StringVar(&f.from, "from", "", "first date, YYYY-MM-DD")
+	fs.StringVar(&f.to, "to", "", "last date, YYYY-MM-DD")
+	fs.StringVar(&f.types, "type", "", "comma-separated types: income, expense, sale, purchase")
+	fs.StringVar(&f.categories, "category", "", "comma-separated category names or IDs")
+	fs.StringVar(&f.statuses, "status", "", "comma-separated payment statuses: pending, completed, partial, cancelled")
+	fs.StringVar(&f.paymentMethods, "payment-method", "", "comma-separated payment method names or IDs")
+	fs.StringVar(&f.account, "account", "", "account name or ID")
+	fs.StringVar(&f.contact, "contact", "", "contact ID")
+	fs.StringVar(&f.customer, "customer", "", "customer or vendor name contains")
+	fs.StringVar(&f.search, "search", "", "description contains")
+	fs.Float64Var(&f.minDue, "min-due", 0, "minimum due amount")
+	fs.Float64Var(&f.maxDue, "max-due", 0, "maximum due amount")
+	return f
+}
+
+// params turns the filters into list parameters. Unlike the app, the CLI
+// never applies the user's default filter: it lists what it is asked for.
+func (f *listFlags) params(c *cli) (services.ListTransactionParams, error) {
+	params := services.ListTransactionParams{
+		CreatedBy:            c.user,
+		FromDate:             f.from,
+		ToDate:               f.to,
+		TypeFilter:           splitList(f.types),
+		PaymentStatusFilter:  splitList(f.statuses),
+		ContactFilter:        f.contact,
+		CustomerVendorSearch: f.customer,
+		DescriptionSearch:    f.search,
+		MinDueAmount:         f.minDue,
+		MaxDueAmount:         f.maxDue,
+		IgnoreDefaultFilter:  true,
+	}
+	if err := checkDates(f.from, f.to); err != nil {
+		return params, err
+	}
+	for _, name := range splitList(f.categories) {
+		id, err := c.categoryID(name)
+		if err != nil {
+			return params, err
+		}
+		params.CategoryFilter = append(params.CategoryFilter, id)
+	}
+	for _, name := range splitList(f.paymentMethods) {
+		id, err := c.paymentMethodID(name)
+		if err != nil {
+			return params, err
+		}
+		params.PaymentMethodFilter = append(params.PaymentMethodFilter, id)
+	}
+	var err error
+	params.AccountFilter, err = c.accountID(f.account)
+	return params, err
+}
+
+func (c *cli) listTransactions(args []string) error {
+	fs := c.flags("tx list")
+	filters := addListFlags(fs)
+	limit := fs.Int("limit", 50, "how many transactions to list, 0 for all")
+	offset := fs.Int("offset", 0, "how many transactions to skip")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+
+	params, err := filters.params(c)
+	if err != nil {
+		return err
+	}
+	params.Limit = *limit
+	params.Offset = *offset
+	transactions, err := c.transactions.ListTransactions(c.ctx, params)
+	if err != nil {
+		return err
+	}
+	return c.printTransactions(c.convert.Transactions(c.ctx, transactions))
+}
+
+func (c *cli) getTransaction(args []string) error {
+	fs := c.flags("tx get")
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := c.transactions.GetTransaction(c.ctx, c.user, args[0])
+	if err != nil {
+		return err
+	}
+	t := c.convert.Transaction(c.ctx, transaction)
+	if c.json {
+		return c.print(t, nil, nil)
+	}
+
+	rows := [][]string{
+		{"ID", t.ID},
+		{"Date", t.TransactionDate},
+		{"Type", t.Type},
+		{"Description", t.Description},
+		{"Amount", formatAmount(t.Amount) + " " + t.Currency},
+		{"Tax", formatAmount(t.TaxAmount)},
+		{"Discount", formatAmount(t.DiscountAmount)},
+		{"Due", formatAmount(t.DueAmount)},
+		{"Net", formatAmount(t.NetAmount)},
+		{"Category", t.Category},
+		{"Payment method", t.PaymentMethod},
+		{"Account", t.Account},
+		{"Payment status", t.PaymentStatus},
+		{"Cleared", t.ClearedStatus},
+		{"Customer/vendor", t.CustomerVendor},
+		{"Reference", t.ReferenceNumber},
+		{"Invoice", t.InvoiceNumber},
+		{"Due date", t.DueDate},
+		{"Tags", strings.Join(t.Tags, ", ")},
+		{"Notes", truncate(t.Notes, 200)},
+	}
+	if t.IsRecurring {
+		rows = append(rows, []string{"Recurring", t.RecurringFrequency + " until " + t.RecurringEndDate})
+	}
+	return c.print(t, nil, rows)
+}
+
+func (c *cli) searchTransactions(args []string) error {
+	fs := c.flags("tx search")
+	limit := fs.Int("limit", 50, "how many matches to list")
+	offset := fs.Int("offset", 0, "how many matches to skip")
+	args, err := c.parse(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.transactions.SearchTransactions(c.ctx, c.user, strings.Join(args, " "), *limit, *offset)
+	if err != nil {
+		return err
+	}
+	response := make([]api.SearchResult, 0, len(results))
+	for _, result := range results {
+		response = append(response, api.SearchResult{
+			Transaction: *c.convert.Transaction(c.ctx, &result.Transaction),
+			Snippet:     result.Snippet,
+			Score:       result.Score,
+		})
+	}
+	if c.json {
+		return c.print(response, nil, nil)
+	}
+	transactions := make([]api.Transaction, 0, len(response))
+	for _, r := range response {
+		transactions = append(transactions, r.Transaction)
+	}
+	return c.printTransactions(transactions)
+}
+
+// transactionFlags are the fields tx add and tx update set
+type transactionFlags struct {
+	typ, description, date, category, paymentMethod, account, status string
+	customer, contact, reference, invoice, notes, tags, dueDate      string
+	currency, recurring, until                                       string
+	amount, tax, discount, due, rate                                 float64
+}
+
+func addTransactionFlags(fs *flag.FlagSet) *transactionFlags {
+	f := &transactionFlags{}
+	fs.StringVar(&f.typ, "type", "", "income, expense, sale or purchase")
+	fs.StringVar(&f.description, "description", "", "description")
+	fs.Float64Var(&f.amount, "amount", 0, "amount")
+	fs.StringVar(&f.date, "date", "", "transaction date, YYYY-MM-DD (default today)")
+	fs.StringVar(&f.category, "category", "", "category name or ID")
+	fs.StringVar(&f.paymentMethod, "payment-method", "", "payment method name or ID")
+	fs.StringVar(&f.account, "account", "", "account name or ID")
+	fs.StringVar(&f.status, "status", "", "payment status: pending, completed, partial or cancelled")
+	fs.StringVar(&f.customer, "customer", "", "customer or vendor")
+	fs.StringVar(&f.contact, "contact", "", "contact ID")
+	fs.StringVar(&f.reference, "ref", "", "reference number")
+	fs.StringVar(&f.invoice, "invoice", "", "invoice number")
+	fs.StringVar(&f.notes, "notes", "", "notes")
+	fs.StringVar(&f.tags, "tags", "", "comma-separated tags")
+	fs.Float64Var(&f.tax, "tax", 0, "tax amount")
+	fs.Float64Var(&f.discount, "discount", 0, "discount amount")
+	fs.Float64Var(&f.due, "due", 0, "amount still due")
+	fs.StringVar(&f.dueDate, "due-date", "", "due date, YYYY-MM-DD")
+	fs.StringVar(&f.currency, "currency", "", "currency code (default the base currency)")
+	fs.Float64Var(&f.rate, "rate", 0, "exchange rate to the base currency (default looked up)")
+	fs.StringVar(&f.recurring, "recurring", "", "repeat daily, weekly, monthly, quarterly or yearly")
+	fs.StringVar(&f.until, "until", "", "last date of a recurring transaction, YYYY-MM-DD")
+	return f
+}
+
+func (c *cli) addTransaction(args []string) error {
+	fs := c.flags("tx add")
+	f := addTransactionFlags(fs)
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if f.typ == "" || f.description == "" || f.amount == 0 {
+		return c.usageError("tx add needs -type, -description and -amount")
+	}
+	if f.date == "" {
+		f.date = time.Now().Format("2006-01-02")
+	}
+	if err := checkDates(f.date, f.dueDate, f.until); err != nil {
+		return err
+	}
+	category, paymentMethod, account, err := c.resolveTransactionNames(f)
+	if err != nil {
+		return err
+	}
+
+	params := services.CreateTransactionParams{
+		Type:               f.typ,
+		Description:        f.description,
+		Amount:             f.amount,
+		TransactionDate:    f.date,
+		Category:           category,
+		Tags:               splitList(f.tags),
+		CustomerVendor:     f.customer,
+		ContactID:          f.contact,
+		PaymentMethod:      paymentMethod,
+		Account:            account,
+		PaymentStatus:      f.status,
+		ReferenceNumber:    f.reference,
+		InvoiceNumber:      f.invoice,
+		Notes:              f.notes,
+		TaxAmount:          f.tax,
+		DiscountAmount:     f.discount,
+		DueAmount:          f.due,
+		DueDate:            f.dueDate,
+		Currency:           f.currency,
+		ExchangeRate:       f.rate,
+		IsRecurring:        f.recurring != "",
+		RecurringFrequency: f.recurring,
+		RecurringEndDate:   f.until,
+		CreatedBy:          c.user,
+	}
+	transaction, err := c.transactions.CreateTransaction(c.ctx, params)
+	if err != nil {
+		return err
+	}
+	// Back-dated recurring transactions get their past occurrences right
+	// away; the transaction is saved either way
+	if params.IsRecurring {
+		if _, err := c.recurring.ProcessDueOccurrences(c.ctx, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, "cashflow: recurring:", err)
+		}
+	}
+	return c.printTransactions([]api.Transaction{*c.convert.Transaction(c.ctx, transaction)})
+}
+
+func (c *cli) updateTransaction(args []string) error {
+	fs := c.flags("tx update")
+	f := addTransactionFlags(fs)
+	args, err := c.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	if err := checkDates(f.date, f.dueDate, f.until); err != nil {
+		return err
+	}
+	category, paymentMethod, account, err := c.resolveTransactionNames(f)
+	if err != nil {
+		return err
+	}
+
+	current, err := c.transactions.GetTransaction(c.ctx, c.user, args[0])
+	if err != nil {
+		return err
+	}
+	t := c.convert.Transaction(c.ctx, current)
+	params := services.UpdateTransactionParams{
+		Type:               t.Type,
+		Description:        t.Description,
+		Amount:             t.Amount,
+		TransactionDate:    t.TransactionDate,
+		Category:           t.CategoryID,
+		Tags:               t.Tags,
+		CustomerVendor:     t.CustomerVendor,
+		ContactID:          t.ContactID,
+		PaymentMethod:      t.PaymentMethodID,
+		Account:            t.AccountID,
+		PaymentStatus:      t.PaymentStatus,
+		ReferenceNumber:    t.ReferenceNumber,
+		InvoiceNumber:      t.InvoiceNumber,
+		Notes:              t.Notes,
+		Attachments:        t.Attachments,
+		TaxAmount:          t.TaxAmount,
+		DiscountAmount:     t.DiscountAmount,
+		DueAmount:          t.DueAmount,
+		DueDate:            t.DueDate,
+		Currency:           t.Currency,
+		ExchangeRate:       t.ExchangeRate,
+		IsRecurring:        t.IsRecurring,
+		RecurringFrequency: t.RecurringFrequency,
+		RecurringEndDate:   t.RecurringEndDate,
+	}
+
+	// Only the fields given on the command line change
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "type":
+			params.Type = f.typ
+		case "description":
+			params.Description = f.description
+		case "amount":
+			params.Amount = f.amount
+		case "date":
+			params.TransactionDate = f.date
+		case "category":
+			params.Category = category
+		case "payment-method":
+			params.PaymentMethod = paymentMethod
+		case "account":
+			params.Account = account
+		case "status":
+			params.PaymentStatus = f.status
+		case "customer":
+			// A new name links the contact of that name, unless -contact
+			// says which
+			params.CustomerVendor = f.customer
+			params.ContactID = f.contact
+		case "contact":
+			params.ContactID = f.contact
+		case "ref":
+			params.ReferenceNumber = f.reference
+		case "invoice":
+			params.InvoiceNumber = f.invoice
+		case "notes":
+			params.Notes = f.notes
+		case "tags":
+			params.Tags = splitList(f.tags)
+		case "tax":
+			params.TaxAmount = f.tax
+		case "discount":
+			params.DiscountAmount = f.discount
+		case "due":
+			params.DueAmount = f.due
+		case "due-date":
+			params.DueDate = f.dueDate
+		case "currency":
+			params.Currency = f.currency
+		case "rate":
+			params.ExchangeRate = f.rate
+		case "recurring":
+			params.IsRecurring = f.recurring != ""
+			params.RecurringFrequency = f.recurring
+		case "until":
+			params.RecurringEndDate = f.until
+		}
+	})
+
+	transaction, err := c.transactions.UpdateTransaction(c.ctx, c.user, current.ID, params)
+	if err != nil {
+		return err
+	}
+	return c.printTransactions([]api.Transaction{*c.convert.Transaction(c.ctx, transaction)})
+}
+
+func (c *cli) deleteTransactions(args []string) error {
+	fs := c.flags("tx delete")
+	args, err := c.parse(fs, args, -1)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args {
+		if err := c.transactions.DeleteTransaction(c.ctx, c.user, id); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	return c.message("Moved %d transaction(s) to the trash", len(args))
+}
+
+func (c *cli) stats(args []string) error {
+	fs := c.flags("stats")
+	from := fs.String("from", "", "first date, YYYY-MM-DD")
+	to := fs.String("to", "", "last date, YYYY-MM-DD")
+	currency := fs.String("currency", "", "currency to report in (default the base currency)")
+	if _, err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	if err := checkDates(*from, *to); err != nil {
+		return err
+	}
+
+	stats, err := c.transactions.GetTransactionStats(c.ctx, services.StatsParams{
+		CreatedBy: c.user,
+		FromDate:  *from,
+		ToDate:    *to,
+		Currency:  *currency,
+	})
+	if err != nil {
+		return err
+	}
+	rows := [][]string{
+		{"Currency", stats.Currency},
+		{"Income", formatAmount(stats.TotalIncome), fmt.Sprintf("%d transaction(s)", stats.TotalIncomeCount)},
+		{"Expenses", formatAmount(stats.TotalExpenses), fmt.Sprintf("%d transaction(s)", stats.TotalExpenseCount)},
+		{"Net profit", formatAmount(stats.NetProfit)},
+		{"Average", formatAmount(stats.AverageTransaction)},
+		{"Pending income", formatAmount(stats.PendingIncome)},
+		{"Pending expenses", formatAmount(stats.PendingExpenses)},
+	}
+	// Amounts without an exchange rate are not in the totals above
+	for _, u := range stats.Unconverted {
+		rows = append(rows,
+			[]string{"Income in " + u.Currency, formatAmount(u.Income), "no exchange rate"},
+			[]string{"Expenses in " + u.Currency, formatAmount(u.Expenses), "no exchange rate"},
+		)
+	}
+	return c.print(stats, nil, rows)
+}
+
+func (c *cli) printTransactions(transactions []api.Transaction) error {
+	rows := make([][]string, 0, len(transactions))
+	for i := range transactions {
+		rows = append(rows, transactionRow(&transactions[i]))
+	}
+	return c.print(transactions, transactionHeader, rows)
+}
+
+// resolveTransactionNames looks up the category, payment method and account
+// the flags name
+func (c *cli) resolveTransactionNames(f *transactionFlags) (category, paymentMethod, account string, err error) {
+	if category, err = c.categoryID(f.category); err != nil {
+		return
+	}
+	if paymentMethod, err = c.paymentMethodID(f.paymentMethod); err != nil {
+		return
+	}
+	account, err = c.accountID(f.account)
+	return
+}
+
+// accountID finds an account of the user by ID or name
+func (c *cli) accountID(nameOrID string) (string, error) {
+	if nameOrID == "" {
+		return "", nil
+	}
+	accounts, err := c.accounts.ListAccounts(c.ctx, c.user)
+	if err != nil {
+		return "", err
+	}
+	for _, account := range accounts {
+		if account.ID == nameOrID || strings.EqualFold(account.Name, nameOrID) {
+			return account.ID, nil
+		}
+	}
+	return "", fmt.Errorf("unknown account %q", nameOrID)
+}
+
+// checkDates checks that dates given on the command line are YYYY-MM-DD
+func checkDates(dates ...string) error {
+	for _, date := range dates {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "cashflow/internal/db/sqlc"
+	"cashflow/internal/services"
+)
+
+// Transactions
+
+func (s *Server) listTransactions(r *http.Request, userID string) (any, error) {
+	q := r.URL.Query()
+	params := services.ListTransactionParams{
+		CreatedBy:            userID,
+		FromDate:             q.Get("from_date"),
+		ToDate:               q.Get("to_date"),
+		TypeFilter:           listParam(q.Get("type")),
+		CategoryFilter:       listParam(q.Get("category")),
+		PaymentStatusFilter:  listParam(q.Get("payment_status")),
+		PaymentMethodFilter:  listParam(q.Get("payment_method")),
+		ContactFilter:        q.Get("contact"),
+		AccountFilter:        q.Get("account"),
+		CustomerVendorSearch: q.Get("customer_vendor"),
+		DescriptionSearch:    q.Get("search"),
+		// Scripts get what they ask for, not the user's default filter
+		IgnoreDefaultFilter: true,
+	}
+	var err error
+	if params.MinDueAmount, err = floatParam(q.Get("min_due_amount")); err != nil {
+		return nil, err
+	}
+	if params.MaxDueAmount, err = floatParam(q.Get("max_due_amount")); err != nil {
+		return nil, err
+	}
+	if params.Limit, params.Offset, err = pageParams(r); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.transactions.ListTransactions(r.Context(), params)
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.Transactions(r.Context(), transactions), nil
+}
+
+func (s *Server) createTransaction(r *http.Request, userID string) (any, error) {
+	var params services.CreateTransactionParams
+	if err := decodeBody(r, &params); err != nil {
+		return nil, err
+	}
+	params.CreatedBy = userID
+
+	transaction, err := s.transactions.CreateTransaction(r.Context(), params)
+	if err != nil {
+		return nil, err
+	}
+	// Back-dated recurring transactions get their past occurrences right
+	// away; the transaction is saved either way
+	if params.IsRecurring && s.recurring != nil {
+		if _, err := s.recurring.ProcessDueOccurrences(r.Context(), time.Now()); err != nil {
+			log.Printf("api: recurring: %v", err)
+		}
+	}
+	return s.convert.Transaction(r.Context(), transaction), nil
+}
+
+func (s *Server) searchTransactions(r *http.Request, userID string) (any, error) {
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		return nil, badRequest("q is required")
+	}
+	limit, offset, err := pageParams(r)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = 50
+	}
+
+	results, err := s.transactions.SearchTransactions(r.Context(), userID, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]SearchResult, 0, len(results))
+	for _, result := range results {
+		response = append(response, SearchResult{
+			Transaction: *s.convert.Transaction(r.Context(), &result.Transaction),
+			Snippet:     result.Snippet,
+			Score:       result.Score,
+		})
+	}
+	return response, nil
+}
+
+func (s *Server) getTransaction(r *http.Request, userID string) (any, error) {
+	transaction, err := s.transactions.GetTransaction(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.Transaction(r.Context(), transaction), nil
+}
+
+func (s *Server) updateTransaction(r *http.Request, userID string) (any, error) {
+	var params services.UpdateTransactionParams
+	if err := decodeBody(r, &params); err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.transactions.UpdateTransaction(r.Context(), userID, r.PathValue("id"), params)
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.Transaction(r.Context(), transaction), nil
+}
+
+func (s *Server) deleteTransaction(r *http.Request, userID string) (any, error) {
+	return nil, s.transactions.DeleteTransaction(r.Context(), userID, r.PathValue("id"))
+}
+
+func (s *Server) getStats(r *http.Request, userID string) (any, error) {
+	q := r.URL.Query()
+	return s.transactions.GetTransactionStats(r.Context(), services.StatsParams{
+		CreatedBy: userID,
+		FromDate:  q.Get("from_date"),
+		ToDate:    q.Get("to_date"),
+		Currency:  q.Get("currency"),
+	})
+}
+
+// Categories
+
+// categoryRequest creates or replaces a category; IsActive defaults to
+// true for new categories and to what it was for existing ones
+type categoryRequest struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Color    string `json:"color"`
+	Icon     string `json:"icon"`
+	ParentID string `json:"parent_id"`
+	IsActive *bool  `json:"is_active"`
+}
+
+func (c *categoryRequest) validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return badRequest("name is required")
+	}
+	switch c.Type {
+	case "income", "expense", "both":
+		return nil
+	}
+	return badRequest("type must be income, expense or both")
+}
+
+func (s *Server) listCategories(r *http.Request, userID string) (any, error) {
+	var categories []db.Category
+	var err error
+	switch q := r.URL.Query(); {
+	case q.Get("type") != "":
+		categories, err = s.categories.ListCategoriesByType(r.Context(), q.Get("type"))
+	case q.Get("active") == "true":
+		categories, err = s.categories.ListActiveCategories(r.Context())
+	default:
+		categories, err = s.categories.ListCategories(r.Context())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Category, 0, len(categories))
+	for _, c := range categories {
+		result = append(result, *s.convert.Category(&c))
+	}
+	return result, nil
+}
+
+func (s *Server) createCategory(r *http.Request, userID string) (any, error) {
+	var req categoryRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categories.CreateCategory(r.Context(), services.CreateCategoryParams{
+		Name:     req.Name,
+		Type:     req.Type,
+		Color:    req.Color,
+		Icon:     req.Icon,
+		ParentID: req.ParentID,
+		IsActive: req.IsActive == nil || *req.IsActive,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.Category(category), nil
+}
+
+func (s *Server) getCategory(r *http.Request, userID string) (any, error) {
+	category, err := s.categories.GetCategory(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.Category(category), nil
+}
+
+func (s *Server) updateCategory(r *http.Request, userID string) (any, error) {
+	current, err := s.categories.GetCategory(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	var req categoryRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	category, err := s.categories.UpdateCategory(r.Context(), current.ID, services.UpdateCategoryParams{
+		Name:     req.Name,
+		Type:     req.Type,
+		Color:    req.Color,
+		Icon:     req.Icon,
+		ParentID: req.ParentID,
+		IsActive: boolOr(req.IsActive, current.IsActive.Bool),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.Category(category), nil
+}
+
+func (s *Server) deleteCategory(r *http.Request, userID string) (any, error) {
+	if _, err := s.categories.GetCategory(r.Context(), r.PathValue("id")); err != nil {
+		return nil, err
+	}
+	return nil, s.categories.DeleteCategory(r.Context(), r.PathValue("id"))
+}
+
+// Payment methods
+
+// paymentMethodRequest creates or replaces a payment method; IsActive
+// defaults as for categories
+type paymentMethodRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+func (s *Server) listPaymentMethods(r *http.Request, userID string) (any, error) {
+	var paymentMethods []db.PaymentMethod
+	var err error
+	if r.URL.Query().Get("active") == "true" {
+		paymentMethods, err = s.paymentMethods.ListActivePaymentMethods(r.Context())
+	} else {
+		paymentMethods, err = s.paymentMethods.ListPaymentMethods(r.Context())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PaymentMethod, 0, len(paymentMethods))
+	for _, pm := range paymentMethods {
+		result = append(result, *s.convert.PaymentMethod(&pm))
+	}
+	return result, nil
+}
+
+func (s *Server) createPaymentMethod(r *http.Request, userID string) (any, error) {
+	var req paymentMethodRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, badRequest("name is required")
+	}
+
+	paymentMethod, err := s.paymentMethods.CreatePaymentMethod(r.Context(), req.Name, req.Description, req.IsActive == nil || *req.IsActive)
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.PaymentMethod(paymentMethod), nil
+}
+
+func (s *Server) getPaymentMethod(r *http.Request, userID string) (any, error) {
+	paymentMethod, err := s.paymentMethods.GetPaymentMethod(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.PaymentMethod(paymentMethod), nil
+}
+
+func (s *Server) updatePaymentMethod(r *http.Request, userID string) (any, error) {
+	current, err := s.paymentMethods.GetPaymentMethod(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return nil, err
+	}
+	var req paymentMethodRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, badRequest("name is required")
+	}
+
+	paymentMethod, err := s.paymentMethods.UpdatePaymentMethod(r.Context(), current.ID, req.Name, req.Description, boolOr(req.IsActive, current.IsActive.Bool))
+	if err != nil {
+		return nil, err
+	}
+	return s.convert.PaymentMethod(paymentMethod), nil
+}
+
+func (s *Server) deletePaymentMethod(r *http.Request, userID string) (any, error) {
+	if _, err := s.paymentMethods.GetPaymentMethod(r.Context(), r.PathValue("id")); err != nil {
+		return nil, err
+	}
+	return nil, s.paymentMethods.DeletePaymentMethod(r.Context(), r.PathValue("id"))
+}
+
+// Query parameters
+
+// listParam splits a comma-separated query parameter
+func listParam(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func floatParam(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, badRequest("invalid number %q", value)
+	}
+	return f, nil
+}
+
+// pageParams reads the limit and offset query parameters
+func pageParams(r *http.Request) (limit, offset int, err error) {
+	q := r.URL.Query()
+	for _, p := range []struct {
+		name  string
+		value *int
+	}{{"limit", &limit}, {"offset", &offset}} {
+		if v := q.Get(p.name); v != "" {
+			if *p.value, err = strconv.Atoi(v); err != nil || *p.value < 0 {
+				return 0, 0, badRequest("invalid %s %q", p.name, v)
+			}
+		}
+	}
+	return limit, offset, nil
+}
+
+func boolOr(b *bool, otherwise bool) bool {
+	if b == nil {
+		return otherwise
+	}
+	return *b
+}
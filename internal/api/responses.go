@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+	"cashflow/internal/services"
+)
+
+// Transaction is a transaction as the API returns it, with amounts in the
+// transaction's currency. It has the same fields as the desktop app's.
+type Transaction struct {
+	ID                  string   `json:"id"`
+	Type                string   `json:"type"`
+	Description         string   `json:"description"`
+	Amount              float64  `json:"amount"`
+	TransactionDate     string   `json:"transaction_date"`
+	Category            string   `json:"category"`
+	CategoryID          string   `json:"category_id"`
+	Tags                []string `json:"tags"`
+	CustomerVendor      string   `json:"customer_vendor"`
+	ContactID           string   `json:"contact_id"`
+	ClearedStatus       string   `json:"cleared_status"`
+	PaymentMethod       string   `json:"payment_method"`
+	PaymentMethodID     string   `json:"payment_method_id"`
+	Account             string   `json:"account"`
+	AccountID           string   `json:"account_id"`
+	PaymentStatus       string   `json:"payment_status"`
+	ReferenceNumber     string   `json:"reference_number"`
+	InvoiceNumber       string   `json:"invoice_number"`
+	Notes               string   `json:"notes"`
+	Attachments         []string `json:"attachments"`
+	TaxAmount           float64  `json:"tax_amount"`
+	DiscountAmount      float64  `json:"discount_amount"`
+	DueAmount           float64  `json:"due_amount"`
+	NetAmount           float64  `json:"net_amount"`
+	Currency            string   `json:"currency"`
+	ExchangeRate        float64  `json:"exchange_rate"`
+	IsRecurring         bool     `json:"is_recurring"`
+	RecurringFrequency  string   `json:"recurring_frequency"`
+	RecurringEndDate    string   `json:"recurring_end_date"`
+	ParentTransactionID string   `json:"parent_transaction_id"`
+	DueDate             string   `json:"due_date"`
+	CreatedBy           string   `json:"created_by"`
+	CreatedAt           string   `json:"created_at"`
+	UpdatedAt           string   `json:"updated_at"`
+	DeletedAt           string   `json:"deleted_at"`
+}
+
+// SearchResult is a search match. Snippet is HTML with the matched words in
+// <mark> elements.
+type SearchResult struct {
+	Transaction
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// Category is a category as the API returns it
+type Category struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Color     string `json:"color"`
+	Icon      string `json:"icon"`
+	ParentID  string `json:"parent_id"`
+	IsActive  bool   `json:"is_active"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// PaymentMethod is a payment method as the API returns it
+type PaymentMethod struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsActive    bool   `json:"is_active"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// Converter turns stored rows into API responses, looking up the names of
+// categories, payment methods and accounts and turning minor units into
+// amounts
+type Converter struct {
+	db         *database.Database
+	currencies *services.CurrencyService
+}
+
+func NewConverter(db *database.Database) *Converter {
+	return &Converter{db: db, currencies: services.NewCurrencyService(db)}
+}
+
+// Transaction converts a transaction
+func (c *Converter) Transaction(ctx context.Context, t *db.Transaction) *Transaction {
+	var tags []string
+	var attachments []string
+	if t.Tags.Valid && t.Tags.String != "" {
+		json.Unmarshal([]byte(t.Tags.String), &tags)
+	}
+	if t.Attachments.Valid && t.Attachments.String != "" {
+		json.Unmarshal([]byte(t.Attachments.String), &attachments)
+	}
+
+	categoryName := ""
+	if t.CategoryID.Valid && t.CategoryID.String != "" {
+		if name, err := c.db.Queries().GetCategoryName(ctx, t.CategoryID.String); err == nil {
+			categoryName = name
+		}
+	}
+	paymentMethodName := ""
+	if t.PaymentMethodID.Valid && t.PaymentMethodID.String != "" {
+		if name, err := c.db.Queries().GetPaymentMethodName(ctx, t.PaymentMethodID.String); err == nil {
+			paymentMethodName = name
+		}
+	}
+	accountName := ""
+	if t.AccountID.Valid && t.AccountID.String != "" {
+		if account, err := c.db.Queries().GetAccount(ctx, t.AccountID.String); err == nil {
+			accountName = account.Name
+		}
+	}
+
+	// Amounts are stored in minor units of the transaction currency
+	toAmount := func(minor int64) float64 {
+		return c.currencies.FromMinorUnits(ctx, minor, t.Currency.String)
+	}
+
+	return &Transaction{
+		ID:                  t.ID,
+		Type:                t.Type,
+		Description:         t.Description,
+		Amount:              toAmount(t.Amount),
+		TransactionDate:     t.TransactionDate.Format("2006-01-02"),
+		Category:            categoryName,
+		CategoryID:          t.CategoryID.String,
+		Tags:                tags,
+		CustomerVendor:      t.CustomerVendor.String,
+		ContactID:           t.ContactID.String,
+		ClearedStatus:       t.ClearedStatus,
+		PaymentMethod:       paymentMethodName,
+		PaymentMethodID:     t.PaymentMethodID.String,
+		Account:             accountName,
+		AccountID:           t.AccountID.String,
+		PaymentStatus:       t.PaymentStatus.String,
+		ReferenceNumber:     t.ReferenceNumber.String,
+		InvoiceNumber:       t.InvoiceNumber.String,
+		Notes:               t.Notes.String,
+		Attachments:         attachments,
+		TaxAmount:           toAmount(t.TaxAmount.Int64),
+		DiscountAmount:      toAmount(t.DiscountAmount.Int64),
+		DueAmount:           toAmount(t.DueAmount.Int64),
+		NetAmount:           toAmount(t.NetAmount.Int64),
+		Currency:            t.Currency.String,
+		ExchangeRate:        t.ExchangeRate.Float64,
+		IsRecurring:         t.IsRecurring.Bool,
+		RecurringFrequency:  t.RecurringFrequency.String,
+		RecurringEndDate:    formatDate(t.RecurringEndDate),
+		ParentTransactionID: t.ParentTransactionID.String,
+		DueDate:             formatDate(t.DueDate),
+		CreatedBy:           t.CreatedBy,
+		CreatedAt:           formatDate(t.CreatedAt),
+		UpdatedAt:           formatDate(t.UpdatedAt),
+		DeletedAt:           formatDate(t.DeletedAt),
+	}
+}
+
+// Transactions converts a list of transactions
+func (c *Converter) Transactions(ctx context.Context, transactions []db.Transaction) []Transaction {
+	result := make([]Transaction, 0, len(transactions))
+	for _, t := range transactions {
+		result = append(result, *c.Transaction(ctx, &t))
+	}
+	return result
+}
+
+// Category converts a category
+func (c *Converter) Category(category *db.Category) *Category {
+	return &Category{
+		ID:        category.ID,
+		Name:      category.Name,
+		Type:      category.Type,
+		Color:     category.Color.String,
+		Icon:      category.Icon.String,
+		ParentID:  category.ParentID.String,
+		IsActive:  category.IsActive.Bool,
+		CreatedAt: formatDate(category.CreatedAt),
+		UpdatedAt: formatDate(category.UpdatedAt),
+	}
+}
+
+// PaymentMethod converts a payment method
+func (c *Converter) PaymentMethod(pm *db.PaymentMethod) *PaymentMethod {
+	return &PaymentMethod{
+		ID:          pm.ID,
+		Name:        pm.Name,
+		Description: pm.Description.String,
+		IsActive:    pm.IsActive.Bool,
+		CreatedAt:   formatDate(pm.CreatedAt),
+		UpdatedAt:   formatDate(pm.UpdatedAt),
+	}
+}
+
+func formatDate(t sql.NullTime) string {
+	if t.Valid {
+		return t.Time.Format("2006-01-02")
+	}
+	return ""
+}
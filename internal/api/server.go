@@ -0,0 +1,245 @@
+// Package api serves the ledger as a REST API over HTTP/JSON, so scripts
+// can use it while the app runs. The server only listens on the loopback
+// interface, and every request but the OpenAPI spec needs an API token of
+// a user, sent as "Authorization: Bearer <token>", which decides whose
+// books it works on.
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cashflow/internal/database"
+	"cashflow/internal/services"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// DefaultPort is the port the server listens on unless told otherwise
+const DefaultPort = 8765
+
+// Server is the HTTP API server. It is created stopped.
+type Server struct {
+	db             *database.Database
+	tokens         *services.APITokenService
+	transactions   *services.TransactionService
+	categories     *services.CategoryService
+	paymentMethods *services.PaymentMethodService
+	recurring      *services.RecurringService
+	convert        *Converter
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer creates a server on top of the services. recurring is the
+// service that materializes recurring transactions, shared with the app so
+// only one of them does.
+func NewServer(db *database.Database, recurring *services.RecurringService) *Server {
+	return &Server{
+		db:             db,
+		tokens:         services.NewAPITokenService(db),
+		transactions:   services.NewTransactionService(db),
+		categories:     services.NewCategoryService(db),
+		paymentMethods: services.NewPaymentMethodService(db),
+		recurring:      recurring,
+		convert:        NewConverter(db),
+	}
+}
+
+// Start listens on 127.0.0.1 at port, DefaultPort when it is 0, and serves
+// requests in the background until Stop is called
+func (s *Server) Start(port int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return fmt.Errorf("API server is already running at %s", s.listener.Addr())
+	}
+	if port == 0 {
+		port = DefaultPort
+	}
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+	server := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("api: %v", err)
+		}
+	}()
+
+	s.server = server
+	s.listener = listener
+	return nil
+}
+
+// Stop shuts the server down, letting requests in progress finish
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server == nil {
+		return nil
+	}
+	err := s.server.Shutdown(ctx)
+	s.server = nil
+	s.listener = nil
+	return err
+}
+
+// Addr returns the address the server listens on, or "" when it is stopped
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Handler returns the API's routes. It can also be served on its own, as
+// the command line's serve command does.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPISpec)
+	})
+
+	mux.Handle("GET /api/transactions", s.auth(s.listTransactions))
+	mux.Handle("POST /api/transactions", s.auth(s.createTransaction))
+	mux.Handle("GET /api/transactions/search", s.auth(s.searchTransactions))
+	mux.Handle("GET /api/transactions/{id}", s.auth(s.getTransaction))
+	mux.Handle("PUT /api/transactions/{id}", s.auth(s.updateTransaction))
+	mux.Handle("DELETE /api/transactions/{id}", s.auth(s.deleteTransaction))
+	mux.Handle("GET /api/stats", s.auth(s.getStats))
+
+	mux.Handle("GET /api/categories", s.auth(s.listCategories))
+	mux.Handle("POST /api/categories", s.auth(s.createCategory))
+	mux.Handle("GET /api/categories/{id}", s.auth(s.getCategory))
+	mux.Handle("PUT /api/categories/{id}", s.auth(s.updateCategory))
+	mux.Handle("DELETE /api/categories/{id}", s.auth(s.deleteCategory))
+
+	mux.Handle("GET /api/payment-methods", s.auth(s.listPaymentMethods))
+	mux.Handle("POST /api/payment-methods", s.auth(s.createPaymentMethod))
+	mux.Handle("GET /api/payment-methods/{id}", s.auth(s.getPaymentMethod))
+	mux.Handle("PUT /api/payment-methods/{id}", s.auth(s.updatePaymentMethod))
+	mux.Handle("DELETE /api/payment-methods/{id}", s.auth(s.deletePaymentMethod))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not found")
+	})
+	return mux
+}
+
+// handlerFunc is an API handler for a request made by a user; what it
+// returns is sent as JSON, and errors as {"error": "..."}
+type handlerFunc func(r *http.Request, userID string) (any, error)
+
+// auth checks the request's token and runs h on behalf of its user
+func (s *Server) auth(h handlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing API token")
+			return
+		}
+		userID, err := s.tokens.Authenticate(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		r = r.WithContext(services.WithActor(r.Context(), userID))
+		result, err := h(r, userID)
+		if err != nil {
+			var apiErr *Error
+			if errors.As(err, &apiErr) {
+				writeError(w, apiErr.Status, apiErr.Message)
+			} else {
+				writeError(w, errorStatus(err), err.Error())
+			}
+			return
+		}
+		if result == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		status := http.StatusOK
+		if r.Method == http.MethodPost {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, result)
+	})
+}
+
+// Error is an error with the HTTP status it is answered with
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func badRequest(format string, args ...any) error {
+	return &Error{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// errorStatus tells which HTTP status a service error stands for. Services
+// report missing rows as "... not found" and failures of the database as
+// "failed to ..."; anything else is a problem with the request.
+func errorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.HasSuffix(message, "not found"):
+		return http.StatusNotFound
+	case strings.HasPrefix(message, "failed to"):
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// decodeBody reads a JSON request body into v
+func decodeBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
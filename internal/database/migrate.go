@@ -0,0 +1,325 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cashflow/migrations"
+)
+
+// baselineVersion is the schema every database created before versioned
+// migrations already contains
+const baselineVersion = 1
+
+type migration struct {
+	version int64
+	name    string
+	up      migrations.MigrationFunc
+	down    migrations.MigrationFunc
+}
+
+// loadMigrations collects the SQL and Go migrations ordered by version
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]migration)
+	for _, file := range files {
+		version, name, err := parseMigrationFilename(file)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err := fs.ReadFile(migrations.FS, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+		up, down, err := parseSQLMigration(string(contents))
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration %s: %w", file, err)
+		}
+
+		if _, exists := byVersion[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d", version)
+		}
+		byVersion[version] = migration{
+			version: version,
+			name:    name,
+			up:      execStatements(up),
+			down:    execStatements(down),
+		}
+	}
+
+	for _, m := range migrations.GoMigrations() {
+		if _, exists := byVersion[m.Version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		byVersion[m.Version] = migration{
+			version: m.Version,
+			name:    m.Name,
+			up:      m.Up,
+			down:    m.Down,
+		}
+	}
+
+	result := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].version < result[j].version })
+	return result, nil
+}
+
+// parseMigrationFilename splits "002_add_index.sql" into 2 and "add_index"
+func parseMigrationFilename(file string) (int64, string, error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+	prefix, name, found := strings.Cut(base, "_")
+	if !found {
+		return 0, "", fmt.Errorf("migration %s is not named <version>_<name>.sql", file)
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("migration %s has an invalid version: %w", file, err)
+	}
+	return version, name, nil
+}
+
+// parseSQLMigration splits a goose-style file into its Up and Down sections
+func parseSQLMigration(contents string) (string, string, error) {
+	var up, down strings.Builder
+	var section *strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch strings.TrimSpace(line) {
+		case "-- +goose Up":
+			section = &up
+			continue
+		case "-- +goose Down":
+			section = &down
+			continue
+		}
+		if section != nil {
+			section.WriteString(line)
+			section.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+	if section == nil {
+		return "", "", fmt.Errorf("missing -- +goose Up annotation")
+	}
+	return up.String(), down.String(), nil
+}
+
+// execStatements turns a block of SQL into a migration function
+func execStatements(statements string) migrations.MigrationFunc {
+	if strings.TrimSpace(statements) == "" {
+		return nil
+	}
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, statements)
+		return err
+	}
+}
+
+// ensureMigrationsTable creates schema_migrations. Databases that already
+// have tables but no migration history were created by the old start-up
+// schema code and are adopted at the baseline version.
+func ensureMigrationsTable(ctx context.Context, conn *sql.DB) error {
+	var exists int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	var legacy int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'transactions'`).Scan(&legacy); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, `
+CREATE TABLE schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	if legacy > 0 {
+		if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, baselineVersion, "init_schema"); err != nil {
+			return fmt.Errorf("failed to record baseline migration: %w", err)
+		}
+	}
+	return nil
+}
+
+// appliedVersions returns the set of migration versions already applied
+func appliedVersions(ctx context.Context, conn *sql.DB) (map[int64]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// migrateUp applies every pending migration in version order
+func migrateUp(ctx context.Context, conn *sql.DB) error {
+	all, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range all {
+		if applied[m.version] {
+			continue
+		}
+		err := runMigration(ctx, conn, m.up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.version, m.name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %03d_%s failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// migrateDown rolls back applied migrations newer than target, newest
+// first. Nothing is rolled back when one of them has no down migration.
+func migrateDown(ctx context.Context, conn *sql.DB, target int64) error {
+	all, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := ensureMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	var pending []migration
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if m.version <= target || !applied[m.version] {
+			continue
+		}
+		if m.down == nil {
+			return fmt.Errorf("migration %03d_%s is irreversible", m.version, m.name)
+		}
+		pending = append(pending, m)
+	}
+
+	for _, m := range pending {
+		err := runMigration(ctx, conn, m.down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %03d_%s failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// runMigration executes fn and record in one transaction. Foreign keys are
+// disabled on the connection for the duration so tables can be rebuilt, and
+// checked before committing.
+func runMigration(ctx context.Context, conn *sql.DB, fn migrations.MigrationFunc, record func(tx *sql.Tx) error) error {
+	c, err := conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if _, err := c.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
+	}
+	defer c.ExecContext(context.Background(), `PRAGMA foreign_keys = ON`)
+
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if fn != nil {
+		if err := fn(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	violation := rows.Next()
+	rows.Close()
+	if violation {
+		return fmt.Errorf("foreign key violations after migration")
+	}
+
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ensureSearchIndex creates the transaction search index if it is missing
+// and SQLite has FTS5, as when the database was migrated by a build without
+// it, and reports whether full-text search is available
+func ensureSearchIndex(ctx context.Context, conn *sql.DB) (bool, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	available, err := migrations.EnsureTransactionsFTS(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	return available, tx.Commit()
+}
+
+// schemaVersion returns the highest applied migration version
+func schemaVersion(ctx context.Context, conn *sql.DB) (int64, error) {
+	var version sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return version.Int64, nil
+}
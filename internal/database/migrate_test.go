@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// openTestDB opens an empty database in a temporary directory
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "cashflow.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func latestVersion(t *testing.T) int64 {
+	t.Helper()
+	all, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return all[len(all)-1].version
+}
+
+func TestMigrateUpDownUp(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestDB(t)
+	latest := latestVersion(t)
+
+	steps := []struct {
+		name    string
+		run     func() error
+		version int64
+	}{
+		{"up", func() error { return migrateUp(ctx, conn) }, latest},
+		{"up again", func() error { return migrateUp(ctx, conn) }, latest},
+		{"down to baseline", func() error { return migrateDown(ctx, conn, baselineVersion) }, baselineVersion},
+		{"up from baseline", func() error { return migrateUp(ctx, conn) }, latest},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		version, err := schemaVersion(ctx, conn)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if version != step.version {
+			t.Fatalf("%s: schema version %d, want %d", step.name, version, step.version)
+		}
+	}
+
+	var n int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories`).Scan(&n); err != nil {
+		t.Fatalf("categories after migrating back up: %v", err)
+	}
+	if n == 0 {
+		t.Error("default categories are missing after migrating back up")
+	}
+}
+
+// createLegacyDatabase gives conn the baseline schema without a
+// schema_migrations table, as databases from before versioned migrations
+// have, with a USD expense "Coffee" of amount
+func createLegacyDatabase(t *testing.T, conn *sql.DB, amount float64) {
+	t.Helper()
+	ctx := context.Background()
+	all, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all[0].version != baselineVersion {
+		t.Fatalf("first migration is %d, want the baseline", all[0].version)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := all[0].up(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO transactions (type, description, amount, transaction_date) VALUES ('expense', 'Coffee', ?, '2024-01-02')`, amount); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateAdoptsLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestDB(t)
+	createLegacyDatabase(t, conn, 3.5)
+	all, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateUp(ctx, conn); err != nil {
+		t.Fatalf("migrating a legacy database: %v", err)
+	}
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range all {
+		if !applied[m.version] {
+			t.Errorf("migration %03d_%s not recorded", m.version, m.name)
+		}
+	}
+
+	var amount int64
+	if err := conn.QueryRowContext(ctx, `SELECT amount FROM transactions WHERE description = 'Coffee'`).Scan(&amount); err != nil {
+		t.Fatal(err)
+	}
+	if amount != 350 {
+		t.Errorf("legacy amount is %d minor units, want 350", amount)
+	}
+}
+
+func TestMigrateDownKeepsLegacyTemplates(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestDB(t)
+	createLegacyDatabase(t, conn, 3.5)
+	if err := migrateUp(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	// The legacy app created the templates table at runtime, so its rows
+	// predate migration 004
+	if _, err := conn.ExecContext(ctx, `INSERT INTO transaction_templates (name, type, currency) VALUES ('Rent', 'expense', 'USD')`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateDown(ctx, conn, baselineVersion); err == nil || !strings.Contains(err.Error(), "004_templates_and_filters") {
+		t.Fatalf("rolling back past 004 with a template: got %v, want 004 to refuse", err)
+	}
+	var n int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM transaction_templates`).Scan(&n); err != nil {
+		t.Fatalf("templates after the rollback: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("%d template(s) left, want 1", n)
+	}
+}
+
+func TestMigrateRoundsFractionalAmountsWhenAsked(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestDB(t)
+	createLegacyDatabase(t, conn, 3.125)
+
+	if err := migrateUp(ctx, conn); err == nil || !strings.Contains(err.Error(), "CASHFLOW_ROUND_AMOUNTS") {
+		t.Fatalf("migrating a fractional amount: got %v, want an error naming CASHFLOW_ROUND_AMOUNTS", err)
+	}
+
+	t.Setenv("CASHFLOW_ROUND_AMOUNTS", "1")
+	if err := migrateUp(ctx, conn); err != nil {
+		t.Fatalf("migrating with rounding: %v", err)
+	}
+	var amount int64
+	if err := conn.QueryRowContext(ctx, `SELECT amount FROM transactions WHERE description = 'Coffee'`).Scan(&amount); err != nil {
+		t.Fatal(err)
+	}
+	if amount != 313 {
+		t.Errorf("rounded amount is %d minor units, want 313", amount)
+	}
+}
+
+func TestParseSQLMigration(t *testing.T) {
+	tests := []struct {
+		name         string
+		contents     string
+		up, down     string
+		reversible   bool
+		wantParseErr bool
+	}{
+		{
+			name:       "up and down",
+			contents:   "-- +goose Up\nCREATE TABLE a (id INTEGER);\n-- +goose Down\nDROP TABLE a;\n",
+			up:         "CREATE TABLE a (id INTEGER);\n",
+			down:       "DROP TABLE a;\n",
+			reversible: true,
+		},
+		{
+			name:     "no down section",
+			contents: "-- +goose Up\nCREATE TABLE a (id INTEGER);\n",
+			up:       "CREATE TABLE a (id INTEGER);\n",
+		},
+		{
+			name:     "empty down section",
+			contents: "-- +goose Up\nCREATE TABLE a (id INTEGER);\n-- +goose Down\n\n",
+			up:       "CREATE TABLE a (id INTEGER);\n",
+			down:     "\n",
+		},
+		{
+			name:         "no annotations",
+			contents:     "CREATE TABLE a (id INTEGER);\n",
+			wantParseErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down, err := parseSQLMigration(tt.contents)
+			if tt.wantParseErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if up != tt.up || down != tt.down {
+				t.Errorf("got up %q down %q, want up %q down %q", up, down, tt.up, tt.down)
+			}
+			if reversible := execStatements(down) != nil; reversible != tt.reversible {
+				t.Errorf("reversible = %v, want %v", reversible, tt.reversible)
+			}
+		})
+	}
+}
+
+func TestSearchIndexTriggersComeBack(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestDB(t)
+	if err := migrateUp(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	available, err := ensureSearchIndex(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !available {
+		t.Skip("SQLite was built without FTS5")
+	}
+
+	// A build without FTS5 drops the triggers and adds transactions the
+	// index does not know about
+	if _, err := conn.ExecContext(ctx, `
+DROP TRIGGER transactions_fts_insert;
+DROP TRIGGER transactions_fts_update;
+DROP TRIGGER transactions_fts_delete;
+INSERT INTO transactions (type, description, amount, transaction_date) VALUES ('expense', 'Consulting', 5000, '2024-01-02');`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ensureSearchIndex(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	var triggers, found int
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'transactions_fts_%'`).Scan(&triggers); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM transactions_fts WHERE transactions_fts MATCH 'consult*'`).Scan(&found); err != nil {
+		t.Fatal(err)
+	}
+	if triggers != 3 || found != 1 {
+		t.Errorf("got %d trigger(s) and %d match(es), want 3 and 1", triggers, found)
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+// schemaSnapshot is the schema sqlc generates the queries against
+const schemaSnapshot = "../db/schema.sql"
+
+const schemaHeader = `-- Schema of a database migrated to the latest version, for sqlc, which
+-- cannot run the Go migrations. Regenerate it with "make schema" after
+-- adding a migration; TestSchemaSnapshot fails while it is out of date.
+`
+
+var update = flag.Bool("update", false, "rewrite the schema snapshot")
+
+// schemaStatements returns the statements that create the schema objects of
+// conn, leaving out the migrations table and the tables SQLite keeps for
+// the full-text search index
+func schemaStatements(ctx context.Context, conn *sql.DB) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `
+SELECT sql FROM sqlite_master
+WHERE sql IS NOT NULL
+  AND name NOT LIKE 'sqlite_%'
+  AND name != 'schema_migrations'
+  AND NOT (type = 'table' AND name LIKE 'transactions_fts_%')
+ORDER BY rowid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []string
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement+";")
+	}
+	return statements, rows.Err()
+}
+
+func TestSchemaSnapshot(t *testing.T) {
+	ctx := context.Background()
+	conn := openTestDB(t)
+	if err := migrateUp(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	statements, err := schemaStatements(ctx, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fts5 bool
+	if err := conn.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if !fts5 {
+			t.Fatal("the snapshot includes the full-text search index; update it with -tags sqlite_fts5")
+		}
+		contents := schemaHeader + "\n" + strings.Join(statements, "\n\n") + "\n"
+		if err := os.WriteFile(schemaSnapshot, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	contents, err := os.ReadFile(schemaSnapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, statement := range strings.Split(strings.TrimPrefix(string(contents), schemaHeader+"\n"), ";\n\n") {
+		statement = strings.TrimSuffix(strings.TrimSpace(statement), ";") + ";"
+		// Without FTS5 the migrations leave the search index out
+		if !fts5 && strings.Contains(statement, "transactions_fts") {
+			continue
+		}
+		want = append(want, statement)
+	}
+	if strings.Join(statements, "\n\n") != strings.Join(want, "\n\n") {
+		t.Errorf("%s is out of date; run make schema", schemaSnapshot)
+	}
+}
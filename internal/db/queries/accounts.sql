@@ -0,0 +1,78 @@
+-- name: CreateAccount :one
+INSERT INTO accounts (
+    name, type, currency, opening_balance, opening_date, is_active, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAccount :one
+SELECT * FROM accounts
+WHERE id = ?;
+
+-- name: ListAccounts :many
+SELECT * FROM accounts
+WHERE created_by = ?
+ORDER BY name COLLATE NOCASE ASC;
+
+-- name: UpdateAccount :one
+UPDATE accounts
+SET
+    name = ?,
+    type = ?,
+    currency = ?,
+    opening_balance = ?,
+    opening_date = ?,
+    is_active = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteAccount :exec
+DELETE FROM accounts
+WHERE id = ?;
+
+-- name: CountAccountUses :one
+SELECT
+    (SELECT COUNT(*) FROM transactions WHERE transactions.account_id = sqlc.arg('account_id'))
+    + (SELECT COUNT(*) FROM transfers WHERE from_account_id = sqlc.arg('account_id') OR to_account_id = sqlc.arg('account_id')) AS count;
+
+-- name: CreateTransfer :one
+INSERT INTO transfers (
+    from_account_id, to_account_id, amount, to_amount, transfer_date, description, reference, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetTransfer :one
+SELECT * FROM transfers
+WHERE id = ?;
+
+-- name: ListTransfers :many
+SELECT * FROM transfers
+WHERE created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('account_id') = '' OR from_account_id = sqlc.arg('account_id') OR to_account_id = sqlc.arg('account_id'))
+    AND (sqlc.arg('to_date') = '' OR transfer_date < date(sqlc.arg('to_date'), '+1 day'))
+ORDER BY transfer_date DESC, created_at DESC;
+
+-- name: DeleteTransfer :execrows
+DELETE FROM transfers
+WHERE id = ?;
+
+-- name: GetAccountActivity :many
+SELECT
+    t.account_id,
+    t.type,
+    COALESCE(t.currency, 'USD') as currency,
+    t.transaction_date,
+    t.exchange_rate,
+    CAST(COALESCE(SUM(t.net_amount), 0) AS INTEGER) as total_amount
+FROM transactions t
+JOIN accounts a ON a.id = t.account_id
+WHERE t.deleted_at IS NULL
+    AND t.created_by = sqlc.arg('created_by')
+    AND COALESCE(t.payment_status, '') != 'cancelled'
+    AND t.transaction_date >= date(a.opening_date)
+    AND t.transaction_date < date(sqlc.arg('as_of'), '+1 day')
+GROUP BY t.account_id, t.type, COALESCE(t.currency, 'USD'), t.transaction_date, t.exchange_rate;
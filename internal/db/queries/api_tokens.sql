@@ -0,0 +1,25 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (
+    user_id, name, token_hash, token_prefix
+) VALUES (
+    ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: ListAPITokens :many
+SELECT * FROM api_tokens
+WHERE user_id = ?
+ORDER BY created_at DESC;
+
+-- name: GetAPITokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = ?;
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = ? AND user_id = ?;
@@ -0,0 +1,23 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (
+    transaction_id, file_name, mime_type, size_bytes, sha256
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments
+WHERE id = ?;
+
+-- name: ListAttachmentsByTransaction :many
+SELECT * FROM attachments
+WHERE transaction_id = ?
+ORDER BY created_at ASC, file_name ASC;
+
+-- name: ListAttachmentHashes :many
+SELECT DISTINCT sha256 FROM attachments;
+
+-- name: DeleteAttachment :exec
+DELETE FROM attachments
+WHERE id = ?;
@@ -0,0 +1,38 @@
+-- name: CreateBudget :one
+INSERT INTO budgets (
+    category_id, period, amount, currency, rollover, alert_threshold, start_date, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetBudget :one
+SELECT * FROM budgets
+WHERE id = ?;
+
+-- name: ListBudgets :many
+SELECT * FROM budgets
+WHERE created_by = ?
+ORDER BY created_at ASC;
+
+-- name: UpdateBudget :one
+UPDATE budgets
+SET
+    category_id = ?,
+    period = ?,
+    amount = ?,
+    currency = ?,
+    rollover = ?,
+    alert_threshold = ?,
+    start_date = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteBudget :exec
+DELETE FROM budgets
+WHERE id = ?;
+
+-- name: DeleteBudgetsByUser :exec
+DELETE FROM budgets
+WHERE created_by = ?;
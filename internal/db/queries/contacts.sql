@@ -0,0 +1,49 @@
+-- name: CreateContact :one
+INSERT INTO contacts (
+    name, type, email, phone, address, tax_id,
+    default_category_id, default_payment_method_id, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?,
+    ?, ?, ?
+) RETURNING *;
+
+-- name: GetContact :one
+SELECT * FROM contacts
+WHERE id = ?;
+
+-- name: ListContacts :many
+SELECT * FROM contacts
+WHERE created_by = ?
+ORDER BY name COLLATE NOCASE ASC;
+
+-- name: UpdateContact :one
+UPDATE contacts
+SET
+    name = ?,
+    type = ?,
+    email = ?,
+    phone = ?,
+    address = ?,
+    tax_id = ?,
+    default_category_id = ?,
+    default_payment_method_id = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: SetContactType :exec
+UPDATE contacts
+SET type = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteContact :exec
+DELETE FROM contacts
+WHERE id = ?;
+
+-- name: DeleteContactsByUser :exec
+DELETE FROM contacts
+WHERE created_by = ?;
+
+-- name: CountTransactionsByContact :one
+SELECT COUNT(*) as count FROM transactions
+WHERE contact_id = ? AND deleted_at IS NULL;
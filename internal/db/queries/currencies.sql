@@ -0,0 +1,7 @@
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code ASC;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = ?;
@@ -0,0 +1,28 @@
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (
+    from_currency, to_currency, rate, rate_date, source
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+ON CONFLICT (from_currency, to_currency, rate_date) DO UPDATE SET
+    rate = excluded.rate,
+    source = excluded.source,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListExchangeRates :many
+SELECT * FROM exchange_rates
+WHERE (sqlc.arg('currency') = '' OR from_currency = sqlc.arg('currency') OR to_currency = sqlc.arg('currency'))
+ORDER BY rate_date DESC, from_currency ASC, to_currency ASC;
+
+-- name: GetEffectiveExchangeRate :one
+SELECT * FROM exchange_rates
+WHERE from_currency = ?
+    AND to_currency = ?
+    AND rate_date <= ?
+ORDER BY rate_date DESC
+LIMIT 1;
+
+-- name: DeleteExchangeRate :exec
+DELETE FROM exchange_rates
+WHERE id = ?;
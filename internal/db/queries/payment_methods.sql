@@ -9,6 +9,11 @@ INSERT INTO payment_methods (
 SELECT * FROM payment_methods
 WHERE id = ?;
 
+-- name: GetPaymentMethodByName :one
+SELECT * FROM payment_methods
+WHERE name = ? AND is_active = TRUE
+LIMIT 1;
+
 -- name: ListPaymentMethods :many
 SELECT * FROM payment_methods
 ORDER BY name ASC;
@@ -0,0 +1,28 @@
+-- name: CreatePayment :one
+INSERT INTO payments (
+    transaction_id, amount, payment_date, payment_method_id, reference, notes, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetPayment :one
+SELECT * FROM payments
+WHERE id = ?;
+
+-- name: ListPaymentsByTransaction :many
+SELECT * FROM payments
+WHERE transaction_id = ?
+ORDER BY payment_date ASC, created_at ASC;
+
+-- name: GetPaymentTotals :one
+SELECT
+    CAST(COUNT(*) AS INTEGER) AS payment_count,
+    CAST(COALESCE(SUM(CASE WHEN voided_at IS NULL THEN amount ELSE 0 END), 0) AS INTEGER) AS total_paid
+FROM payments
+WHERE transaction_id = ?;
+
+-- name: VoidPayment :execrows
+UPDATE payments
+SET voided_at = CURRENT_TIMESTAMP
+WHERE id = ? AND voided_at IS NULL;
@@ -0,0 +1,109 @@
+-- name: CreateReconciliation :one
+INSERT INTO reconciliations (
+    account_id, statement_start_date, statement_end_date, ending_balance, created_by
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetReconciliation :one
+SELECT * FROM reconciliations
+WHERE id = ?;
+
+-- name: GetOpenReconciliation :one
+SELECT * FROM reconciliations
+WHERE account_id = ? AND status = 'open';
+
+-- name: ListReconciliations :many
+SELECT * FROM reconciliations
+WHERE account_id = ?
+ORDER BY statement_end_date DESC, created_at DESC;
+
+-- name: UpdateReconciliation :one
+UPDATE reconciliations
+SET
+    statement_start_date = ?,
+    statement_end_date = ?,
+    ending_balance = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: CompleteReconciliation :exec
+UPDATE reconciliations
+SET
+    status = 'completed',
+    completed_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteReconciliation :exec
+DELETE FROM reconciliations
+WHERE id = ?;
+
+-- name: CreateStatementLine :one
+INSERT INTO statement_lines (
+    reconciliation_id, line_date, description, amount, reference
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetStatementLine :one
+SELECT * FROM statement_lines
+WHERE id = ?;
+
+-- name: ListStatementLines :many
+SELECT * FROM statement_lines
+WHERE reconciliation_id = ?
+ORDER BY line_date ASC, rowid ASC;
+
+-- name: GetOpenStatementLineByTransaction :one
+SELECT sl.* FROM statement_lines sl
+JOIN reconciliations r ON r.id = sl.reconciliation_id
+WHERE sl.transaction_id = ? AND r.status = 'open';
+
+-- name: SetStatementLineMatch :exec
+UPDATE statement_lines
+SET
+    transaction_id = ?,
+    match_type = ?
+WHERE id = ?;
+
+-- name: DeleteStatementLine :exec
+DELETE FROM statement_lines
+WHERE id = ?;
+
+-- name: SetTransactionClearedStatus :exec
+UPDATE transactions
+SET cleared_status = ?
+WHERE id = ?;
+
+-- name: ReconcileClearedTransactions :execrows
+UPDATE transactions
+SET cleared_status = 'reconciled'
+WHERE account_id = ? AND cleared_status = 'cleared' AND deleted_at IS NULL;
+
+-- name: ListUnreconciledTransactions :many
+SELECT * FROM transactions
+WHERE deleted_at IS NULL
+    AND account_id = sqlc.arg('account_id')
+    AND cleared_status != 'reconciled'
+    AND COALESCE(payment_status, '') != 'cancelled'
+    AND transaction_date >= date(sqlc.arg('from_date'))
+    AND transaction_date < date(sqlc.arg('to_date'), '+1 day')
+ORDER BY transaction_date ASC, created_at ASC;
+
+-- name: GetClearedActivity :many
+SELECT
+    t.type,
+    COALESCE(t.currency, 'USD') as currency,
+    t.transaction_date,
+    t.exchange_rate,
+    CAST(COALESCE(SUM(t.net_amount), 0) AS INTEGER) as total_amount
+FROM transactions t
+JOIN accounts a ON a.id = t.account_id
+WHERE t.deleted_at IS NULL
+    AND t.account_id = sqlc.arg('account_id')
+    AND t.cleared_status != 'uncleared'
+    AND COALESCE(t.payment_status, '') != 'cancelled'
+    AND t.transaction_date >= date(a.opening_date)
+GROUP BY t.type, COALESCE(t.currency, 'USD'), t.transaction_date, t.exchange_rate;
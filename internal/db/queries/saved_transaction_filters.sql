@@ -0,0 +1,48 @@
+-- name: CreateSavedFilter :one
+INSERT INTO saved_transaction_filters (
+    name, filter_config, is_default, created_by
+) VALUES (
+    ?, ?, ?, ?
+) RETURNING *;
+
+-- name: GetSavedFilter :one
+SELECT * FROM saved_transaction_filters
+WHERE id = ?;
+
+-- name: GetSavedFilterByName :one
+SELECT * FROM saved_transaction_filters
+WHERE created_by = ? AND name = ?;
+
+-- name: GetDefaultSavedFilter :one
+SELECT * FROM saved_transaction_filters
+WHERE created_by = ? AND is_default = TRUE
+ORDER BY updated_at DESC
+LIMIT 1;
+
+-- name: ListSavedFilters :many
+SELECT * FROM saved_transaction_filters
+WHERE created_by = ?
+ORDER BY is_default DESC, name ASC;
+
+-- name: RenameSavedFilter :execrows
+UPDATE saved_transaction_filters
+SET name = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: SetDefaultSavedFilter :execrows
+UPDATE saved_transaction_filters
+SET is_default = TRUE, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ClearDefaultSavedFilters :exec
+UPDATE saved_transaction_filters
+SET is_default = FALSE, updated_at = CURRENT_TIMESTAMP
+WHERE created_by = ? AND is_default = TRUE;
+
+-- name: DeleteSavedFilter :exec
+DELETE FROM saved_transaction_filters
+WHERE id = ?;
+
+-- name: DeleteSavedFiltersByUser :exec
+DELETE FROM saved_transaction_filters
+WHERE created_by = ?;
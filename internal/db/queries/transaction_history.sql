@@ -0,0 +1,20 @@
+-- name: CreateTransactionHistory :exec
+INSERT INTO transaction_history (
+    transaction_id, version, action, changes, snapshot, changed_by
+) VALUES (
+    sqlc.arg('transaction_id'),
+    (SELECT COALESCE(MAX(version), 0) + 1 FROM transaction_history WHERE transaction_id = sqlc.arg('transaction_id')),
+    sqlc.arg('action'),
+    sqlc.arg('changes'),
+    sqlc.arg('snapshot'),
+    sqlc.arg('changed_by')
+);
+
+-- name: ListTransactionHistory :many
+SELECT * FROM transaction_history
+WHERE transaction_id = ?
+ORDER BY version ASC;
+
+-- name: GetTransactionVersion :one
+SELECT * FROM transaction_history
+WHERE transaction_id = ? AND version = ?;
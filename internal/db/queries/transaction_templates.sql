@@ -0,0 +1,65 @@
+-- name: CreateTemplate :one
+INSERT INTO transaction_templates (
+    name, type, description, amount, category, tags, customer_vendor,
+    payment_method, tax_amount, discount_amount, currency, notes,
+    is_favorite, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?,
+    ?, ?, ?, ?, ?,
+    ?, ?
+) RETURNING *;
+
+-- name: GetTemplate :one
+SELECT * FROM transaction_templates
+WHERE id = ?;
+
+-- name: ListTemplates :many
+SELECT * FROM transaction_templates
+WHERE created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('favorites_only') = FALSE OR is_favorite = TRUE)
+ORDER BY is_favorite DESC, usage_count DESC, name ASC;
+
+-- name: ListMostUsedTemplates :many
+SELECT * FROM transaction_templates
+WHERE created_by = sqlc.arg('created_by')
+    AND usage_count > 0
+ORDER BY usage_count DESC, updated_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateTemplate :one
+UPDATE transaction_templates
+SET
+    name = ?,
+    type = ?,
+    description = ?,
+    amount = ?,
+    category = ?,
+    tags = ?,
+    customer_vendor = ?,
+    payment_method = ?,
+    tax_amount = ?,
+    discount_amount = ?,
+    currency = ?,
+    notes = ?,
+    is_favorite = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: SetTemplateFavorite :exec
+UPDATE transaction_templates
+SET is_favorite = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: IncrementTemplateUsage :exec
+UPDATE transaction_templates
+SET usage_count = COALESCE(usage_count, 0) + 1
+WHERE id = ?;
+
+-- name: DeleteTemplate :exec
+DELETE FROM transaction_templates
+WHERE id = ?;
+
+-- name: DeleteTemplatesByUser :exec
+DELETE FROM transaction_templates
+WHERE created_by = ?;
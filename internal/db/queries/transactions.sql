@@ -5,35 +5,47 @@ INSERT INTO transactions (
     payment_status, reference_number, invoice_number,
     notes, attachments, tax_amount, discount_amount, due_amount,
     currency, exchange_rate, is_recurring, recurring_frequency,
-    recurring_end_date, parent_transaction_id, created_by
+    recurring_end_date, parent_transaction_id, created_by, due_date,
+    contact_id, account_id
 ) VALUES (
     ?, ?, ?, ?,
     ?, ?, ?, ?,
     ?, ?, ?,
     ?, ?, ?, ?, ?,
     ?, ?, ?, ?,
-    ?, ?, ?
+    ?, ?, ?, ?,
+    ?, ?
 ) RETURNING *;
 
 -- name: GetTransaction :one
 SELECT * FROM transactions
 WHERE id = ? AND deleted_at IS NULL;
 
+-- name: GetTransactionWithDeleted :one
+SELECT * FROM transactions
+WHERE id = ?;
+
+-- name: GetRecurringOccurrence :one
+SELECT * FROM transactions
+WHERE parent_transaction_id = ? AND transaction_date = ?;
+
 -- name: ListTransactions :many
 SELECT * FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = sqlc.arg('created_by')
     AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
-    AND (sqlc.arg('to_date') = '' OR transaction_date <= sqlc.arg('to_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
     AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter') OR sqlc.arg('type_filter') LIKE '%' || type || '%')
     AND (sqlc.arg('category_filter') = '' OR category_id = sqlc.arg('category_filter') OR sqlc.arg('category_filter') LIKE '%' || category_id || '%')
     AND (sqlc.arg('payment_status_filter') = '' OR payment_status = sqlc.arg('payment_status_filter') OR sqlc.arg('payment_status_filter') LIKE '%' || payment_status || '%')
     AND (sqlc.arg('payment_method_filter') = '' OR payment_method_id = sqlc.arg('payment_method_filter') OR sqlc.arg('payment_method_filter') LIKE '%' || payment_method_id || '%')
+    AND (sqlc.arg('contact_filter') = '' OR contact_id = sqlc.arg('contact_filter'))
+    AND (sqlc.arg('account_filter') = '' OR account_id = sqlc.arg('account_filter'))
     AND (sqlc.arg('customer_vendor_search') = '' OR customer_vendor LIKE '%' || sqlc.arg('customer_vendor_search') || '%')
     AND (sqlc.arg('description_search') = '' OR description LIKE '%' || sqlc.arg('description_search') || '%')
-    AND (sqlc.arg('min_due_amount') = 0 OR due_amount >= sqlc.arg('min_due_amount'))
-    AND (sqlc.arg('max_due_amount') = 0 OR due_amount <= sqlc.arg('max_due_amount'))
-ORDER BY transaction_date DESC, created_at DESC
+    AND (sqlc.arg('min_due_amount') = 0 OR due_amount >= ROUND(sqlc.arg('min_due_amount') * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
+    AND (sqlc.arg('max_due_amount') = 0 OR due_amount <= ROUND(sqlc.arg('max_due_amount') * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
+ORDER BY transaction_date DESC, created_at DESC, id DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: UpdateTransaction :one
@@ -60,106 +72,232 @@ SET
     is_recurring = ?,
     recurring_frequency = ?,
     recurring_end_date = ?,
+    due_date = ?,
+    contact_id = ?,
+    account_id = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ? AND deleted_at IS NULL
 RETURNING *;
 
+-- name: SetTransactionContact :exec
+UPDATE transactions
+SET
+    contact_id = ?,
+    customer_vendor = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: SetTransactionSettlement :exec
+UPDATE transactions
+SET
+    due_amount = ?,
+    payment_status = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
 -- name: DeleteTransaction :exec
 UPDATE transactions
 SET deleted_at = CURRENT_TIMESTAMP
 WHERE id = ? AND deleted_at IS NULL;
 
--- name: GetTransactionStats :one
+-- name: ListDeletedTransactions :many
+SELECT * FROM transactions
+WHERE deleted_at IS NOT NULL
+    AND created_by = sqlc.arg('created_by')
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: RestoreTransaction :execrows
+UPDATE transactions
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: ListPurgeableTransactions :many
+SELECT t.id FROM transactions t
+WHERE t.deleted_at IS NOT NULL
+    AND t.created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('before') = '' OR t.deleted_at < datetime(sqlc.arg('before')))
+    AND NOT (
+        t.parent_transaction_id IS NOT NULL
+        AND EXISTS (
+            SELECT 1 FROM transactions p
+            WHERE p.id = t.parent_transaction_id AND p.deleted_at IS NULL
+        )
+        AND t.transaction_date = (
+            SELECT MAX(o.transaction_date) FROM transactions o
+            WHERE o.parent_transaction_id = t.parent_transaction_id
+        )
+    );
+
+-- name: DetachChildTransactions :exec
+UPDATE transactions
+SET parent_transaction_id = NULL
+WHERE parent_transaction_id = ?;
+
+-- name: PurgeTransaction :execrows
+DELETE FROM transactions
+WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: GetTransactionStats :many
 SELECT
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
     COUNT(CASE WHEN type IN ('income', 'sale') THEN 1 END) as total_income_count,
     COUNT(CASE WHEN type IN ('expense', 'purchase') THEN 1 END) as total_expense_count,
-    COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END), 0) as total_income,
-    COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END), 0) as total_expenses,
-    COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE -net_amount END), 0) as net_profit,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END), 0) AS INTEGER) as total_income,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END), 0) AS INTEGER) as total_expenses,
     COUNT(*) as total_transactions,
-    COALESCE(AVG(net_amount), 0) as average_transaction,
-    COALESCE(SUM(CASE WHEN type IN ('income', 'sale') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) as pending_income,
-    COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) as pending_expenses
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_net_amount,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('income', 'sale') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) AS INTEGER) as pending_income,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) AS INTEGER) as pending_expenses
 FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = sqlc.arg('created_by')
     AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
-    AND (sqlc.arg('to_date') = '' OR transaction_date <= sqlc.arg('to_date'));
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
+GROUP BY COALESCE(currency, 'USD'), transaction_date, exchange_rate;
 
 -- name: GetTransactionsByCategory :many
 SELECT
     category_id,
     type,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
     COUNT(*) as count,
-    SUM(net_amount) as total_amount
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_amount
+FROM transactions
+WHERE deleted_at IS NULL
+    AND created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
+    AND category_id IS NOT NULL
+GROUP BY category_id, type, COALESCE(currency, 'USD'), transaction_date, exchange_rate
+ORDER BY category_id, type;
+
+-- name: GetProfitAndLoss :many
+SELECT
+    category_id,
+    type,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
+    CAST(COALESCE(SUM(amount - COALESCE(discount_amount, 0)), 0) AS INTEGER) as total_amount,
+    CAST(COALESCE(SUM(tax_amount), 0) AS INTEGER) as total_tax,
+    CAST(COALESCE(SUM(discount_amount), 0) AS INTEGER) as total_discount
 FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = sqlc.arg('created_by')
     AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
-    AND (sqlc.arg('to_date') = '' OR transaction_date <= sqlc.arg('to_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
+GROUP BY category_id, type, COALESCE(currency, 'USD'), transaction_date, exchange_rate;
+
+-- name: GetCategorySpending :many
+SELECT
+    category_id,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
+    CAST(COALESCE(SUM(amount - COALESCE(discount_amount, 0) + COALESCE(tax_amount, 0)), 0) AS INTEGER) as total_amount
+FROM transactions
+WHERE deleted_at IS NULL
+    AND created_by = sqlc.arg('created_by')
     AND category_id IS NOT NULL
-GROUP BY category_id, type
-ORDER BY total_amount DESC;
+    AND COALESCE(payment_status, '') != 'cancelled'
+    AND transaction_date >= date(sqlc.arg('from_date'))
+    AND transaction_date < date(sqlc.arg('to_date'), '+1 day')
+GROUP BY category_id, COALESCE(currency, 'USD'), transaction_date, exchange_rate;
 
 -- name: GetTopCustomersVendors :many
 SELECT
     customer_vendor,
     type,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
     COUNT(*) as transaction_count,
-    SUM(net_amount) as total_amount
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_amount
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
+    AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter') OR sqlc.arg('type_filter') LIKE '%' || type || '%')
     AND customer_vendor IS NOT NULL
     AND customer_vendor != ''
-    AND (? = '' OR transaction_date >= ?)
-    AND (? = '' OR transaction_date <= ?)
-GROUP BY customer_vendor, type
-ORDER BY total_amount DESC
-LIMIT ?;
+GROUP BY customer_vendor, type, COALESCE(currency, 'USD'), transaction_date, exchange_rate
+ORDER BY customer_vendor, type;
 
 -- name: GetMonthlyTrend :many
 SELECT
-    strftime('%Y-%m', transaction_date) as month,
+    transaction_date,
     type,
+    COALESCE(currency, 'USD') as currency,
+    exchange_rate,
     COUNT(*) as count,
-    SUM(net_amount) as total_amount
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_amount
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
-    AND (? = '' OR transaction_date >= ?)
-    AND (? = '' OR transaction_date <= ?)
-GROUP BY month, type
-ORDER BY month DESC;
+    AND created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
+    AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter') OR sqlc.arg('type_filter') LIKE '%' || type || '%')
+GROUP BY transaction_date, type, COALESCE(currency, 'USD'), exchange_rate
+ORDER BY transaction_date;
 
 -- name: GetDailyTransactionSummary :many
 SELECT
     transaction_date,
-    SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END) as daily_income,
-    SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END) as daily_expense,
-    SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE -net_amount END) as daily_profit,
+    COALESCE(currency, 'USD') as currency,
+    exchange_rate,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END), 0) AS INTEGER) as daily_income,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END), 0) AS INTEGER) as daily_expense,
     COUNT(*) as transaction_count
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
-    AND (? = '' OR transaction_date >= ?)
-    AND (? = '' OR transaction_date <= ?)
-GROUP BY transaction_date
-ORDER BY transaction_date DESC;
+    AND created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
+    AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter') OR sqlc.arg('type_filter') LIKE '%' || type || '%')
+GROUP BY transaction_date, COALESCE(currency, 'USD'), exchange_rate
+ORDER BY transaction_date;
+
+-- name: ListOutstandingTransactions :many
+SELECT * FROM transactions
+WHERE deleted_at IS NULL
+    AND created_by = sqlc.arg('created_by')
+    AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter') OR sqlc.arg('type_filter') LIKE '%' || type || '%')
+    AND transaction_date < date(sqlc.arg('as_of'), '+1 day')
+    AND COALESCE(payment_status, 'completed') != 'cancelled'
+    AND (COALESCE(due_amount, 0) > 0 OR payment_status = 'pending')
+ORDER BY customer_vendor, COALESCE(due_date, transaction_date), id;
 
 -- name: SearchTransactions :many
+SELECT t.*,
+    CAST(snippet(transactions_fts, -1, char(2), char(3), '…', 12) AS TEXT) AS snippet,
+    CAST(bm25(transactions_fts, 4.0, 3.0, 2.0, 2.0, 1.0) AS REAL) AS score
+FROM transactions_fts
+JOIN transactions t ON t.rowid = transactions_fts.rowid
+WHERE transactions_fts MATCH CAST(sqlc.arg('query') AS TEXT)
+    AND t.deleted_at IS NULL
+    AND t.created_by = sqlc.arg('created_by')
+ORDER BY score, t.transaction_date DESC, t.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: SearchTransactionsLike :many
 SELECT * FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = sqlc.arg('created_by')
     AND (
-        description LIKE '%' || ? || '%'
-        OR customer_vendor LIKE '%' || ? || '%'
-        OR reference_number LIKE '%' || ? || '%'
-        OR invoice_number LIKE '%' || ? || '%'
-        OR notes LIKE '%' || ? || '%'
+        description LIKE '%' || sqlc.arg('search') || '%'
+        OR customer_vendor LIKE '%' || sqlc.arg('search') || '%'
+        OR reference_number LIKE '%' || sqlc.arg('search') || '%'
+        OR invoice_number LIKE '%' || sqlc.arg('search') || '%'
+        OR notes LIKE '%' || sqlc.arg('search') || '%'
     )
 ORDER BY transaction_date DESC, created_at DESC
-LIMIT ? OFFSET ?;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: GetRecentTransactions :many
 SELECT * FROM transactions
@@ -173,38 +311,83 @@ SELECT COUNT(*) as count FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = sqlc.arg('created_by')
     AND (sqlc.arg('from_date') = '' OR transaction_date >= sqlc.arg('from_date'))
-    AND (sqlc.arg('to_date') = '' OR transaction_date <= sqlc.arg('to_date'))
+    AND (sqlc.arg('to_date') = '' OR transaction_date < date(sqlc.arg('to_date'), '+1 day'))
     AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter') OR sqlc.arg('type_filter') LIKE '%' || type || '%')
     AND (sqlc.arg('category_filter') = '' OR category_id = sqlc.arg('category_filter') OR sqlc.arg('category_filter') LIKE '%' || category_id || '%')
     AND (sqlc.arg('payment_status_filter') = '' OR payment_status = sqlc.arg('payment_status_filter') OR sqlc.arg('payment_status_filter') LIKE '%' || payment_status || '%')
     AND (sqlc.arg('payment_method_filter') = '' OR payment_method_id = sqlc.arg('payment_method_filter') OR sqlc.arg('payment_method_filter') LIKE '%' || payment_method_id || '%')
+    AND (sqlc.arg('contact_filter') = '' OR contact_id = sqlc.arg('contact_filter'))
+    AND (sqlc.arg('account_filter') = '' OR account_id = sqlc.arg('account_filter'))
     AND (sqlc.arg('customer_vendor_search') = '' OR customer_vendor LIKE '%' || sqlc.arg('customer_vendor_search') || '%')
     AND (sqlc.arg('description_search') = '' OR description LIKE '%' || sqlc.arg('description_search') || '%')
-    AND (sqlc.arg('min_due_amount') = 0 OR due_amount >= sqlc.arg('min_due_amount'))
-    AND (sqlc.arg('max_due_amount') = 0 OR due_amount <= sqlc.arg('max_due_amount'));
+    AND (sqlc.arg('min_due_amount') = 0 OR due_amount >= ROUND(sqlc.arg('min_due_amount') * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
+    AND (sqlc.arg('max_due_amount') = 0 OR due_amount <= ROUND(sqlc.arg('max_due_amount') * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)));
 
 -- name: GetDescriptionSuggestions :many
 SELECT DISTINCT description, COUNT(*) as frequency
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = sqlc.arg('created_by')
     AND description IS NOT NULL
     AND description != ''
-    AND (? = '' OR type = ?)
-    AND (? = '' OR description LIKE '%' || ? || '%')
+    AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter'))
+    AND (sqlc.arg('search') = '' OR description LIKE '%' || sqlc.arg('search') || '%')
 GROUP BY description
 ORDER BY frequency DESC, description ASC
-LIMIT ?;
+LIMIT sqlc.arg('limit');
 
 -- name: GetCustomerVendorSuggestions :many
 SELECT DISTINCT customer_vendor, COUNT(*) as frequency
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = sqlc.arg('created_by')
     AND customer_vendor IS NOT NULL
     AND customer_vendor != ''
-    AND (? = '' OR type = ?)
-    AND (? = '' OR customer_vendor LIKE '%' || ? || '%')
+    AND (sqlc.arg('type_filter') = '' OR type = sqlc.arg('type_filter'))
+    AND (sqlc.arg('search') = '' OR customer_vendor LIKE '%' || sqlc.arg('search') || '%')
 GROUP BY customer_vendor
 ORDER BY frequency DESC, customer_vendor ASC
-LIMIT ?;
\ No newline at end of file
+LIMIT sqlc.arg('limit');
+
+-- name: ListTransactionsByContact :many
+SELECT * FROM transactions
+WHERE contact_id = ?
+ORDER BY transaction_date ASC, created_at ASC;
+
+-- name: ListRecurringTransactions :many
+SELECT * FROM transactions
+WHERE deleted_at IS NULL
+    AND is_recurring = TRUE
+    AND recurring_frequency IS NOT NULL
+    AND parent_transaction_id IS NULL
+ORDER BY transaction_date ASC;
+
+-- name: GetLatestRecurringOccurrenceDate :one
+SELECT transaction_date FROM transactions
+WHERE parent_transaction_id = ?
+ORDER BY transaction_date DESC
+LIMIT 1;
+
+-- name: CreateRecurringOccurrence :execrows
+INSERT INTO transactions (
+    type, description, amount, transaction_date,
+    category_id, tags, customer_vendor, payment_method_id,
+    payment_status, reference_number, invoice_number,
+    notes, attachments, tax_amount, discount_amount, due_amount,
+    currency, exchange_rate, is_recurring, parent_transaction_id, created_by,
+    contact_id, account_id
+) VALUES (
+    ?, ?, ?, ?,
+    ?, ?, ?, ?,
+    ?, ?, ?,
+    ?, ?, ?, ?, ?,
+    ?, ?, FALSE, ?, ?,
+    ?, ?
+)
+ON CONFLICT DO NOTHING;
+
+-- name: CountTransactionsByReference :one
+SELECT COUNT(*) as count FROM transactions
+WHERE created_by = sqlc.arg('created_by')
+    AND COALESCE(account_id, '') = sqlc.arg('account_id')
+    AND reference_number = sqlc.arg('reference_number');
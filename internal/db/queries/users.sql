@@ -0,0 +1,44 @@
+-- name: GetUserPreferences :one
+SELECT preferences FROM users
+WHERE id = ?;
+
+-- name: UpdateUserPreferences :execrows
+UPDATE users
+SET preferences = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: CreateUser :one
+INSERT INTO users (
+    id, name, email
+) VALUES (
+    lower(hex(randomblob(16))), ?, ?
+) RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = ?;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY CASE WHEN id = 'default' THEN 0 ELSE 1 END, name ASC;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+    name = ?,
+    email = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = ?;
+
+-- name: CountUserRecords :one
+SELECT
+    (SELECT COUNT(*) FROM transactions t WHERE t.created_by = sqlc.arg('created_by')) AS transactions,
+    (SELECT COUNT(*) FROM payments p WHERE p.created_by = sqlc.arg('created_by')) AS payments,
+    (SELECT COUNT(*) FROM accounts a WHERE a.created_by = sqlc.arg('created_by')) AS accounts,
+    (SELECT COUNT(*) FROM transfers tr WHERE tr.created_by = sqlc.arg('created_by')) AS transfers,
+    (SELECT COUNT(*) FROM reconciliations r WHERE r.created_by = sqlc.arg('created_by')) AS reconciliations;
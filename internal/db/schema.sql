@@ -0,0 +1,336 @@
+-- Schema of a database migrated to the latest version, for sqlc, which
+-- cannot run the Go migrations. Regenerate it with "make schema" after
+-- adding a migration; TestSchemaSnapshot fails while it is out of date.
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY DEFAULT 'default',
+    name TEXT NOT NULL,
+    email TEXT,
+    preferences TEXT, -- JSON configuration
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE categories (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'both')),
+    color TEXT,
+    icon TEXT,
+    parent_id TEXT REFERENCES categories(id),
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE payment_methods (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    description TEXT,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE saved_transaction_filters (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    filter_config TEXT NOT NULL,
+    is_default BOOLEAN DEFAULT FALSE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE currencies (
+    code TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    exponent INTEGER NOT NULL DEFAULT 2 CHECK (exponent BETWEEN 0 AND 4),
+    scale INTEGER GENERATED ALWAYS AS (
+        CASE exponent WHEN 0 THEN 1 WHEN 1 THEN 10 WHEN 2 THEN 100 WHEN 3 THEN 1000 ELSE 10000 END
+    ) STORED
+);
+
+CREATE TABLE "transactions" (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    transaction_date DATE NOT NULL,
+    category_id TEXT REFERENCES categories(id),
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    payment_status TEXT DEFAULT 'completed' CHECK (payment_status IN ('pending', 'completed', 'partial', 'cancelled')),
+    reference_number TEXT,
+    invoice_number TEXT,
+    notes TEXT,
+    attachments TEXT,
+    tax_amount INTEGER DEFAULT 0,
+    discount_amount INTEGER DEFAULT 0,
+    due_amount INTEGER DEFAULT 0,
+    net_amount INTEGER GENERATED ALWAYS AS (amount - discount_amount + tax_amount - due_amount) STORED,
+    currency TEXT DEFAULT 'USD',
+    exchange_rate REAL DEFAULT 1.0,
+    is_recurring BOOLEAN DEFAULT FALSE,
+    recurring_frequency TEXT CHECK (recurring_frequency IN ('daily', 'weekly', 'monthly', 'quarterly', 'yearly')),
+    recurring_end_date DATE,
+    parent_transaction_id TEXT REFERENCES transactions(id),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP, due_date DATE, contact_id TEXT REFERENCES contacts(id) ON DELETE SET NULL, account_id TEXT REFERENCES accounts(id), cleared_status TEXT NOT NULL DEFAULT 'uncleared' CHECK (cleared_status IN ('uncleared', 'cleared', 'reconciled')),
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX idx_transactions_amount ON transactions(amount);
+
+CREATE INDEX idx_transactions_category ON transactions(category_id);
+
+CREATE INDEX idx_transactions_created_by ON transactions(created_by);
+
+CREATE INDEX idx_transactions_customer_vendor ON transactions(customer_vendor);
+
+CREATE INDEX idx_transactions_date ON transactions(transaction_date);
+
+CREATE INDEX idx_transactions_deleted_at ON transactions(deleted_at);
+
+CREATE INDEX idx_transactions_due_amount ON transactions(due_amount);
+
+CREATE INDEX idx_transactions_payment_method ON transactions(payment_method_id);
+
+CREATE INDEX idx_transactions_payment_status ON transactions(payment_status);
+
+CREATE UNIQUE INDEX idx_transactions_recurring_occurrence
+    ON transactions(parent_transaction_id, transaction_date)
+    WHERE parent_transaction_id IS NOT NULL;
+
+CREATE INDEX idx_transactions_type ON transactions(type);
+
+CREATE TABLE "transaction_templates" (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT,
+    amount INTEGER,
+    category TEXT,
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method TEXT,
+    tax_amount INTEGER DEFAULT 0,
+    discount_amount INTEGER DEFAULT 0,
+    currency TEXT DEFAULT 'USD',
+    notes TEXT,
+    usage_count INTEGER DEFAULT 0,
+    is_favorite BOOLEAN DEFAULT FALSE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE exchange_rates (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    from_currency TEXT NOT NULL,
+    to_currency TEXT NOT NULL,
+    rate REAL NOT NULL CHECK (rate > 0),
+    rate_date DATE NOT NULL,
+    source TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (from_currency, to_currency, rate_date)
+);
+
+CREATE INDEX idx_exchange_rates_pair_date ON exchange_rates(from_currency, to_currency, rate_date);
+
+CREATE TABLE attachments (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    transaction_id TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    sha256 TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_attachments_transaction ON attachments(transaction_id);
+
+CREATE INDEX idx_attachments_sha256 ON attachments(sha256);
+
+CREATE INDEX idx_transactions_reference ON transactions(created_by, reference_number);
+
+CREATE VIRTUAL TABLE transactions_fts USING fts5(
+    description,
+    customer_vendor,
+    reference_number,
+    invoice_number,
+    notes,
+    content = 'transactions',
+    content_rowid = 'rowid',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER transactions_fts_insert AFTER INSERT ON transactions BEGIN
+    INSERT INTO transactions_fts (rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES (new.rowid, new.description, new.customer_vendor, new.reference_number, new.invoice_number, new.notes);
+END;
+
+CREATE TRIGGER transactions_fts_delete AFTER DELETE ON transactions BEGIN
+    INSERT INTO transactions_fts (transactions_fts, rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES ('delete', old.rowid, old.description, old.customer_vendor, old.reference_number, old.invoice_number, old.notes);
+END;
+
+CREATE TRIGGER transactions_fts_update AFTER UPDATE OF description, customer_vendor, reference_number, invoice_number, notes ON transactions BEGIN
+    INSERT INTO transactions_fts (transactions_fts, rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES ('delete', old.rowid, old.description, old.customer_vendor, old.reference_number, old.invoice_number, old.notes);
+    INSERT INTO transactions_fts (rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES (new.rowid, new.description, new.customer_vendor, new.reference_number, new.invoice_number, new.notes);
+END;
+
+CREATE TABLE payments (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    transaction_id TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    payment_date DATE NOT NULL,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    reference TEXT,
+    notes TEXT,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    voided_at TIMESTAMP,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_payments_transaction ON payments(transaction_id);
+
+CREATE TABLE budgets (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    category_id TEXT NOT NULL,
+    period TEXT NOT NULL CHECK (period IN ('month', 'quarter', 'year')),
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    currency TEXT NOT NULL,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    alert_threshold REAL NOT NULL DEFAULT 1.0 CHECK (alert_threshold > 0),
+    start_date DATE NOT NULL,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    UNIQUE (created_by, category_id)
+);
+
+CREATE TABLE transaction_history (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    transaction_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'revert', 'purge')),
+    changes TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, version)
+);
+
+CREATE TRIGGER transaction_history_no_delete BEFORE DELETE ON transaction_history BEGIN
+    SELECT RAISE(ABORT, 'transaction history is append-only');
+END;
+
+CREATE TABLE contacts (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'both' CHECK (type IN ('customer', 'vendor', 'both')),
+    email TEXT,
+    phone TEXT,
+    address TEXT,
+    tax_id TEXT,
+    default_category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+    default_payment_method_id TEXT REFERENCES payment_methods(id) ON DELETE SET NULL,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_contacts_created_by ON contacts(created_by, name);
+
+CREATE INDEX idx_transactions_contact_id ON transactions(contact_id);
+
+CREATE TABLE accounts (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL COLLATE NOCASE,
+    type TEXT NOT NULL DEFAULT 'bank' CHECK (type IN ('bank', 'cash', 'card', 'other')),
+    currency TEXT NOT NULL,
+    opening_balance INTEGER NOT NULL DEFAULT 0,
+    opening_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (created_by, name)
+);
+
+CREATE TABLE transfers (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    from_account_id TEXT NOT NULL REFERENCES accounts(id),
+    to_account_id TEXT NOT NULL REFERENCES accounts(id),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    to_amount INTEGER NOT NULL CHECK (to_amount > 0),
+    transfer_date DATE NOT NULL,
+    description TEXT,
+    reference TEXT,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_account_id != to_account_id)
+);
+
+CREATE INDEX idx_transfers_from_account_id ON transfers(from_account_id);
+
+CREATE INDEX idx_transfers_to_account_id ON transfers(to_account_id);
+
+CREATE INDEX idx_transactions_account_id ON transactions(account_id);
+
+CREATE TABLE reconciliations (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    account_id TEXT NOT NULL REFERENCES accounts(id),
+    statement_start_date DATE NOT NULL,
+    statement_end_date DATE NOT NULL,
+    ending_balance INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed')),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    CHECK (statement_start_date <= statement_end_date)
+);
+
+CREATE INDEX idx_reconciliations_account_id ON reconciliations(account_id, statement_end_date);
+
+CREATE TABLE statement_lines (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    reconciliation_id TEXT NOT NULL REFERENCES reconciliations(id) ON DELETE CASCADE,
+    line_date DATE NOT NULL,
+    description TEXT,
+    amount INTEGER NOT NULL,
+    reference TEXT,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    match_type TEXT CHECK (match_type IN ('auto', 'manual')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_statement_lines_reconciliation_id ON statement_lines(reconciliation_id, line_date);
+
+CREATE INDEX idx_statement_lines_transaction_id ON statement_lines(transaction_id);
+
+CREATE TABLE api_tokens (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    token_prefix TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+CREATE TRIGGER transaction_history_no_update BEFORE UPDATE ON transaction_history BEGIN
+    SELECT RAISE(ABORT, 'transaction history is append-only');
+END;
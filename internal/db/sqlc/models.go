@@ -9,6 +9,53 @@ import (
 	"time"
 )
 
+type Account struct {
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	Type           string       `json:"type"`
+	Currency       string       `json:"currency"`
+	OpeningBalance int64        `json:"opening_balance"`
+	OpeningDate    time.Time    `json:"opening_date"`
+	IsActive       bool         `json:"is_active"`
+	CreatedBy      string       `json:"created_by"`
+	CreatedAt      sql.NullTime `json:"created_at"`
+	UpdatedAt      sql.NullTime `json:"updated_at"`
+}
+
+type ApiToken struct {
+	ID          string       `json:"id"`
+	UserID      string       `json:"user_id"`
+	Name        string       `json:"name"`
+	TokenHash   string       `json:"token_hash"`
+	TokenPrefix string       `json:"token_prefix"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	LastUsedAt  sql.NullTime `json:"last_used_at"`
+}
+
+type Attachment struct {
+	ID            string       `json:"id"`
+	TransactionID string       `json:"transaction_id"`
+	FileName      string       `json:"file_name"`
+	MimeType      string       `json:"mime_type"`
+	SizeBytes     int64        `json:"size_bytes"`
+	Sha256        string       `json:"sha256"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+}
+
+type Budget struct {
+	ID             string       `json:"id"`
+	CategoryID     string       `json:"category_id"`
+	Period         string       `json:"period"`
+	Amount         int64        `json:"amount"`
+	Currency       string       `json:"currency"`
+	Rollover       bool         `json:"rollover"`
+	AlertThreshold float64      `json:"alert_threshold"`
+	StartDate      time.Time    `json:"start_date"`
+	CreatedBy      string       `json:"created_by"`
+	CreatedAt      sql.NullTime `json:"created_at"`
+	UpdatedAt      sql.NullTime `json:"updated_at"`
+}
+
 type Category struct {
 	ID        string         `json:"id"`
 	Name      string         `json:"name"`
@@ -21,6 +68,52 @@ type Category struct {
 	UpdatedAt sql.NullTime   `json:"updated_at"`
 }
 
+type Contact struct {
+	ID                     string         `json:"id"`
+	Name                   string         `json:"name"`
+	Type                   string         `json:"type"`
+	Email                  sql.NullString `json:"email"`
+	Phone                  sql.NullString `json:"phone"`
+	Address                sql.NullString `json:"address"`
+	TaxID                  sql.NullString `json:"tax_id"`
+	DefaultCategoryID      sql.NullString `json:"default_category_id"`
+	DefaultPaymentMethodID sql.NullString `json:"default_payment_method_id"`
+	CreatedBy              string         `json:"created_by"`
+	CreatedAt              sql.NullTime   `json:"created_at"`
+	UpdatedAt              sql.NullTime   `json:"updated_at"`
+}
+
+type Currency struct {
+	Code     string        `json:"code"`
+	Name     string        `json:"name"`
+	Exponent int64         `json:"exponent"`
+	Scale    sql.NullInt64 `json:"scale"`
+}
+
+type ExchangeRate struct {
+	ID           string         `json:"id"`
+	FromCurrency string         `json:"from_currency"`
+	ToCurrency   string         `json:"to_currency"`
+	Rate         float64        `json:"rate"`
+	RateDate     time.Time      `json:"rate_date"`
+	Source       sql.NullString `json:"source"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	UpdatedAt    sql.NullTime   `json:"updated_at"`
+}
+
+type Payment struct {
+	ID              string         `json:"id"`
+	TransactionID   string         `json:"transaction_id"`
+	Amount          int64          `json:"amount"`
+	PaymentDate     time.Time      `json:"payment_date"`
+	PaymentMethodID sql.NullString `json:"payment_method_id"`
+	Reference       sql.NullString `json:"reference"`
+	Notes           sql.NullString `json:"notes"`
+	CreatedBy       string         `json:"created_by"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	VoidedAt        sql.NullTime   `json:"voided_at"`
+}
+
 type PaymentMethod struct {
 	ID          string         `json:"id"`
 	Name        string         `json:"name"`
@@ -30,11 +123,45 @@ type PaymentMethod struct {
 	UpdatedAt   sql.NullTime   `json:"updated_at"`
 }
 
+type Reconciliation struct {
+	ID                 string       `json:"id"`
+	AccountID          string       `json:"account_id"`
+	StatementStartDate time.Time    `json:"statement_start_date"`
+	StatementEndDate   time.Time    `json:"statement_end_date"`
+	EndingBalance      int64        `json:"ending_balance"`
+	Status             string       `json:"status"`
+	CreatedBy          string       `json:"created_by"`
+	CreatedAt          sql.NullTime `json:"created_at"`
+	CompletedAt        sql.NullTime `json:"completed_at"`
+}
+
+type SavedTransactionFilter struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	FilterConfig string       `json:"filter_config"`
+	IsDefault    sql.NullBool `json:"is_default"`
+	CreatedBy    string       `json:"created_by"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+	UpdatedAt    sql.NullTime `json:"updated_at"`
+}
+
+type StatementLine struct {
+	ID               string         `json:"id"`
+	ReconciliationID string         `json:"reconciliation_id"`
+	LineDate         time.Time      `json:"line_date"`
+	Description      sql.NullString `json:"description"`
+	Amount           int64          `json:"amount"`
+	Reference        sql.NullString `json:"reference"`
+	TransactionID    sql.NullString `json:"transaction_id"`
+	MatchType        sql.NullString `json:"match_type"`
+	CreatedAt        sql.NullTime   `json:"created_at"`
+}
+
 type Transaction struct {
 	ID                  string          `json:"id"`
 	Type                string          `json:"type"`
 	Description         string          `json:"description"`
-	Amount              float64         `json:"amount"`
+	Amount              int64           `json:"amount"`
 	TransactionDate     time.Time       `json:"transaction_date"`
 	CategoryID          sql.NullString  `json:"category_id"`
 	Tags                sql.NullString  `json:"tags"`
@@ -45,10 +172,10 @@ type Transaction struct {
 	InvoiceNumber       sql.NullString  `json:"invoice_number"`
 	Notes               sql.NullString  `json:"notes"`
 	Attachments         sql.NullString  `json:"attachments"`
-	TaxAmount           sql.NullFloat64 `json:"tax_amount"`
-	DiscountAmount      sql.NullFloat64 `json:"discount_amount"`
-	DueAmount           sql.NullFloat64 `json:"due_amount"`
-	NetAmount           sql.NullFloat64 `json:"net_amount"`
+	TaxAmount           sql.NullInt64   `json:"tax_amount"`
+	DiscountAmount      sql.NullInt64   `json:"discount_amount"`
+	DueAmount           sql.NullInt64   `json:"due_amount"`
+	NetAmount           sql.NullInt64   `json:"net_amount"`
 	Currency            sql.NullString  `json:"currency"`
 	ExchangeRate        sql.NullFloat64 `json:"exchange_rate"`
 	IsRecurring         sql.NullBool    `json:"is_recurring"`
@@ -59,6 +186,55 @@ type Transaction struct {
 	CreatedAt           sql.NullTime    `json:"created_at"`
 	UpdatedAt           sql.NullTime    `json:"updated_at"`
 	DeletedAt           sql.NullTime    `json:"deleted_at"`
+	DueDate             sql.NullTime    `json:"due_date"`
+	ContactID           sql.NullString  `json:"contact_id"`
+	AccountID           sql.NullString  `json:"account_id"`
+	ClearedStatus       string          `json:"cleared_status"`
+}
+
+type TransactionHistory struct {
+	ID            string       `json:"id"`
+	TransactionID string       `json:"transaction_id"`
+	Version       int64        `json:"version"`
+	Action        string       `json:"action"`
+	Changes       string       `json:"changes"`
+	Snapshot      string       `json:"snapshot"`
+	ChangedBy     string       `json:"changed_by"`
+	ChangedAt     sql.NullTime `json:"changed_at"`
+}
+
+type TransactionTemplate struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	Description    sql.NullString `json:"description"`
+	Amount         sql.NullInt64  `json:"amount"`
+	Category       sql.NullString `json:"category"`
+	Tags           sql.NullString `json:"tags"`
+	CustomerVendor sql.NullString `json:"customer_vendor"`
+	PaymentMethod  sql.NullString `json:"payment_method"`
+	TaxAmount      sql.NullInt64  `json:"tax_amount"`
+	DiscountAmount sql.NullInt64  `json:"discount_amount"`
+	Currency       sql.NullString `json:"currency"`
+	Notes          sql.NullString `json:"notes"`
+	UsageCount     sql.NullInt64  `json:"usage_count"`
+	IsFavorite     sql.NullBool   `json:"is_favorite"`
+	CreatedBy      string         `json:"created_by"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+	UpdatedAt      sql.NullTime   `json:"updated_at"`
+}
+
+type Transfer struct {
+	ID            string         `json:"id"`
+	FromAccountID string         `json:"from_account_id"`
+	ToAccountID   string         `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	ToAmount      int64          `json:"to_amount"`
+	TransferDate  time.Time      `json:"transfer_date"`
+	Description   sql.NullString `json:"description"`
+	Reference     sql.NullString `json:"reference"`
+	CreatedBy     string         `json:"created_by"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
 }
 
 type User struct {
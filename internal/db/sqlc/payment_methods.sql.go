@@ -92,6 +92,26 @@ func (q *Queries) GetPaymentMethod(ctx context.Context, id string) (PaymentMetho
 	return i, err
 }
 
+const getPaymentMethodByName = `-- name: GetPaymentMethodByName :one
+SELECT id, name, description, is_active, created_at, updated_at FROM payment_methods
+WHERE name = ? AND is_active = TRUE
+LIMIT 1
+`
+
+func (q *Queries) GetPaymentMethodByName(ctx context.Context, name string) (PaymentMethod, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentMethodByName, name)
+	var i PaymentMethod
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getPaymentMethodName = `-- name: GetPaymentMethodName :one
 SELECT name FROM payment_methods
 WHERE id = ?
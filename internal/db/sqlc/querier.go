@@ -7,44 +7,157 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	ClearDefaultSavedFilters(ctx context.Context, createdBy string) error
+	CompleteReconciliation(ctx context.Context, id string) error
+	CountAccountUses(ctx context.Context, accountID sql.NullString) (int64, error)
 	CountTransactions(ctx context.Context, arg CountTransactionsParams) (int64, error)
 	CountTransactionsByCategory(ctx context.Context, categoryID sql.NullString) (int64, error)
+	CountTransactionsByContact(ctx context.Context, contactID sql.NullString) (int64, error)
 	CountTransactionsByPaymentMethod(ctx context.Context, paymentMethodID sql.NullString) (int64, error)
+	CountTransactionsByReference(ctx context.Context, arg CountTransactionsByReferenceParams) (int64, error)
+	CountUserRecords(ctx context.Context, createdBy string) (CountUserRecordsRow, error)
+	CreateAPIToken(ctx context.Context, arg CreateAPITokenParams) (ApiToken, error)
+	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (Attachment, error)
+	CreateBudget(ctx context.Context, arg CreateBudgetParams) (Budget, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateContact(ctx context.Context, arg CreateContactParams) (Contact, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
 	CreatePaymentMethod(ctx context.Context, arg CreatePaymentMethodParams) (PaymentMethod, error)
+	CreateReconciliation(ctx context.Context, arg CreateReconciliationParams) (Reconciliation, error)
+	CreateRecurringOccurrence(ctx context.Context, arg CreateRecurringOccurrenceParams) (int64, error)
+	CreateSavedFilter(ctx context.Context, arg CreateSavedFilterParams) (SavedTransactionFilter, error)
+	CreateStatementLine(ctx context.Context, arg CreateStatementLineParams) (StatementLine, error)
+	CreateTemplate(ctx context.Context, arg CreateTemplateParams) (TransactionTemplate, error)
 	CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error)
+	CreateTransactionHistory(ctx context.Context, arg CreateTransactionHistoryParams) error
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeactivateCategory(ctx context.Context, id string) error
 	DeactivatePaymentMethod(ctx context.Context, id string) error
+	DeleteAPIToken(ctx context.Context, arg DeleteAPITokenParams) (int64, error)
+	DeleteAccount(ctx context.Context, id string) error
+	DeleteAttachment(ctx context.Context, id string) error
+	DeleteBudget(ctx context.Context, id string) error
+	DeleteBudgetsByUser(ctx context.Context, createdBy string) error
 	DeleteCategory(ctx context.Context, id string) error
+	DeleteContact(ctx context.Context, id string) error
+	DeleteContactsByUser(ctx context.Context, createdBy string) error
+	DeleteExchangeRate(ctx context.Context, id string) error
 	DeletePaymentMethod(ctx context.Context, id string) error
+	DeleteReconciliation(ctx context.Context, id string) error
+	DeleteSavedFilter(ctx context.Context, id string) error
+	DeleteSavedFiltersByUser(ctx context.Context, createdBy string) error
+	DeleteStatementLine(ctx context.Context, id string) error
+	DeleteTemplate(ctx context.Context, id string) error
+	DeleteTemplatesByUser(ctx context.Context, createdBy string) error
 	DeleteTransaction(ctx context.Context, id string) error
+	DeleteTransfer(ctx context.Context, id string) (int64, error)
+	DeleteUser(ctx context.Context, id string) error
+	DetachChildTransactions(ctx context.Context, parentTransactionID sql.NullString) error
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (ApiToken, error)
+	GetAccount(ctx context.Context, id string) (Account, error)
+	GetAccountActivity(ctx context.Context, arg GetAccountActivityParams) ([]GetAccountActivityRow, error)
+	GetAttachment(ctx context.Context, id string) (Attachment, error)
+	GetBudget(ctx context.Context, id string) (Budget, error)
 	GetCategory(ctx context.Context, id string) (Category, error)
 	GetCategoryByName(ctx context.Context, name string) (Category, error)
 	GetCategoryName(ctx context.Context, id string) (string, error)
+	GetCategorySpending(ctx context.Context, arg GetCategorySpendingParams) ([]GetCategorySpendingRow, error)
+	GetClearedActivity(ctx context.Context, accountID sql.NullString) ([]GetClearedActivityRow, error)
+	GetContact(ctx context.Context, id string) (Contact, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetCustomerVendorSuggestions(ctx context.Context, arg GetCustomerVendorSuggestionsParams) ([]GetCustomerVendorSuggestionsRow, error)
 	GetDailyTransactionSummary(ctx context.Context, arg GetDailyTransactionSummaryParams) ([]GetDailyTransactionSummaryRow, error)
+	GetDefaultSavedFilter(ctx context.Context, createdBy string) (SavedTransactionFilter, error)
 	GetDescriptionSuggestions(ctx context.Context, arg GetDescriptionSuggestionsParams) ([]GetDescriptionSuggestionsRow, error)
+	GetEffectiveExchangeRate(ctx context.Context, arg GetEffectiveExchangeRateParams) (ExchangeRate, error)
+	GetLatestRecurringOccurrenceDate(ctx context.Context, parentTransactionID sql.NullString) (time.Time, error)
 	GetMonthlyTrend(ctx context.Context, arg GetMonthlyTrendParams) ([]GetMonthlyTrendRow, error)
+	GetOpenReconciliation(ctx context.Context, accountID string) (Reconciliation, error)
+	GetOpenStatementLineByTransaction(ctx context.Context, transactionID sql.NullString) (StatementLine, error)
+	GetPayment(ctx context.Context, id string) (Payment, error)
 	GetPaymentMethod(ctx context.Context, id string) (PaymentMethod, error)
+	GetPaymentMethodByName(ctx context.Context, name string) (PaymentMethod, error)
 	GetPaymentMethodName(ctx context.Context, id string) (string, error)
+	GetPaymentTotals(ctx context.Context, transactionID string) (GetPaymentTotalsRow, error)
+	GetProfitAndLoss(ctx context.Context, arg GetProfitAndLossParams) ([]GetProfitAndLossRow, error)
 	GetRecentTransactions(ctx context.Context, arg GetRecentTransactionsParams) ([]Transaction, error)
+	GetReconciliation(ctx context.Context, id string) (Reconciliation, error)
+	GetRecurringOccurrence(ctx context.Context, arg GetRecurringOccurrenceParams) (Transaction, error)
+	GetSavedFilter(ctx context.Context, id string) (SavedTransactionFilter, error)
+	GetSavedFilterByName(ctx context.Context, arg GetSavedFilterByNameParams) (SavedTransactionFilter, error)
+	GetStatementLine(ctx context.Context, id string) (StatementLine, error)
+	GetTemplate(ctx context.Context, id string) (TransactionTemplate, error)
 	GetTopCustomersVendors(ctx context.Context, arg GetTopCustomersVendorsParams) ([]GetTopCustomersVendorsRow, error)
 	GetTransaction(ctx context.Context, id string) (Transaction, error)
-	GetTransactionStats(ctx context.Context, arg GetTransactionStatsParams) (GetTransactionStatsRow, error)
+	GetTransactionStats(ctx context.Context, arg GetTransactionStatsParams) ([]GetTransactionStatsRow, error)
+	GetTransactionVersion(ctx context.Context, arg GetTransactionVersionParams) (TransactionHistory, error)
+	GetTransactionWithDeleted(ctx context.Context, id string) (Transaction, error)
 	GetTransactionsByCategory(ctx context.Context, arg GetTransactionsByCategoryParams) ([]GetTransactionsByCategoryRow, error)
+	GetTransfer(ctx context.Context, id string) (Transfer, error)
+	GetUser(ctx context.Context, id string) (User, error)
+	GetUserPreferences(ctx context.Context, id string) (sql.NullString, error)
+	IncrementTemplateUsage(ctx context.Context, id string) error
+	ListAPITokens(ctx context.Context, userID string) ([]ApiToken, error)
+	ListAccounts(ctx context.Context, createdBy string) ([]Account, error)
 	ListActiveCategories(ctx context.Context) ([]Category, error)
 	ListActivePaymentMethods(ctx context.Context) ([]PaymentMethod, error)
+	ListAttachmentHashes(ctx context.Context) ([]string, error)
+	ListAttachmentsByTransaction(ctx context.Context, transactionID string) ([]Attachment, error)
+	ListBudgets(ctx context.Context, createdBy string) ([]Budget, error)
 	ListCategories(ctx context.Context) ([]Category, error)
 	ListCategoriesByType(ctx context.Context, type_ string) ([]Category, error)
+	ListContacts(ctx context.Context, createdBy string) ([]Contact, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListDeletedTransactions(ctx context.Context, arg ListDeletedTransactionsParams) ([]Transaction, error)
+	ListExchangeRates(ctx context.Context, currency interface{}) ([]ExchangeRate, error)
+	ListMostUsedTemplates(ctx context.Context, arg ListMostUsedTemplatesParams) ([]TransactionTemplate, error)
+	ListOutstandingTransactions(ctx context.Context, arg ListOutstandingTransactionsParams) ([]Transaction, error)
 	ListPaymentMethods(ctx context.Context) ([]PaymentMethod, error)
+	ListPaymentsByTransaction(ctx context.Context, transactionID string) ([]Payment, error)
+	ListPurgeableTransactions(ctx context.Context, arg ListPurgeableTransactionsParams) ([]string, error)
+	ListReconciliations(ctx context.Context, accountID string) ([]Reconciliation, error)
+	ListRecurringTransactions(ctx context.Context) ([]Transaction, error)
+	ListSavedFilters(ctx context.Context, createdBy string) ([]SavedTransactionFilter, error)
+	ListStatementLines(ctx context.Context, reconciliationID string) ([]StatementLine, error)
+	ListTemplates(ctx context.Context, arg ListTemplatesParams) ([]TransactionTemplate, error)
+	ListTransactionHistory(ctx context.Context, transactionID string) ([]TransactionHistory, error)
 	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error)
-	SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]Transaction, error)
+	ListTransactionsByContact(ctx context.Context, contactID sql.NullString) ([]Transaction, error)
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnreconciledTransactions(ctx context.Context, arg ListUnreconciledTransactionsParams) ([]Transaction, error)
+	ListUsers(ctx context.Context) ([]User, error)
+	PurgeTransaction(ctx context.Context, id string) (int64, error)
+	ReconcileClearedTransactions(ctx context.Context, accountID sql.NullString) (int64, error)
+	RenameSavedFilter(ctx context.Context, arg RenameSavedFilterParams) (int64, error)
+	RestoreTransaction(ctx context.Context, id string) (int64, error)
+	SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error)
+	SearchTransactionsLike(ctx context.Context, arg SearchTransactionsLikeParams) ([]Transaction, error)
+	SetContactType(ctx context.Context, arg SetContactTypeParams) error
+	SetDefaultSavedFilter(ctx context.Context, id string) (int64, error)
+	SetStatementLineMatch(ctx context.Context, arg SetStatementLineMatchParams) error
+	SetTemplateFavorite(ctx context.Context, arg SetTemplateFavoriteParams) error
+	SetTransactionClearedStatus(ctx context.Context, arg SetTransactionClearedStatusParams) error
+	SetTransactionContact(ctx context.Context, arg SetTransactionContactParams) error
+	SetTransactionSettlement(ctx context.Context, arg SetTransactionSettlementParams) error
+	TouchAPIToken(ctx context.Context, id string) error
+	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateBudget(ctx context.Context, arg UpdateBudgetParams) (Budget, error)
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error)
 	UpdatePaymentMethod(ctx context.Context, arg UpdatePaymentMethodParams) (PaymentMethod, error)
+	UpdateReconciliation(ctx context.Context, arg UpdateReconciliationParams) (Reconciliation, error)
+	UpdateTemplate(ctx context.Context, arg UpdateTemplateParams) (TransactionTemplate, error)
 	UpdateTransaction(ctx context.Context, arg UpdateTransactionParams) (Transaction, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPreferences(ctx context.Context, arg UpdateUserPreferencesParams) (int64, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	VoidPayment(ctx context.Context, id string) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -16,15 +16,17 @@ SELECT COUNT(*) as count FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = ?1
     AND (?2 = '' OR transaction_date >= ?2)
-    AND (?3 = '' OR transaction_date <= ?3)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
     AND (?4 = '' OR type = ?4 OR ?4 LIKE '%' || type || '%')
     AND (?5 = '' OR category_id = ?5 OR ?5 LIKE '%' || category_id || '%')
     AND (?6 = '' OR payment_status = ?6 OR ?6 LIKE '%' || payment_status || '%')
     AND (?7 = '' OR payment_method_id = ?7 OR ?7 LIKE '%' || payment_method_id || '%')
-    AND (?8 = '' OR customer_vendor LIKE '%' || ?8 || '%')
-    AND (?9 = '' OR description LIKE '%' || ?9 || '%')
-    AND (?10 = 0 OR due_amount >= ?10)
-    AND (?11 = 0 OR due_amount <= ?11)
+    AND (?8 = '' OR contact_id = ?8)
+    AND (?9 = '' OR account_id = ?9)
+    AND (?10 = '' OR customer_vendor LIKE '%' || ?10 || '%')
+    AND (?11 = '' OR description LIKE '%' || ?11 || '%')
+    AND (?12 = 0 OR due_amount >= ROUND(?12 * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
+    AND (?13 = 0 OR due_amount <= ROUND(?13 * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
 `
 
 type CountTransactionsParams struct {
@@ -35,6 +37,8 @@ type CountTransactionsParams struct {
 	CategoryFilter       interface{} `json:"category_filter"`
 	PaymentStatusFilter  interface{} `json:"payment_status_filter"`
 	PaymentMethodFilter  interface{} `json:"payment_method_filter"`
+	ContactFilter        interface{} `json:"contact_filter"`
+	AccountFilter        interface{} `json:"account_filter"`
 	CustomerVendorSearch interface{} `json:"customer_vendor_search"`
 	DescriptionSearch    interface{} `json:"description_search"`
 	MinDueAmount         interface{} `json:"min_due_amount"`
@@ -50,6 +54,8 @@ func (q *Queries) CountTransactions(ctx context.Context, arg CountTransactionsPa
 		arg.CategoryFilter,
 		arg.PaymentStatusFilter,
 		arg.PaymentMethodFilter,
+		arg.ContactFilter,
+		arg.AccountFilter,
 		arg.CustomerVendorSearch,
 		arg.DescriptionSearch,
 		arg.MinDueAmount,
@@ -60,6 +66,101 @@ func (q *Queries) CountTransactions(ctx context.Context, arg CountTransactionsPa
 	return count, err
 }
 
+const countTransactionsByReference = `-- name: CountTransactionsByReference :one
+SELECT COUNT(*) as count FROM transactions
+WHERE created_by = ?1
+    AND COALESCE(account_id, '') = ?2
+    AND reference_number = ?3
+`
+
+type CountTransactionsByReferenceParams struct {
+	CreatedBy       string         `json:"created_by"`
+	AccountID       string         `json:"account_id"`
+	ReferenceNumber sql.NullString `json:"reference_number"`
+}
+
+func (q *Queries) CountTransactionsByReference(ctx context.Context, arg CountTransactionsByReferenceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransactionsByReference, arg.CreatedBy, arg.AccountID, arg.ReferenceNumber)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createRecurringOccurrence = `-- name: CreateRecurringOccurrence :execrows
+INSERT INTO transactions (
+    type, description, amount, transaction_date,
+    category_id, tags, customer_vendor, payment_method_id,
+    payment_status, reference_number, invoice_number,
+    notes, attachments, tax_amount, discount_amount, due_amount,
+    currency, exchange_rate, is_recurring, parent_transaction_id, created_by,
+    contact_id, account_id
+) VALUES (
+    ?, ?, ?, ?,
+    ?, ?, ?, ?,
+    ?, ?, ?,
+    ?, ?, ?, ?, ?,
+    ?, ?, FALSE, ?, ?,
+    ?, ?
+)
+ON CONFLICT DO NOTHING
+`
+
+type CreateRecurringOccurrenceParams struct {
+	Type                string          `json:"type"`
+	Description         string          `json:"description"`
+	Amount              int64           `json:"amount"`
+	TransactionDate     time.Time       `json:"transaction_date"`
+	CategoryID          sql.NullString  `json:"category_id"`
+	Tags                sql.NullString  `json:"tags"`
+	CustomerVendor      sql.NullString  `json:"customer_vendor"`
+	PaymentMethodID     sql.NullString  `json:"payment_method_id"`
+	PaymentStatus       sql.NullString  `json:"payment_status"`
+	ReferenceNumber     sql.NullString  `json:"reference_number"`
+	InvoiceNumber       sql.NullString  `json:"invoice_number"`
+	Notes               sql.NullString  `json:"notes"`
+	Attachments         sql.NullString  `json:"attachments"`
+	TaxAmount           sql.NullInt64   `json:"tax_amount"`
+	DiscountAmount      sql.NullInt64   `json:"discount_amount"`
+	DueAmount           sql.NullInt64   `json:"due_amount"`
+	Currency            sql.NullString  `json:"currency"`
+	ExchangeRate        sql.NullFloat64 `json:"exchange_rate"`
+	ParentTransactionID sql.NullString  `json:"parent_transaction_id"`
+	CreatedBy           string          `json:"created_by"`
+	ContactID           sql.NullString  `json:"contact_id"`
+	AccountID           sql.NullString  `json:"account_id"`
+}
+
+func (q *Queries) CreateRecurringOccurrence(ctx context.Context, arg CreateRecurringOccurrenceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createRecurringOccurrence,
+		arg.Type,
+		arg.Description,
+		arg.Amount,
+		arg.TransactionDate,
+		arg.CategoryID,
+		arg.Tags,
+		arg.CustomerVendor,
+		arg.PaymentMethodID,
+		arg.PaymentStatus,
+		arg.ReferenceNumber,
+		arg.InvoiceNumber,
+		arg.Notes,
+		arg.Attachments,
+		arg.TaxAmount,
+		arg.DiscountAmount,
+		arg.DueAmount,
+		arg.Currency,
+		arg.ExchangeRate,
+		arg.ParentTransactionID,
+		arg.CreatedBy,
+		arg.ContactID,
+		arg.AccountID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createTransaction = `-- name: CreateTransaction :one
 INSERT INTO transactions (
     type, description, amount, transaction_date,
@@ -67,21 +168,23 @@ INSERT INTO transactions (
     payment_status, reference_number, invoice_number,
     notes, attachments, tax_amount, discount_amount, due_amount,
     currency, exchange_rate, is_recurring, recurring_frequency,
-    recurring_end_date, parent_transaction_id, created_by
+    recurring_end_date, parent_transaction_id, created_by, due_date,
+    contact_id, account_id
 ) VALUES (
     ?, ?, ?, ?,
     ?, ?, ?, ?,
     ?, ?, ?,
     ?, ?, ?, ?, ?,
     ?, ?, ?, ?,
-    ?, ?, ?
-) RETURNING id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at
+    ?, ?, ?, ?,
+    ?, ?
+) RETURNING id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status
 `
 
 type CreateTransactionParams struct {
 	Type                string          `json:"type"`
 	Description         string          `json:"description"`
-	Amount              float64         `json:"amount"`
+	Amount              int64           `json:"amount"`
 	TransactionDate     time.Time       `json:"transaction_date"`
 	CategoryID          sql.NullString  `json:"category_id"`
 	Tags                sql.NullString  `json:"tags"`
@@ -92,9 +195,9 @@ type CreateTransactionParams struct {
 	InvoiceNumber       sql.NullString  `json:"invoice_number"`
 	Notes               sql.NullString  `json:"notes"`
 	Attachments         sql.NullString  `json:"attachments"`
-	TaxAmount           sql.NullFloat64 `json:"tax_amount"`
-	DiscountAmount      sql.NullFloat64 `json:"discount_amount"`
-	DueAmount           sql.NullFloat64 `json:"due_amount"`
+	TaxAmount           sql.NullInt64   `json:"tax_amount"`
+	DiscountAmount      sql.NullInt64   `json:"discount_amount"`
+	DueAmount           sql.NullInt64   `json:"due_amount"`
 	Currency            sql.NullString  `json:"currency"`
 	ExchangeRate        sql.NullFloat64 `json:"exchange_rate"`
 	IsRecurring         sql.NullBool    `json:"is_recurring"`
@@ -102,6 +205,9 @@ type CreateTransactionParams struct {
 	RecurringEndDate    sql.NullTime    `json:"recurring_end_date"`
 	ParentTransactionID sql.NullString  `json:"parent_transaction_id"`
 	CreatedBy           string          `json:"created_by"`
+	DueDate             sql.NullTime    `json:"due_date"`
+	ContactID           sql.NullString  `json:"contact_id"`
+	AccountID           sql.NullString  `json:"account_id"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -129,6 +235,9 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.RecurringEndDate,
 		arg.ParentTransactionID,
 		arg.CreatedBy,
+		arg.DueDate,
+		arg.ContactID,
+		arg.AccountID,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -160,6 +269,10 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.DueDate,
+		&i.ContactID,
+		&i.AccountID,
+		&i.ClearedStatus,
 	)
 	return i, err
 }
@@ -175,27 +288,96 @@ func (q *Queries) DeleteTransaction(ctx context.Context, id string) error {
 	return err
 }
 
+const detachChildTransactions = `-- name: DetachChildTransactions :exec
+UPDATE transactions
+SET parent_transaction_id = NULL
+WHERE parent_transaction_id = ?
+`
+
+func (q *Queries) DetachChildTransactions(ctx context.Context, parentTransactionID sql.NullString) error {
+	_, err := q.db.ExecContext(ctx, detachChildTransactions, parentTransactionID)
+	return err
+}
+
+const getCategorySpending = `-- name: GetCategorySpending :many
+SELECT
+    category_id,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
+    CAST(COALESCE(SUM(amount - COALESCE(discount_amount, 0) + COALESCE(tax_amount, 0)), 0) AS INTEGER) as total_amount
+FROM transactions
+WHERE deleted_at IS NULL
+    AND created_by = ?1
+    AND category_id IS NOT NULL
+    AND COALESCE(payment_status, '') != 'cancelled'
+    AND transaction_date >= date(?2)
+    AND transaction_date < date(?3, '+1 day')
+GROUP BY category_id, COALESCE(currency, 'USD'), transaction_date, exchange_rate
+`
+
+type GetCategorySpendingParams struct {
+	CreatedBy string      `json:"created_by"`
+	FromDate  interface{} `json:"from_date"`
+	ToDate    interface{} `json:"to_date"`
+}
+
+type GetCategorySpendingRow struct {
+	CategoryID      sql.NullString  `json:"category_id"`
+	Currency        string          `json:"currency"`
+	TransactionDate time.Time       `json:"transaction_date"`
+	ExchangeRate    sql.NullFloat64 `json:"exchange_rate"`
+	TotalAmount     int64           `json:"total_amount"`
+}
+
+func (q *Queries) GetCategorySpending(ctx context.Context, arg GetCategorySpendingParams) ([]GetCategorySpendingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCategorySpending, arg.CreatedBy, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCategorySpendingRow{}
+	for rows.Next() {
+		var i GetCategorySpendingRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Currency,
+			&i.TransactionDate,
+			&i.ExchangeRate,
+			&i.TotalAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCustomerVendorSuggestions = `-- name: GetCustomerVendorSuggestions :many
 SELECT DISTINCT customer_vendor, COUNT(*) as frequency
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = ?1
     AND customer_vendor IS NOT NULL
     AND customer_vendor != ''
-    AND (? = '' OR type = ?)
-    AND (? = '' OR customer_vendor LIKE '%' || ? || '%')
+    AND (?2 = '' OR type = ?2)
+    AND (?3 = '' OR customer_vendor LIKE '%' || ?3 || '%')
 GROUP BY customer_vendor
 ORDER BY frequency DESC, customer_vendor ASC
-LIMIT ?
+LIMIT ?4
 `
 
 type GetCustomerVendorSuggestionsParams struct {
-	CreatedBy string         `json:"created_by"`
-	Column2   interface{}    `json:"column_2"`
-	Type      string         `json:"type"`
-	Column4   interface{}    `json:"column_4"`
-	Column5   sql.NullString `json:"column_5"`
-	Limit     int64          `json:"limit"`
+	CreatedBy  string      `json:"created_by"`
+	TypeFilter interface{} `json:"type_filter"`
+	Search     interface{} `json:"search"`
+	Limit      int64       `json:"limit"`
 }
 
 type GetCustomerVendorSuggestionsRow struct {
@@ -206,10 +388,8 @@ type GetCustomerVendorSuggestionsRow struct {
 func (q *Queries) GetCustomerVendorSuggestions(ctx context.Context, arg GetCustomerVendorSuggestionsParams) ([]GetCustomerVendorSuggestionsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getCustomerVendorSuggestions,
 		arg.CreatedBy,
-		arg.Column2,
-		arg.Type,
-		arg.Column4,
-		arg.Column5,
+		arg.TypeFilter,
+		arg.Search,
 		arg.Limit,
 	)
 	if err != nil {
@@ -236,42 +416,43 @@ func (q *Queries) GetCustomerVendorSuggestions(ctx context.Context, arg GetCusto
 const getDailyTransactionSummary = `-- name: GetDailyTransactionSummary :many
 SELECT
     transaction_date,
-    SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END) as daily_income,
-    SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END) as daily_expense,
-    SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE -net_amount END) as daily_profit,
+    COALESCE(currency, 'USD') as currency,
+    exchange_rate,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END), 0) AS INTEGER) as daily_income,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END), 0) AS INTEGER) as daily_expense,
     COUNT(*) as transaction_count
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
-    AND (? = '' OR transaction_date >= ?)
-    AND (? = '' OR transaction_date <= ?)
-GROUP BY transaction_date
-ORDER BY transaction_date DESC
+    AND created_by = ?1
+    AND (?2 = '' OR transaction_date >= ?2)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
+    AND (?4 = '' OR type = ?4 OR ?4 LIKE '%' || type || '%')
+GROUP BY transaction_date, COALESCE(currency, 'USD'), exchange_rate
+ORDER BY transaction_date
 `
 
 type GetDailyTransactionSummaryParams struct {
-	CreatedBy         string      `json:"created_by"`
-	Column2           interface{} `json:"column_2"`
-	TransactionDate   time.Time   `json:"transaction_date"`
-	Column4           interface{} `json:"column_4"`
-	TransactionDate_2 time.Time   `json:"transaction_date_2"`
+	CreatedBy  string      `json:"created_by"`
+	FromDate   interface{} `json:"from_date"`
+	ToDate     interface{} `json:"to_date"`
+	TypeFilter interface{} `json:"type_filter"`
 }
 
 type GetDailyTransactionSummaryRow struct {
 	TransactionDate  time.Time       `json:"transaction_date"`
-	DailyIncome      sql.NullFloat64 `json:"daily_income"`
-	DailyExpense     sql.NullFloat64 `json:"daily_expense"`
-	DailyProfit      sql.NullFloat64 `json:"daily_profit"`
+	Currency         string          `json:"currency"`
+	ExchangeRate     sql.NullFloat64 `json:"exchange_rate"`
+	DailyIncome      int64           `json:"daily_income"`
+	DailyExpense     int64           `json:"daily_expense"`
 	TransactionCount int64           `json:"transaction_count"`
 }
 
 func (q *Queries) GetDailyTransactionSummary(ctx context.Context, arg GetDailyTransactionSummaryParams) ([]GetDailyTransactionSummaryRow, error) {
 	rows, err := q.db.QueryContext(ctx, getDailyTransactionSummary,
 		arg.CreatedBy,
-		arg.Column2,
-		arg.TransactionDate,
-		arg.Column4,
-		arg.TransactionDate_2,
+		arg.FromDate,
+		arg.ToDate,
+		arg.TypeFilter,
 	)
 	if err != nil {
 		return nil, err
@@ -282,9 +463,10 @@ func (q *Queries) GetDailyTransactionSummary(ctx context.Context, arg GetDailyTr
 		var i GetDailyTransactionSummaryRow
 		if err := rows.Scan(
 			&i.TransactionDate,
+			&i.Currency,
+			&i.ExchangeRate,
 			&i.DailyIncome,
 			&i.DailyExpense,
-			&i.DailyProfit,
 			&i.TransactionCount,
 		); err != nil {
 			return nil, err
@@ -304,23 +486,21 @@ const getDescriptionSuggestions = `-- name: GetDescriptionSuggestions :many
 SELECT DISTINCT description, COUNT(*) as frequency
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = ?1
     AND description IS NOT NULL
     AND description != ''
-    AND (? = '' OR type = ?)
-    AND (? = '' OR description LIKE '%' || ? || '%')
+    AND (?2 = '' OR type = ?2)
+    AND (?3 = '' OR description LIKE '%' || ?3 || '%')
 GROUP BY description
 ORDER BY frequency DESC, description ASC
-LIMIT ?
+LIMIT ?4
 `
 
 type GetDescriptionSuggestionsParams struct {
-	CreatedBy string         `json:"created_by"`
-	Column2   interface{}    `json:"column_2"`
-	Type      string         `json:"type"`
-	Column4   interface{}    `json:"column_4"`
-	Column5   sql.NullString `json:"column_5"`
-	Limit     int64          `json:"limit"`
+	CreatedBy  string      `json:"created_by"`
+	TypeFilter interface{} `json:"type_filter"`
+	Search     interface{} `json:"search"`
+	Limit      int64       `json:"limit"`
 }
 
 type GetDescriptionSuggestionsRow struct {
@@ -331,10 +511,8 @@ type GetDescriptionSuggestionsRow struct {
 func (q *Queries) GetDescriptionSuggestions(ctx context.Context, arg GetDescriptionSuggestionsParams) ([]GetDescriptionSuggestionsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getDescriptionSuggestions,
 		arg.CreatedBy,
-		arg.Column2,
-		arg.Type,
-		arg.Column4,
-		arg.Column5,
+		arg.TypeFilter,
+		arg.Search,
 		arg.Limit,
 	)
 	if err != nil {
@@ -358,43 +536,60 @@ func (q *Queries) GetDescriptionSuggestions(ctx context.Context, arg GetDescript
 	return items, nil
 }
 
+const getLatestRecurringOccurrenceDate = `-- name: GetLatestRecurringOccurrenceDate :one
+SELECT transaction_date FROM transactions
+WHERE parent_transaction_id = ?
+ORDER BY transaction_date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestRecurringOccurrenceDate(ctx context.Context, parentTransactionID sql.NullString) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLatestRecurringOccurrenceDate, parentTransactionID)
+	var transaction_date time.Time
+	err := row.Scan(&transaction_date)
+	return transaction_date, err
+}
+
 const getMonthlyTrend = `-- name: GetMonthlyTrend :many
 SELECT
-    strftime('%Y-%m', transaction_date) as month,
+    transaction_date,
     type,
+    COALESCE(currency, 'USD') as currency,
+    exchange_rate,
     COUNT(*) as count,
-    SUM(net_amount) as total_amount
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_amount
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
-    AND (? = '' OR transaction_date >= ?)
-    AND (? = '' OR transaction_date <= ?)
-GROUP BY month, type
-ORDER BY month DESC
+    AND created_by = ?1
+    AND (?2 = '' OR transaction_date >= ?2)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
+    AND (?4 = '' OR type = ?4 OR ?4 LIKE '%' || type || '%')
+GROUP BY transaction_date, type, COALESCE(currency, 'USD'), exchange_rate
+ORDER BY transaction_date
 `
 
 type GetMonthlyTrendParams struct {
-	CreatedBy         string      `json:"created_by"`
-	Column2           interface{} `json:"column_2"`
-	TransactionDate   time.Time   `json:"transaction_date"`
-	Column4           interface{} `json:"column_4"`
-	TransactionDate_2 time.Time   `json:"transaction_date_2"`
+	CreatedBy  string      `json:"created_by"`
+	FromDate   interface{} `json:"from_date"`
+	ToDate     interface{} `json:"to_date"`
+	TypeFilter interface{} `json:"type_filter"`
 }
 
 type GetMonthlyTrendRow struct {
-	Month       interface{}     `json:"month"`
-	Type        string          `json:"type"`
-	Count       int64           `json:"count"`
-	TotalAmount sql.NullFloat64 `json:"total_amount"`
+	TransactionDate time.Time       `json:"transaction_date"`
+	Type            string          `json:"type"`
+	Currency        string          `json:"currency"`
+	ExchangeRate    sql.NullFloat64 `json:"exchange_rate"`
+	Count           int64           `json:"count"`
+	TotalAmount     int64           `json:"total_amount"`
 }
 
 func (q *Queries) GetMonthlyTrend(ctx context.Context, arg GetMonthlyTrendParams) ([]GetMonthlyTrendRow, error) {
 	rows, err := q.db.QueryContext(ctx, getMonthlyTrend,
 		arg.CreatedBy,
-		arg.Column2,
-		arg.TransactionDate,
-		arg.Column4,
-		arg.TransactionDate_2,
+		arg.FromDate,
+		arg.ToDate,
+		arg.TypeFilter,
 	)
 	if err != nil {
 		return nil, err
@@ -404,8 +599,10 @@ func (q *Queries) GetMonthlyTrend(ctx context.Context, arg GetMonthlyTrendParams
 	for rows.Next() {
 		var i GetMonthlyTrendRow
 		if err := rows.Scan(
-			&i.Month,
+			&i.TransactionDate,
 			&i.Type,
+			&i.Currency,
+			&i.ExchangeRate,
 			&i.Count,
 			&i.TotalAmount,
 		); err != nil {
@@ -422,8 +619,75 @@ func (q *Queries) GetMonthlyTrend(ctx context.Context, arg GetMonthlyTrendParams
 	return items, nil
 }
 
+const getProfitAndLoss = `-- name: GetProfitAndLoss :many
+SELECT
+    category_id,
+    type,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
+    CAST(COALESCE(SUM(amount - COALESCE(discount_amount, 0)), 0) AS INTEGER) as total_amount,
+    CAST(COALESCE(SUM(tax_amount), 0) AS INTEGER) as total_tax,
+    CAST(COALESCE(SUM(discount_amount), 0) AS INTEGER) as total_discount
+FROM transactions
+WHERE deleted_at IS NULL
+    AND created_by = ?1
+    AND (?2 = '' OR transaction_date >= ?2)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
+GROUP BY category_id, type, COALESCE(currency, 'USD'), transaction_date, exchange_rate
+`
+
+type GetProfitAndLossParams struct {
+	CreatedBy string      `json:"created_by"`
+	FromDate  interface{} `json:"from_date"`
+	ToDate    interface{} `json:"to_date"`
+}
+
+type GetProfitAndLossRow struct {
+	CategoryID      sql.NullString  `json:"category_id"`
+	Type            string          `json:"type"`
+	Currency        string          `json:"currency"`
+	TransactionDate time.Time       `json:"transaction_date"`
+	ExchangeRate    sql.NullFloat64 `json:"exchange_rate"`
+	TotalAmount     int64           `json:"total_amount"`
+	TotalTax        int64           `json:"total_tax"`
+	TotalDiscount   int64           `json:"total_discount"`
+}
+
+func (q *Queries) GetProfitAndLoss(ctx context.Context, arg GetProfitAndLossParams) ([]GetProfitAndLossRow, error) {
+	rows, err := q.db.QueryContext(ctx, getProfitAndLoss, arg.CreatedBy, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetProfitAndLossRow{}
+	for rows.Next() {
+		var i GetProfitAndLossRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.Type,
+			&i.Currency,
+			&i.TransactionDate,
+			&i.ExchangeRate,
+			&i.TotalAmount,
+			&i.TotalTax,
+			&i.TotalDiscount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRecentTransactions = `-- name: GetRecentTransactions :many
-SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at FROM transactions
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = ?
 ORDER BY created_at DESC
@@ -473,6 +737,10 @@ func (q *Queries) GetRecentTransactions(ctx context.Context, arg GetRecentTransa
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -487,48 +755,100 @@ func (q *Queries) GetRecentTransactions(ctx context.Context, arg GetRecentTransa
 	return items, nil
 }
 
+const getRecurringOccurrence = `-- name: GetRecurringOccurrence :one
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
+WHERE parent_transaction_id = ? AND transaction_date = ?
+`
+
+type GetRecurringOccurrenceParams struct {
+	ParentTransactionID sql.NullString `json:"parent_transaction_id"`
+	TransactionDate     time.Time      `json:"transaction_date"`
+}
+
+func (q *Queries) GetRecurringOccurrence(ctx context.Context, arg GetRecurringOccurrenceParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringOccurrence, arg.ParentTransactionID, arg.TransactionDate)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Description,
+		&i.Amount,
+		&i.TransactionDate,
+		&i.CategoryID,
+		&i.Tags,
+		&i.CustomerVendor,
+		&i.PaymentMethodID,
+		&i.PaymentStatus,
+		&i.ReferenceNumber,
+		&i.InvoiceNumber,
+		&i.Notes,
+		&i.Attachments,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.DueAmount,
+		&i.NetAmount,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.IsRecurring,
+		&i.RecurringFrequency,
+		&i.RecurringEndDate,
+		&i.ParentTransactionID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DueDate,
+		&i.ContactID,
+		&i.AccountID,
+		&i.ClearedStatus,
+	)
+	return i, err
+}
+
 const getTopCustomersVendors = `-- name: GetTopCustomersVendors :many
 SELECT
     customer_vendor,
     type,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
     COUNT(*) as transaction_count,
-    SUM(net_amount) as total_amount
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_amount
 FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = ?1
+    AND (?2 = '' OR transaction_date >= ?2)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
+    AND (?4 = '' OR type = ?4 OR ?4 LIKE '%' || type || '%')
     AND customer_vendor IS NOT NULL
     AND customer_vendor != ''
-    AND (? = '' OR transaction_date >= ?)
-    AND (? = '' OR transaction_date <= ?)
-GROUP BY customer_vendor, type
-ORDER BY total_amount DESC
-LIMIT ?
+GROUP BY customer_vendor, type, COALESCE(currency, 'USD'), transaction_date, exchange_rate
+ORDER BY customer_vendor, type
 `
 
 type GetTopCustomersVendorsParams struct {
-	CreatedBy         string      `json:"created_by"`
-	Column2           interface{} `json:"column_2"`
-	TransactionDate   time.Time   `json:"transaction_date"`
-	Column4           interface{} `json:"column_4"`
-	TransactionDate_2 time.Time   `json:"transaction_date_2"`
-	Limit             int64       `json:"limit"`
+	CreatedBy  string      `json:"created_by"`
+	FromDate   interface{} `json:"from_date"`
+	ToDate     interface{} `json:"to_date"`
+	TypeFilter interface{} `json:"type_filter"`
 }
 
 type GetTopCustomersVendorsRow struct {
 	CustomerVendor   sql.NullString  `json:"customer_vendor"`
 	Type             string          `json:"type"`
+	Currency         string          `json:"currency"`
+	TransactionDate  time.Time       `json:"transaction_date"`
+	ExchangeRate     sql.NullFloat64 `json:"exchange_rate"`
 	TransactionCount int64           `json:"transaction_count"`
-	TotalAmount      sql.NullFloat64 `json:"total_amount"`
+	TotalAmount      int64           `json:"total_amount"`
 }
 
 func (q *Queries) GetTopCustomersVendors(ctx context.Context, arg GetTopCustomersVendorsParams) ([]GetTopCustomersVendorsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getTopCustomersVendors,
 		arg.CreatedBy,
-		arg.Column2,
-		arg.TransactionDate,
-		arg.Column4,
-		arg.TransactionDate_2,
-		arg.Limit,
+		arg.FromDate,
+		arg.ToDate,
+		arg.TypeFilter,
 	)
 	if err != nil {
 		return nil, err
@@ -540,6 +860,9 @@ func (q *Queries) GetTopCustomersVendors(ctx context.Context, arg GetTopCustomer
 		if err := rows.Scan(
 			&i.CustomerVendor,
 			&i.Type,
+			&i.Currency,
+			&i.TransactionDate,
+			&i.ExchangeRate,
 			&i.TransactionCount,
 			&i.TotalAmount,
 		); err != nil {
@@ -557,7 +880,7 @@ func (q *Queries) GetTopCustomersVendors(ctx context.Context, arg GetTopCustomer
 }
 
 const getTransaction = `-- name: GetTransaction :one
-SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at FROM transactions
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
 WHERE id = ? AND deleted_at IS NULL
 `
 
@@ -593,26 +916,33 @@ func (q *Queries) GetTransaction(ctx context.Context, id string) (Transaction, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.DueDate,
+		&i.ContactID,
+		&i.AccountID,
+		&i.ClearedStatus,
 	)
 	return i, err
 }
 
-const getTransactionStats = `-- name: GetTransactionStats :one
+const getTransactionStats = `-- name: GetTransactionStats :many
 SELECT
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
     COUNT(CASE WHEN type IN ('income', 'sale') THEN 1 END) as total_income_count,
     COUNT(CASE WHEN type IN ('expense', 'purchase') THEN 1 END) as total_expense_count,
-    COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END), 0) as total_income,
-    COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END), 0) as total_expenses,
-    COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE -net_amount END), 0) as net_profit,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('income', 'sale') THEN net_amount ELSE 0 END), 0) AS INTEGER) as total_income,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') THEN net_amount ELSE 0 END), 0) AS INTEGER) as total_expenses,
     COUNT(*) as total_transactions,
-    COALESCE(AVG(net_amount), 0) as average_transaction,
-    COALESCE(SUM(CASE WHEN type IN ('income', 'sale') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) as pending_income,
-    COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) as pending_expenses
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_net_amount,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('income', 'sale') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) AS INTEGER) as pending_income,
+    CAST(COALESCE(SUM(CASE WHEN type IN ('expense', 'purchase') AND payment_status = 'pending' THEN net_amount ELSE 0 END), 0) AS INTEGER) as pending_expenses
 FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = ?1
     AND (?2 = '' OR transaction_date >= ?2)
-    AND (?3 = '' OR transaction_date <= ?3)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
+GROUP BY COALESCE(currency, 'USD'), transaction_date, exchange_rate
 `
 
 type GetTransactionStatsParams struct {
@@ -622,48 +952,116 @@ type GetTransactionStatsParams struct {
 }
 
 type GetTransactionStatsRow struct {
-	TotalIncomeCount   int64       `json:"total_income_count"`
-	TotalExpenseCount  int64       `json:"total_expense_count"`
-	TotalIncome        interface{} `json:"total_income"`
-	TotalExpenses      interface{} `json:"total_expenses"`
-	NetProfit          interface{} `json:"net_profit"`
-	TotalTransactions  int64       `json:"total_transactions"`
-	AverageTransaction interface{} `json:"average_transaction"`
-	PendingIncome      interface{} `json:"pending_income"`
-	PendingExpenses    interface{} `json:"pending_expenses"`
-}
-
-func (q *Queries) GetTransactionStats(ctx context.Context, arg GetTransactionStatsParams) (GetTransactionStatsRow, error) {
-	row := q.db.QueryRowContext(ctx, getTransactionStats, arg.CreatedBy, arg.FromDate, arg.ToDate)
-	var i GetTransactionStatsRow
-	err := row.Scan(
-		&i.TotalIncomeCount,
-		&i.TotalExpenseCount,
-		&i.TotalIncome,
-		&i.TotalExpenses,
-		&i.NetProfit,
-		&i.TotalTransactions,
-		&i.AverageTransaction,
-		&i.PendingIncome,
-		&i.PendingExpenses,
-	)
-	return i, err
+	Currency          string          `json:"currency"`
+	TransactionDate   time.Time       `json:"transaction_date"`
+	ExchangeRate      sql.NullFloat64 `json:"exchange_rate"`
+	TotalIncomeCount  int64           `json:"total_income_count"`
+	TotalExpenseCount int64           `json:"total_expense_count"`
+	TotalIncome       int64           `json:"total_income"`
+	TotalExpenses     int64           `json:"total_expenses"`
+	TotalTransactions int64           `json:"total_transactions"`
+	TotalNetAmount    int64           `json:"total_net_amount"`
+	PendingIncome     int64           `json:"pending_income"`
+	PendingExpenses   int64           `json:"pending_expenses"`
 }
 
-const getTransactionsByCategory = `-- name: GetTransactionsByCategory :many
-SELECT
-    category_id,
-    type,
+func (q *Queries) GetTransactionStats(ctx context.Context, arg GetTransactionStatsParams) ([]GetTransactionStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTransactionStats, arg.CreatedBy, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTransactionStatsRow{}
+	for rows.Next() {
+		var i GetTransactionStatsRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.TransactionDate,
+			&i.ExchangeRate,
+			&i.TotalIncomeCount,
+			&i.TotalExpenseCount,
+			&i.TotalIncome,
+			&i.TotalExpenses,
+			&i.TotalTransactions,
+			&i.TotalNetAmount,
+			&i.PendingIncome,
+			&i.PendingExpenses,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransactionWithDeleted = `-- name: GetTransactionWithDeleted :one
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
+WHERE id = ?
+`
+
+func (q *Queries) GetTransactionWithDeleted(ctx context.Context, id string) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getTransactionWithDeleted, id)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Description,
+		&i.Amount,
+		&i.TransactionDate,
+		&i.CategoryID,
+		&i.Tags,
+		&i.CustomerVendor,
+		&i.PaymentMethodID,
+		&i.PaymentStatus,
+		&i.ReferenceNumber,
+		&i.InvoiceNumber,
+		&i.Notes,
+		&i.Attachments,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.DueAmount,
+		&i.NetAmount,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.IsRecurring,
+		&i.RecurringFrequency,
+		&i.RecurringEndDate,
+		&i.ParentTransactionID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.DueDate,
+		&i.ContactID,
+		&i.AccountID,
+		&i.ClearedStatus,
+	)
+	return i, err
+}
+
+const getTransactionsByCategory = `-- name: GetTransactionsByCategory :many
+SELECT
+    category_id,
+    type,
+    COALESCE(currency, 'USD') as currency,
+    transaction_date,
+    exchange_rate,
     COUNT(*) as count,
-    SUM(net_amount) as total_amount
+    CAST(COALESCE(SUM(net_amount), 0) AS INTEGER) as total_amount
 FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = ?1
     AND (?2 = '' OR transaction_date >= ?2)
-    AND (?3 = '' OR transaction_date <= ?3)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
     AND category_id IS NOT NULL
-GROUP BY category_id, type
-ORDER BY total_amount DESC
+GROUP BY category_id, type, COALESCE(currency, 'USD'), transaction_date, exchange_rate
+ORDER BY category_id, type
 `
 
 type GetTransactionsByCategoryParams struct {
@@ -673,10 +1071,13 @@ type GetTransactionsByCategoryParams struct {
 }
 
 type GetTransactionsByCategoryRow struct {
-	CategoryID  sql.NullString  `json:"category_id"`
-	Type        string          `json:"type"`
-	Count       int64           `json:"count"`
-	TotalAmount sql.NullFloat64 `json:"total_amount"`
+	CategoryID      sql.NullString  `json:"category_id"`
+	Type            string          `json:"type"`
+	Currency        string          `json:"currency"`
+	TransactionDate time.Time       `json:"transaction_date"`
+	ExchangeRate    sql.NullFloat64 `json:"exchange_rate"`
+	Count           int64           `json:"count"`
+	TotalAmount     int64           `json:"total_amount"`
 }
 
 func (q *Queries) GetTransactionsByCategory(ctx context.Context, arg GetTransactionsByCategoryParams) ([]GetTransactionsByCategoryRow, error) {
@@ -691,6 +1092,9 @@ func (q *Queries) GetTransactionsByCategory(ctx context.Context, arg GetTransact
 		if err := rows.Scan(
 			&i.CategoryID,
 			&i.Type,
+			&i.Currency,
+			&i.TransactionDate,
+			&i.ExchangeRate,
 			&i.Count,
 			&i.TotalAmount,
 		); err != nil {
@@ -707,22 +1111,278 @@ func (q *Queries) GetTransactionsByCategory(ctx context.Context, arg GetTransact
 	return items, nil
 }
 
+const listDeletedTransactions = `-- name: ListDeletedTransactions :many
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
+WHERE deleted_at IS NOT NULL
+    AND created_by = ?1
+ORDER BY deleted_at DESC, id DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListDeletedTransactionsParams struct {
+	CreatedBy string `json:"created_by"`
+	Offset    int64  `json:"offset"`
+	Limit     int64  `json:"limit"`
+}
+
+func (q *Queries) ListDeletedTransactions(ctx context.Context, arg ListDeletedTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedTransactions, arg.CreatedBy, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Description,
+			&i.Amount,
+			&i.TransactionDate,
+			&i.CategoryID,
+			&i.Tags,
+			&i.CustomerVendor,
+			&i.PaymentMethodID,
+			&i.PaymentStatus,
+			&i.ReferenceNumber,
+			&i.InvoiceNumber,
+			&i.Notes,
+			&i.Attachments,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.DueAmount,
+			&i.NetAmount,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.IsRecurring,
+			&i.RecurringFrequency,
+			&i.RecurringEndDate,
+			&i.ParentTransactionID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutstandingTransactions = `-- name: ListOutstandingTransactions :many
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
+WHERE deleted_at IS NULL
+    AND created_by = ?1
+    AND (?2 = '' OR type = ?2 OR ?2 LIKE '%' || type || '%')
+    AND transaction_date < date(?3, '+1 day')
+    AND COALESCE(payment_status, 'completed') != 'cancelled'
+    AND (COALESCE(due_amount, 0) > 0 OR payment_status = 'pending')
+ORDER BY customer_vendor, COALESCE(due_date, transaction_date), id
+`
+
+type ListOutstandingTransactionsParams struct {
+	CreatedBy  string      `json:"created_by"`
+	TypeFilter interface{} `json:"type_filter"`
+	AsOf       interface{} `json:"as_of"`
+}
+
+func (q *Queries) ListOutstandingTransactions(ctx context.Context, arg ListOutstandingTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listOutstandingTransactions, arg.CreatedBy, arg.TypeFilter, arg.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Description,
+			&i.Amount,
+			&i.TransactionDate,
+			&i.CategoryID,
+			&i.Tags,
+			&i.CustomerVendor,
+			&i.PaymentMethodID,
+			&i.PaymentStatus,
+			&i.ReferenceNumber,
+			&i.InvoiceNumber,
+			&i.Notes,
+			&i.Attachments,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.DueAmount,
+			&i.NetAmount,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.IsRecurring,
+			&i.RecurringFrequency,
+			&i.RecurringEndDate,
+			&i.ParentTransactionID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableTransactions = `-- name: ListPurgeableTransactions :many
+SELECT t.id FROM transactions t
+WHERE t.deleted_at IS NOT NULL
+    AND t.created_by = ?1
+    AND (?2 = '' OR t.deleted_at < datetime(?2))
+    AND NOT (
+        t.parent_transaction_id IS NOT NULL
+        AND EXISTS (
+            SELECT 1 FROM transactions p
+            WHERE p.id = t.parent_transaction_id AND p.deleted_at IS NULL
+        )
+        AND t.transaction_date = (
+            SELECT MAX(o.transaction_date) FROM transactions o
+            WHERE o.parent_transaction_id = t.parent_transaction_id
+        )
+    )
+`
+
+type ListPurgeableTransactionsParams struct {
+	CreatedBy string      `json:"created_by"`
+	Before    interface{} `json:"before"`
+}
+
+func (q *Queries) ListPurgeableTransactions(ctx context.Context, arg ListPurgeableTransactionsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableTransactions, arg.CreatedBy, arg.Before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecurringTransactions = `-- name: ListRecurringTransactions :many
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
+WHERE deleted_at IS NULL
+    AND is_recurring = TRUE
+    AND recurring_frequency IS NOT NULL
+    AND parent_transaction_id IS NULL
+ORDER BY transaction_date ASC
+`
+
+func (q *Queries) ListRecurringTransactions(ctx context.Context) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listRecurringTransactions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Description,
+			&i.Amount,
+			&i.TransactionDate,
+			&i.CategoryID,
+			&i.Tags,
+			&i.CustomerVendor,
+			&i.PaymentMethodID,
+			&i.PaymentStatus,
+			&i.ReferenceNumber,
+			&i.InvoiceNumber,
+			&i.Notes,
+			&i.Attachments,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.DueAmount,
+			&i.NetAmount,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.IsRecurring,
+			&i.RecurringFrequency,
+			&i.RecurringEndDate,
+			&i.ParentTransactionID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransactions = `-- name: ListTransactions :many
-SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at FROM transactions
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
 WHERE deleted_at IS NULL
     AND created_by = ?1
     AND (?2 = '' OR transaction_date >= ?2)
-    AND (?3 = '' OR transaction_date <= ?3)
+    AND (?3 = '' OR transaction_date < date(?3, '+1 day'))
     AND (?4 = '' OR type = ?4 OR ?4 LIKE '%' || type || '%')
     AND (?5 = '' OR category_id = ?5 OR ?5 LIKE '%' || category_id || '%')
     AND (?6 = '' OR payment_status = ?6 OR ?6 LIKE '%' || payment_status || '%')
     AND (?7 = '' OR payment_method_id = ?7 OR ?7 LIKE '%' || payment_method_id || '%')
-    AND (?8 = '' OR customer_vendor LIKE '%' || ?8 || '%')
-    AND (?9 = '' OR description LIKE '%' || ?9 || '%')
-    AND (?10 = 0 OR due_amount >= ?10)
-    AND (?11 = 0 OR due_amount <= ?11)
-ORDER BY transaction_date DESC, created_at DESC
-LIMIT ?13 OFFSET ?12
+    AND (?8 = '' OR contact_id = ?8)
+    AND (?9 = '' OR account_id = ?9)
+    AND (?10 = '' OR customer_vendor LIKE '%' || ?10 || '%')
+    AND (?11 = '' OR description LIKE '%' || ?11 || '%')
+    AND (?12 = 0 OR due_amount >= ROUND(?12 * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
+    AND (?13 = 0 OR due_amount <= ROUND(?13 * COALESCE((SELECT scale FROM currencies WHERE code = transactions.currency), 100)))
+ORDER BY transaction_date DESC, created_at DESC, id DESC
+LIMIT ?15 OFFSET ?14
 `
 
 type ListTransactionsParams struct {
@@ -733,6 +1393,8 @@ type ListTransactionsParams struct {
 	CategoryFilter       interface{} `json:"category_filter"`
 	PaymentStatusFilter  interface{} `json:"payment_status_filter"`
 	PaymentMethodFilter  interface{} `json:"payment_method_filter"`
+	ContactFilter        interface{} `json:"contact_filter"`
+	AccountFilter        interface{} `json:"account_filter"`
 	CustomerVendorSearch interface{} `json:"customer_vendor_search"`
 	DescriptionSearch    interface{} `json:"description_search"`
 	MinDueAmount         interface{} `json:"min_due_amount"`
@@ -750,6 +1412,8 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 		arg.CategoryFilter,
 		arg.PaymentStatusFilter,
 		arg.PaymentMethodFilter,
+		arg.ContactFilter,
+		arg.AccountFilter,
 		arg.CustomerVendorSearch,
 		arg.DescriptionSearch,
 		arg.MinDueAmount,
@@ -793,6 +1457,10 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -807,42 +1475,243 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 	return items, nil
 }
 
+const listTransactionsByContact = `-- name: ListTransactionsByContact :many
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
+WHERE contact_id = ?
+ORDER BY transaction_date ASC, created_at ASC
+`
+
+func (q *Queries) ListTransactionsByContact(ctx context.Context, contactID sql.NullString) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactionsByContact, contactID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transaction{}
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Description,
+			&i.Amount,
+			&i.TransactionDate,
+			&i.CategoryID,
+			&i.Tags,
+			&i.CustomerVendor,
+			&i.PaymentMethodID,
+			&i.PaymentStatus,
+			&i.ReferenceNumber,
+			&i.InvoiceNumber,
+			&i.Notes,
+			&i.Attachments,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.DueAmount,
+			&i.NetAmount,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.IsRecurring,
+			&i.RecurringFrequency,
+			&i.RecurringEndDate,
+			&i.ParentTransactionID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeTransaction = `-- name: PurgeTransaction :execrows
+DELETE FROM transactions
+WHERE id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeTransaction(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeTransaction, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreTransaction = `-- name: RestoreTransaction :execrows
+UPDATE transactions
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreTransaction(ctx context.Context, id string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreTransaction, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const searchTransactions = `-- name: SearchTransactions :many
-SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at FROM transactions
+SELECT t.id, t.type, t.description, t.amount, t.transaction_date, t.category_id, t.tags, t.customer_vendor, t.payment_method_id, t.payment_status, t.reference_number, t.invoice_number, t.notes, t.attachments, t.tax_amount, t.discount_amount, t.due_amount, t.net_amount, t.currency, t.exchange_rate, t.is_recurring, t.recurring_frequency, t.recurring_end_date, t.parent_transaction_id, t.created_by, t.created_at, t.updated_at, t.deleted_at, t.due_date, t.contact_id, t.account_id, t.cleared_status,
+    CAST(snippet(transactions_fts, -1, char(2), char(3), '…', 12) AS TEXT) AS snippet,
+    CAST(bm25(transactions_fts, 4.0, 3.0, 2.0, 2.0, 1.0) AS REAL) AS score
+FROM transactions_fts
+JOIN transactions t ON t.rowid = transactions_fts.rowid
+WHERE transactions_fts MATCH CAST(?1 AS TEXT)
+    AND t.deleted_at IS NULL
+    AND t.created_by = ?2
+ORDER BY score, t.transaction_date DESC, t.id DESC
+LIMIT ?4 OFFSET ?3
+`
+
+type SearchTransactionsParams struct {
+	Query     string `json:"query"`
+	CreatedBy string `json:"created_by"`
+	Offset    int64  `json:"offset"`
+	Limit     int64  `json:"limit"`
+}
+
+type SearchTransactionsRow struct {
+	ID                  string          `json:"id"`
+	Type                string          `json:"type"`
+	Description         string          `json:"description"`
+	Amount              int64           `json:"amount"`
+	TransactionDate     time.Time       `json:"transaction_date"`
+	CategoryID          sql.NullString  `json:"category_id"`
+	Tags                sql.NullString  `json:"tags"`
+	CustomerVendor      sql.NullString  `json:"customer_vendor"`
+	PaymentMethodID     sql.NullString  `json:"payment_method_id"`
+	PaymentStatus       sql.NullString  `json:"payment_status"`
+	ReferenceNumber     sql.NullString  `json:"reference_number"`
+	InvoiceNumber       sql.NullString  `json:"invoice_number"`
+	Notes               sql.NullString  `json:"notes"`
+	Attachments         sql.NullString  `json:"attachments"`
+	TaxAmount           sql.NullInt64   `json:"tax_amount"`
+	DiscountAmount      sql.NullInt64   `json:"discount_amount"`
+	DueAmount           sql.NullInt64   `json:"due_amount"`
+	NetAmount           sql.NullInt64   `json:"net_amount"`
+	Currency            sql.NullString  `json:"currency"`
+	ExchangeRate        sql.NullFloat64 `json:"exchange_rate"`
+	IsRecurring         sql.NullBool    `json:"is_recurring"`
+	RecurringFrequency  sql.NullString  `json:"recurring_frequency"`
+	RecurringEndDate    sql.NullTime    `json:"recurring_end_date"`
+	ParentTransactionID sql.NullString  `json:"parent_transaction_id"`
+	CreatedBy           string          `json:"created_by"`
+	CreatedAt           sql.NullTime    `json:"created_at"`
+	UpdatedAt           sql.NullTime    `json:"updated_at"`
+	DeletedAt           sql.NullTime    `json:"deleted_at"`
+	DueDate             sql.NullTime    `json:"due_date"`
+	ContactID           sql.NullString  `json:"contact_id"`
+	AccountID           sql.NullString  `json:"account_id"`
+	ClearedStatus       string          `json:"cleared_status"`
+	Snippet             string          `json:"snippet"`
+	Score               float64         `json:"score"`
+}
+
+func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransactions,
+		arg.Query,
+		arg.CreatedBy,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchTransactionsRow{}
+	for rows.Next() {
+		var i SearchTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Description,
+			&i.Amount,
+			&i.TransactionDate,
+			&i.CategoryID,
+			&i.Tags,
+			&i.CustomerVendor,
+			&i.PaymentMethodID,
+			&i.PaymentStatus,
+			&i.ReferenceNumber,
+			&i.InvoiceNumber,
+			&i.Notes,
+			&i.Attachments,
+			&i.TaxAmount,
+			&i.DiscountAmount,
+			&i.DueAmount,
+			&i.NetAmount,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.IsRecurring,
+			&i.RecurringFrequency,
+			&i.RecurringEndDate,
+			&i.ParentTransactionID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
+			&i.Snippet,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransactionsLike = `-- name: SearchTransactionsLike :many
+SELECT id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status FROM transactions
 WHERE deleted_at IS NULL
-    AND created_by = ?
+    AND created_by = ?1
     AND (
-        description LIKE '%' || ? || '%'
-        OR customer_vendor LIKE '%' || ? || '%'
-        OR reference_number LIKE '%' || ? || '%'
-        OR invoice_number LIKE '%' || ? || '%'
-        OR notes LIKE '%' || ? || '%'
+        description LIKE '%' || ?2 || '%'
+        OR customer_vendor LIKE '%' || ?2 || '%'
+        OR reference_number LIKE '%' || ?2 || '%'
+        OR invoice_number LIKE '%' || ?2 || '%'
+        OR notes LIKE '%' || ?2 || '%'
     )
 ORDER BY transaction_date DESC, created_at DESC
-LIMIT ? OFFSET ?
+LIMIT ?4 OFFSET ?3
 `
 
-type SearchTransactionsParams struct {
-	CreatedBy string         `json:"created_by"`
-	Column2   sql.NullString `json:"column_2"`
-	Column3   sql.NullString `json:"column_3"`
-	Column4   sql.NullString `json:"column_4"`
-	Column5   sql.NullString `json:"column_5"`
-	Column6   sql.NullString `json:"column_6"`
-	Limit     int64          `json:"limit"`
-	Offset    int64          `json:"offset"`
+type SearchTransactionsLikeParams struct {
+	CreatedBy string `json:"created_by"`
+	Search    string `json:"search"`
+	Offset    int64  `json:"offset"`
+	Limit     int64  `json:"limit"`
 }
 
-func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, searchTransactions,
+func (q *Queries) SearchTransactionsLike(ctx context.Context, arg SearchTransactionsLikeParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransactionsLike,
 		arg.CreatedBy,
-		arg.Column2,
-		arg.Column3,
-		arg.Column4,
-		arg.Column5,
-		arg.Column6,
-		arg.Limit,
+		arg.Search,
 		arg.Offset,
+		arg.Limit,
 	)
 	if err != nil {
 		return nil, err
@@ -880,6 +1749,10 @@ func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactions
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.DueDate,
+			&i.ContactID,
+			&i.AccountID,
+			&i.ClearedStatus,
 		); err != nil {
 			return nil, err
 		}
@@ -894,6 +1767,46 @@ func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactions
 	return items, nil
 }
 
+const setTransactionContact = `-- name: SetTransactionContact :exec
+UPDATE transactions
+SET
+    contact_id = ?,
+    customer_vendor = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type SetTransactionContactParams struct {
+	ContactID      sql.NullString `json:"contact_id"`
+	CustomerVendor sql.NullString `json:"customer_vendor"`
+	ID             string         `json:"id"`
+}
+
+func (q *Queries) SetTransactionContact(ctx context.Context, arg SetTransactionContactParams) error {
+	_, err := q.db.ExecContext(ctx, setTransactionContact, arg.ContactID, arg.CustomerVendor, arg.ID)
+	return err
+}
+
+const setTransactionSettlement = `-- name: SetTransactionSettlement :exec
+UPDATE transactions
+SET
+    due_amount = ?,
+    payment_status = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type SetTransactionSettlementParams struct {
+	DueAmount     sql.NullInt64  `json:"due_amount"`
+	PaymentStatus sql.NullString `json:"payment_status"`
+	ID            string         `json:"id"`
+}
+
+func (q *Queries) SetTransactionSettlement(ctx context.Context, arg SetTransactionSettlementParams) error {
+	_, err := q.db.ExecContext(ctx, setTransactionSettlement, arg.DueAmount, arg.PaymentStatus, arg.ID)
+	return err
+}
+
 const updateTransaction = `-- name: UpdateTransaction :one
 UPDATE transactions
 SET
@@ -918,15 +1831,18 @@ SET
     is_recurring = ?,
     recurring_frequency = ?,
     recurring_end_date = ?,
+    due_date = ?,
+    contact_id = ?,
+    account_id = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ? AND deleted_at IS NULL
-RETURNING id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at
+RETURNING id, type, description, amount, transaction_date, category_id, tags, customer_vendor, payment_method_id, payment_status, reference_number, invoice_number, notes, attachments, tax_amount, discount_amount, due_amount, net_amount, currency, exchange_rate, is_recurring, recurring_frequency, recurring_end_date, parent_transaction_id, created_by, created_at, updated_at, deleted_at, due_date, contact_id, account_id, cleared_status
 `
 
 type UpdateTransactionParams struct {
 	Type               string          `json:"type"`
 	Description        string          `json:"description"`
-	Amount             float64         `json:"amount"`
+	Amount             int64           `json:"amount"`
 	TransactionDate    time.Time       `json:"transaction_date"`
 	CategoryID         sql.NullString  `json:"category_id"`
 	Tags               sql.NullString  `json:"tags"`
@@ -937,14 +1853,17 @@ type UpdateTransactionParams struct {
 	InvoiceNumber      sql.NullString  `json:"invoice_number"`
 	Notes              sql.NullString  `json:"notes"`
 	Attachments        sql.NullString  `json:"attachments"`
-	TaxAmount          sql.NullFloat64 `json:"tax_amount"`
-	DiscountAmount     sql.NullFloat64 `json:"discount_amount"`
-	DueAmount          sql.NullFloat64 `json:"due_amount"`
+	TaxAmount          sql.NullInt64   `json:"tax_amount"`
+	DiscountAmount     sql.NullInt64   `json:"discount_amount"`
+	DueAmount          sql.NullInt64   `json:"due_amount"`
 	Currency           sql.NullString  `json:"currency"`
 	ExchangeRate       sql.NullFloat64 `json:"exchange_rate"`
 	IsRecurring        sql.NullBool    `json:"is_recurring"`
 	RecurringFrequency sql.NullString  `json:"recurring_frequency"`
 	RecurringEndDate   sql.NullTime    `json:"recurring_end_date"`
+	DueDate            sql.NullTime    `json:"due_date"`
+	ContactID          sql.NullString  `json:"contact_id"`
+	AccountID          sql.NullString  `json:"account_id"`
 	ID                 string          `json:"id"`
 }
 
@@ -971,6 +1890,9 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		arg.IsRecurring,
 		arg.RecurringFrequency,
 		arg.RecurringEndDate,
+		arg.DueDate,
+		arg.ContactID,
+		arg.AccountID,
 		arg.ID,
 	)
 	var i Transaction
@@ -1003,6 +1925,10 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.DueDate,
+		&i.ContactID,
+		&i.AccountID,
+		&i.ClearedStatus,
 	)
 	return i, err
 }
@@ -0,0 +1,462 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// AccountService manages the bank, cash and card accounts money moves in
+// and out of, and transfers between them
+type AccountService struct {
+	db         *database.Database
+	currencies *CurrencyService
+	rates      *ExchangeRateService
+}
+
+func NewAccountService(db *database.Database) *AccountService {
+	return &AccountService{
+		db:         db,
+		currencies: NewCurrencyService(db),
+		rates:      NewExchangeRateService(db),
+	}
+}
+
+// AccountParams describes an account. OpeningBalance, in the account's
+// currency, is what it held at the start of OpeningDate.
+type AccountParams struct {
+	Name           string  `json:"name"`
+	Type           string  `json:"type"` // bank, cash, card or other
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"opening_balance"`
+	OpeningDate    string  `json:"opening_date"` // defaults to today
+	IsActive       bool    `json:"is_active"`
+	CreatedBy      string  `json:"created_by"`
+}
+
+// TransferParams describes a transfer between two accounts. Amount leaves
+// the first account in its currency; ToAmount is what arrives in the
+// second and is needed when their currencies differ.
+type TransferParams struct {
+	FromAccount  string  `json:"from_account"`
+	ToAccount    string  `json:"to_account"`
+	Amount       float64 `json:"amount"`
+	ToAmount     float64 `json:"to_amount,omitempty"`
+	TransferDate string  `json:"transfer_date"` // defaults to today
+	Description  string  `json:"description,omitempty"`
+	Reference    string  `json:"reference,omitempty"`
+	CreatedBy    string  `json:"created_by"`
+}
+
+// AccountBalance is what an account held at the end of a day, in its own
+// currency. Income and expenses are what was actually paid on transactions
+// (their net amount), counted from the opening date.
+type AccountBalance struct {
+	AccountID      string  `json:"account_id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Currency       string  `json:"currency"`
+	IsActive       bool    `json:"is_active"`
+	OpeningBalance float64 `json:"opening_balance"`
+	Income         float64 `json:"income"`
+	Expenses       float64 `json:"expenses"`
+	TransfersIn    float64 `json:"transfers_in"`
+	TransfersOut   float64 `json:"transfers_out"`
+	Balance        float64 `json:"balance"`
+}
+
+// AccountBalances lists the balance of every account of a user, with their
+// total in the reporting currency
+type AccountBalances struct {
+	AsOf     string           `json:"as_of"`
+	Currency string           `json:"currency"`
+	Accounts []AccountBalance `json:"accounts"`
+	Total    float64          `json:"total"`
+}
+
+// CreateAccount creates an active account
+func (s *AccountService) CreateAccount(ctx context.Context, params AccountParams) (*db.Account, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	args, err := s.validateAccount(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, params.CreatedBy, args.Name, ""); err != nil {
+		return nil, err
+	}
+
+	account, err := s.db.Queries().CreateAccount(ctx, db.CreateAccountParams{
+		Name:           args.Name,
+		Type:           args.Type,
+		Currency:       args.Currency,
+		OpeningBalance: args.OpeningBalance,
+		OpeningDate:    args.OpeningDate,
+		IsActive:       true,
+		CreatedBy:      params.CreatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+	return &account, nil
+}
+
+// GetAccount retrieves an account of createdBy by ID; those of other users
+// are not found
+func (s *AccountService) GetAccount(ctx context.Context, createdBy, id string) (*db.Account, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	account, err := s.db.Queries().GetAccount(ctx, id)
+	if err == sql.ErrNoRows || err == nil && account.CreatedBy != createdBy {
+		return nil, fmt.Errorf("account not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return &account, nil
+}
+
+// ListAccounts lists the accounts of a user by name
+func (s *AccountService) ListAccounts(ctx context.Context, createdBy string) ([]db.Account, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	accounts, err := s.db.Queries().ListAccounts(ctx, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// UpdateAccount updates an account. Its currency cannot change once it has
+// transactions or transfers.
+func (s *AccountService) UpdateAccount(ctx context.Context, createdBy, id string, params AccountParams) (*db.Account, error) {
+	current, err := s.GetAccount(ctx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	args, err := s.validateAccount(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, current.CreatedBy, args.Name, id); err != nil {
+		return nil, err
+	}
+	if args.Currency != current.Currency {
+		uses, err := s.db.Queries().CountAccountUses(ctx, toSqlNullString(id))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check account: %w", err)
+		}
+		if uses > 0 {
+			return nil, fmt.Errorf("cannot change the currency of an account that is in use")
+		}
+	}
+
+	account, err := s.db.Queries().UpdateAccount(ctx, db.UpdateAccountParams{
+		ID:             id,
+		Name:           args.Name,
+		Type:           args.Type,
+		Currency:       args.Currency,
+		OpeningBalance: args.OpeningBalance,
+		OpeningDate:    args.OpeningDate,
+		IsActive:       args.IsActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+	return &account, nil
+}
+
+// DeleteAccount deletes an account nothing uses; accounts in use can be
+// deactivated instead
+func (s *AccountService) DeleteAccount(ctx context.Context, createdBy, id string) error {
+	if _, err := s.GetAccount(ctx, createdBy, id); err != nil {
+		return err
+	}
+	uses, err := s.db.Queries().CountAccountUses(ctx, toSqlNullString(id))
+	if err != nil {
+		return fmt.Errorf("failed to check account: %w", err)
+	}
+	if uses > 0 {
+		return fmt.Errorf("cannot delete an account with transactions or transfers; deactivate it instead")
+	}
+	if err := s.db.Queries().DeleteAccount(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	return nil
+}
+
+// CreateTransfer moves money between two accounts of the same user
+func (s *AccountService) CreateTransfer(ctx context.Context, params TransferParams) (*db.Transfer, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	if params.FromAccount == params.ToAccount {
+		return nil, fmt.Errorf("cannot transfer to the same account")
+	}
+	from, err := s.GetAccount(ctx, params.CreatedBy, params.FromAccount)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetAccount(ctx, params.CreatedBy, params.ToAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	transferDate := truncateToDate(time.Now())
+	if params.TransferDate != "" {
+		if transferDate, err = time.Parse("2006-01-02", params.TransferDate); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.TransferDate)
+		}
+	}
+
+	amount := toMinorUnits(params.Amount, s.currencies.Exponent(ctx, from.Currency))
+	if amount <= 0 {
+		return nil, fmt.Errorf("transfer amount must be positive")
+	}
+	toAmount := amount
+	if from.Currency != to.Currency {
+		if params.ToAmount <= 0 {
+			return nil, fmt.Errorf("the amount received in %s is required", to.Currency)
+		}
+		toAmount = toMinorUnits(params.ToAmount, s.currencies.Exponent(ctx, to.Currency))
+	}
+
+	transfer, err := s.db.Queries().CreateTransfer(ctx, db.CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		TransferDate:  transferDate,
+		Description:   toSqlNullString(params.Description),
+		Reference:     toSqlNullString(params.Reference),
+		CreatedBy:     params.CreatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+// ListTransfers lists the transfers of a user, newest first, optionally
+// only those in or out of one account
+func (s *AccountService) ListTransfers(ctx context.Context, createdBy, accountID string) ([]db.Transfer, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	transfers, err := s.db.Queries().ListTransfers(ctx, db.ListTransfersParams{
+		CreatedBy: createdBy,
+		AccountID: accountID,
+		ToDate:    "",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// DeleteTransfer deletes a transfer of createdBy
+func (s *AccountService) DeleteTransfer(ctx context.Context, createdBy, id string) error {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	transfer, err := s.db.Queries().GetTransfer(ctx, id)
+	if err == sql.ErrNoRows || err == nil && transfer.CreatedBy != createdBy {
+		return fmt.Errorf("transfer not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get transfer: %w", err)
+	}
+
+	n, err := s.db.Queries().DeleteTransfer(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete transfer: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("transfer not found")
+	}
+	return nil
+}
+
+// GetAccountBalances computes what each account of a user held at the end
+// of asOf (today when empty): its opening balance, plus what was paid on
+// income and sales, less what was paid on expenses and purchases, plus
+// transfers in and less transfers out. Cancelled and deleted transactions,
+// and anything before an account's opening date, do not count.
+// Transactions in another currency than their account are converted on
+// their date.
+func (s *AccountService) GetAccountBalances(ctx context.Context, createdBy, asOf string) (*AccountBalances, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	date := truncateToDate(time.Now())
+	if asOf != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", asOf); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", asOf)
+		}
+	}
+	day := date.Format("2006-01-02")
+
+	accounts, err := s.ListAccounts(ctx, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := s.db.Queries().GetAccountActivity(ctx, db.GetAccountActivityParams{
+		CreatedBy: createdBy,
+		AsOf:      day,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account activity: %w", err)
+	}
+	transfers, err := s.db.Queries().ListTransfers(ctx, db.ListTransfersParams{
+		CreatedBy: createdBy,
+		AccountID: "",
+		ToDate:    day,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+
+	// Amounts in minor units of each account's currency
+	type totals struct {
+		income, expenses, in, out int64
+	}
+	byID := make(map[string]*db.Account, len(accounts))
+	sums := make(map[string]*totals, len(accounts))
+	converters := make(map[string]*currencyConverter)
+	for i := range accounts {
+		byID[accounts[i].ID] = &accounts[i]
+		sums[accounts[i].ID] = &totals{}
+	}
+
+	for _, row := range activity {
+		account := byID[row.AccountID.String]
+		if account == nil {
+			continue
+		}
+		converter := converters[account.Currency]
+		if converter == nil {
+			if converter, err = s.rates.newConverter(ctx, createdBy, account.Currency); err != nil {
+				return nil, err
+			}
+			converters[account.Currency] = converter
+		}
+		amount, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+		if err != nil {
+			return nil, err
+		}
+		switch row.Type {
+		case "income", "sale":
+			sums[account.ID].income += amount
+		default:
+			sums[account.ID].expenses += amount
+		}
+	}
+	for _, t := range transfers {
+		if from := byID[t.FromAccountID]; from != nil && !t.TransferDate.Before(truncateToDate(from.OpeningDate)) {
+			sums[from.ID].out += t.Amount
+		}
+		if to := byID[t.ToAccountID]; to != nil && !t.TransferDate.Before(truncateToDate(to.OpeningDate)) {
+			sums[to.ID].in += t.ToAmount
+		}
+	}
+
+	total, err := s.rates.newConverter(ctx, createdBy, "")
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountBalances{
+		AsOf:     day,
+		Currency: total.target,
+		Accounts: []AccountBalance{},
+	}
+	var sum int64
+	for _, a := range accounts {
+		t := sums[a.ID]
+		balance := a.OpeningBalance + t.income - t.expenses + t.in - t.out
+		exponent := s.currencies.Exponent(ctx, a.Currency)
+		result.Accounts = append(result.Accounts, AccountBalance{
+			AccountID:      a.ID,
+			Name:           a.Name,
+			Type:           a.Type,
+			Currency:       a.Currency,
+			IsActive:       a.IsActive,
+			OpeningBalance: fromMinorUnits(a.OpeningBalance, exponent),
+			Income:         fromMinorUnits(t.income, exponent),
+			Expenses:       fromMinorUnits(t.expenses, exponent),
+			TransfersIn:    fromMinorUnits(t.in, exponent),
+			TransfersOut:   fromMinorUnits(t.out, exponent),
+			Balance:        fromMinorUnits(balance, exponent),
+		})
+
+		converted, err := total.convert(ctx, balance, a.Currency, date, sql.NullFloat64{})
+		if err != nil {
+			return nil, err
+		}
+		sum += converted
+	}
+	result.Total = fromMinorUnits(sum, s.currencies.Exponent(ctx, total.target))
+	return result, nil
+}
+
+// checkName rejects names, in any case, used by another of the user's accounts
+func (s *AccountService) checkName(ctx context.Context, createdBy, name, id string) error {
+	accounts, err := s.ListAccounts(ctx, createdBy)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		if a.ID != id && strings.EqualFold(a.Name, name) {
+			return fmt.Errorf("an account named %q already exists", a.Name)
+		}
+	}
+	return nil
+}
+
+// validateAccount checks account params and turns them into stored values
+func (s *AccountService) validateAccount(ctx context.Context, params AccountParams) (db.CreateAccountParams, error) {
+	if params.Name == "" {
+		return db.CreateAccountParams{}, fmt.Errorf("account name is required")
+	}
+	if params.Type == "" {
+		params.Type = "bank"
+	}
+	switch params.Type {
+	case "bank", "cash", "card", "other":
+	default:
+		return db.CreateAccountParams{}, fmt.Errorf("invalid account type %q", params.Type)
+	}
+
+	currency := normalizeCurrency(params.Currency)
+	if _, err := s.db.Queries().GetCurrency(ctx, currency); err != nil {
+		if err == sql.ErrNoRows {
+			return db.CreateAccountParams{}, fmt.Errorf("unknown currency %s", currency)
+		}
+		return db.CreateAccountParams{}, fmt.Errorf("failed to get currency: %w", err)
+	}
+
+	openingDate := truncateToDate(time.Now())
+	if params.OpeningDate != "" {
+		var err error
+		if openingDate, err = time.Parse("2006-01-02", params.OpeningDate); err != nil {
+			return db.CreateAccountParams{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.OpeningDate)
+		}
+	}
+
+	return db.CreateAccountParams{
+		Name:           params.Name,
+		Type:           params.Type,
+		Currency:       currency,
+		OpeningBalance: toMinorUnits(params.OpeningBalance, s.currencies.Exponent(ctx, currency)),
+		OpeningDate:    openingDate,
+		IsActive:       params.IsActive,
+	}, nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "cashflow/internal/db/sqlc"
+)
+
+// Aging report kinds
+const (
+	AgingReceivable = "receivable"
+	AgingPayable    = "payable"
+)
+
+// Aging buckets, by days past due
+const (
+	AgingCurrent = "current"
+	Aging1To30   = "1-30"
+	Aging31To60  = "31-60"
+	Aging61To90  = "61-90"
+	AgingOver90  = "90+"
+)
+
+// agingBuckets lists the buckets in report order
+var agingBuckets = []string{AgingCurrent, Aging1To30, Aging31To60, Aging61To90, AgingOver90}
+
+// AgingParams selects an aging report. Receivables are owed on income and
+// sales, payables on expenses and purchases. A transaction is due on its
+// due date, or TermsDays after its transaction date when it has none.
+// CustomerVendor and Bucket narrow down the details of a report.
+type AgingParams struct {
+	CreatedBy      string `json:"created_by"`
+	Kind           string `json:"kind"`
+	AsOf           string `json:"as_of,omitempty"` // defaults to today
+	TermsDays      int    `json:"terms_days,omitempty"`
+	Currency       string `json:"currency,omitempty"` // reporting currency, defaults to the base currency
+	CustomerVendor string `json:"customer_vendor,omitempty"`
+	Bucket         string `json:"bucket,omitempty"`
+}
+
+// AgingBucketTotal is the amount outstanding in one bucket
+type AgingBucketTotal struct {
+	Bucket string  `json:"bucket"`
+	Count  int     `json:"count"`
+	Total  float64 `json:"total"`
+}
+
+// AgingCounterparty is what one customer or vendor owes or is owed, with
+// one amount per bucket in the order of the report's buckets
+type AgingCounterparty struct {
+	Name    string    `json:"name"`
+	Amounts []float64 `json:"amounts"`
+	Count   int       `json:"count"`
+	Total   float64   `json:"total"`
+}
+
+// AgingReport groups outstanding amounts by customer or vendor and by how
+// long they are overdue. Amounts without an exchange rate into the
+// reporting currency are left out and listed in Unconverted.
+type AgingReport struct {
+	Kind           string              `json:"kind"`
+	AsOf           string              `json:"as_of"`
+	Currency       string              `json:"currency"`
+	Buckets        []AgingBucketTotal  `json:"buckets"`
+	Counterparties []AgingCounterparty `json:"counterparties"`
+	Total          float64             `json:"total"`
+	Unconverted    []UnconvertedAmount `json:"unconverted,omitempty"`
+}
+
+// AgingItem is an outstanding transaction. Outstanding is in Currency, the
+// reporting currency or, when the transaction has no exchange rate into it,
+// its own; DaysOverdue is negative while the transaction is not yet due.
+type AgingItem struct {
+	Transaction db.Transaction
+	DueDate     string
+	DaysOverdue int
+	Bucket      string
+	Outstanding float64
+	Currency    string
+}
+
+// agingEntry is an outstanding transaction with its amount in minor units
+// of currency
+type agingEntry struct {
+	transaction db.Transaction
+	dueDate     time.Time
+	daysOverdue int
+	bucket      string
+	outstanding int64
+	currency    string
+}
+
+// GetAging builds an accounts receivable or payable aging report
+func (s *ReportService) GetAging(ctx context.Context, params AgingParams) (*AgingReport, error) {
+	entries, converter, asOf, err := s.agingEntries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	currency := converter.target
+
+	bucketIndex := make(map[string]int, len(agingBuckets))
+	bucketTotals := make([]int64, len(agingBuckets))
+	bucketCounts := make([]int, len(agingBuckets))
+	for i, bucket := range agingBuckets {
+		bucketIndex[bucket] = i
+	}
+
+	// Entries come ordered by customer/vendor
+	type counterparty struct {
+		name    string
+		amounts []int64
+		count   int
+	}
+	var counterparties []*counterparty
+	var total int64
+	for _, e := range entries {
+		if e.currency != currency {
+			continue
+		}
+		name := e.transaction.CustomerVendor.String
+		if len(counterparties) == 0 || counterparties[len(counterparties)-1].name != name {
+			counterparties = append(counterparties, &counterparty{name: name, amounts: make([]int64, len(agingBuckets))})
+		}
+		c := counterparties[len(counterparties)-1]
+		i := bucketIndex[e.bucket]
+		c.amounts[i] += e.outstanding
+		c.count++
+		bucketTotals[i] += e.outstanding
+		bucketCounts[i]++
+		total += e.outstanding
+	}
+
+	exponent := s.currencies.Exponent(ctx, currency)
+	report := &AgingReport{
+		Kind:           params.Kind,
+		AsOf:           asOf.Format("2006-01-02"),
+		Currency:       currency,
+		Counterparties: []AgingCounterparty{},
+		Total:          fromMinorUnits(total, exponent),
+		Unconverted:    converter.unconvertedAmounts(ctx),
+	}
+	for i, bucket := range agingBuckets {
+		report.Buckets = append(report.Buckets, AgingBucketTotal{
+			Bucket: bucket,
+			Count:  bucketCounts[i],
+			Total:  fromMinorUnits(bucketTotals[i], exponent),
+		})
+	}
+	for _, c := range counterparties {
+		row := AgingCounterparty{Name: c.name, Count: c.count, Amounts: make([]float64, len(agingBuckets))}
+		var sum int64
+		for i, amount := range c.amounts {
+			row.Amounts[i] = fromMinorUnits(amount, exponent)
+			sum += amount
+		}
+		row.Total = fromMinorUnits(sum, exponent)
+		report.Counterparties = append(report.Counterparties, row)
+	}
+	return report, nil
+}
+
+// GetAgingDetails lists the outstanding transactions behind an aging
+// report, optionally only those of one customer/vendor or bucket. An empty
+// CustomerVendor is not a filter; transactions without one cannot be
+// picked out on their own.
+func (s *ReportService) GetAgingDetails(ctx context.Context, params AgingParams) ([]AgingItem, error) {
+	entries, _, _, err := s.agingEntries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []AgingItem{}
+	for _, e := range entries {
+		if params.CustomerVendor != "" && e.transaction.CustomerVendor.String != params.CustomerVendor {
+			continue
+		}
+		if params.Bucket != "" && e.bucket != params.Bucket {
+			continue
+		}
+		items = append(items, AgingItem{
+			Transaction: e.transaction,
+			DueDate:     e.dueDate.Format("2006-01-02"),
+			DaysOverdue: e.daysOverdue,
+			Bucket:      e.bucket,
+			Outstanding: fromMinorUnits(e.outstanding, s.currencies.Exponent(ctx, e.currency)),
+			Currency:    e.currency,
+		})
+	}
+	return items, nil
+}
+
+// agingEntries loads the outstanding transactions of an aging report and
+// returns them with the converter into the reporting currency and the as-of
+// date. Those without an exchange rate keep their own currency and are set
+// aside in the converter.
+func (s *ReportService) agingEntries(ctx context.Context, params AgingParams) ([]agingEntry, *currencyConverter, time.Time, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+
+	var types string
+	switch params.Kind {
+	case AgingReceivable:
+		types = "income,sale"
+	case AgingPayable:
+		types = "expense,purchase"
+	default:
+		return nil, nil, time.Time{}, fmt.Errorf("invalid aging report kind %q", params.Kind)
+	}
+	if params.Bucket != "" {
+		valid := false
+		for _, bucket := range agingBuckets {
+			valid = valid || bucket == params.Bucket
+		}
+		if !valid {
+			return nil, nil, time.Time{}, fmt.Errorf("invalid aging bucket %q", params.Bucket)
+		}
+	}
+	if params.TermsDays < 0 {
+		return nil, nil, time.Time{}, fmt.Errorf("payment terms cannot be negative")
+	}
+
+	asOf := truncateToDate(time.Now())
+	if params.AsOf != "" {
+		var err error
+		if asOf, err = time.Parse("2006-01-02", params.AsOf); err != nil {
+			return nil, nil, time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.AsOf)
+		}
+	}
+
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	transactions, err := s.db.Queries().ListOutstandingTransactions(ctx, db.ListOutstandingTransactionsParams{
+		CreatedBy:  params.CreatedBy,
+		TypeFilter: types,
+		AsOf:       asOf.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, nil, time.Time{}, fmt.Errorf("failed to list outstanding transactions: %w", err)
+	}
+
+	entries := make([]agingEntry, 0, len(transactions))
+	for _, t := range transactions {
+		currency := converter.target
+		outstanding, err := converter.convert(ctx, outstandingAmount(&t), t.Currency.String, t.TransactionDate, t.ExchangeRate)
+		if err != nil {
+			if err := converter.setAside(err, t.Currency.String, t.Type, outstandingAmount(&t)); err != nil {
+				return nil, nil, time.Time{}, err
+			}
+			currency, outstanding = normalizeCurrency(t.Currency.String), outstandingAmount(&t)
+		}
+
+		dueDate := truncateToDate(t.TransactionDate).AddDate(0, 0, params.TermsDays)
+		if t.DueDate.Valid {
+			dueDate = truncateToDate(t.DueDate.Time)
+		}
+		days := int(asOf.Sub(dueDate).Hours() / 24)
+		entries = append(entries, agingEntry{
+			transaction: t,
+			dueDate:     dueDate,
+			daysOverdue: days,
+			bucket:      agingBucket(days),
+			outstanding: outstanding,
+			currency:    currency,
+		})
+	}
+	return entries, converter, asOf, nil
+}
+
+// outstandingAmount is what is still owed on a transaction in minor units:
+// its due amount, or all of it while it is pending with no due amount set
+func outstandingAmount(t *db.Transaction) int64 {
+	if t.DueAmount.Int64 > 0 {
+		return t.DueAmount.Int64
+	}
+	if t.PaymentStatus.String == "pending" {
+		return transactionTotal(t)
+	}
+	return 0
+}
+
+// agingBucket returns the bucket of a transaction overdue by days
+func agingBucket(days int) string {
+	switch {
+	case days <= 0:
+		return AgingCurrent
+	case days <= 30:
+		return Aging1To30
+	case days <= 60:
+		return Aging31To60
+	case days <= 90:
+		return Aging61To90
+	}
+	return AgingOver90
+}
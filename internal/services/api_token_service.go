@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// apiTokenPrefix starts every API token, so they are easy to recognize
+const apiTokenPrefix = "cf_"
+
+// APITokenService manages the tokens scripts use to call the local HTTP
+// API on behalf of a user
+type APITokenService struct {
+	db *database.Database
+}
+
+func NewAPITokenService(db *database.Database) *APITokenService {
+	return &APITokenService{db: db}
+}
+
+// NewAPIToken is a token just created. Token is only ever available here;
+// only its hash is stored.
+type NewAPIToken struct {
+	db.ApiToken
+	Token string `json:"token"`
+}
+
+// CreateAPIToken creates a token for a user
+func (s *APITokenService) CreateAPIToken(ctx context.Context, userID, name string) (*NewAPIToken, error) {
+	if userID == "" {
+		userID = DefaultUserID
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("token name is required")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+
+	created, err := s.db.Queries().CreateAPIToken(ctx, db.CreateAPITokenParams{
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   hashAPIToken(token),
+		TokenPrefix: token[:len(apiTokenPrefix)+8],
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+	return &NewAPIToken{ApiToken: created, Token: token}, nil
+}
+
+// ListAPITokens lists the tokens of a user, newest first
+func (s *APITokenService) ListAPITokens(ctx context.Context, userID string) ([]db.ApiToken, error) {
+	if userID == "" {
+		userID = DefaultUserID
+	}
+	tokens, err := s.db.Queries().ListAPITokens(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes a token of a user; it stops working right away
+func (s *APITokenService) RevokeAPIToken(ctx context.Context, userID, id string) error {
+	if userID == "" {
+		userID = DefaultUserID
+	}
+	n, err := s.db.Queries().DeleteAPIToken(ctx, db.DeleteAPITokenParams{ID: id, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("token not found")
+	}
+	return nil
+}
+
+// Authenticate returns the user a token belongs to and notes when it was
+// last used
+func (s *APITokenService) Authenticate(ctx context.Context, token string) (string, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return "", fmt.Errorf("invalid token")
+	}
+	found, err := s.db.Queries().GetAPITokenByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("invalid token")
+		}
+		return "", fmt.Errorf("failed to check token: %w", err)
+	}
+	if err := s.db.Queries().TouchAPIToken(ctx, found.ID); err != nil {
+		return "", fmt.Errorf("failed to update token: %w", err)
+	}
+	return found.UserID, nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// maxAttachmentSize is the largest file that can be attached
+const maxAttachmentSize = 50 << 20
+
+// AttachmentURLPrefix is the asset server path attachments are served under
+const AttachmentURLPrefix = "/attachments/"
+
+// AttachmentService stores files attached to transactions. File contents
+// are kept once per sha256 under ~/.cashflow/attachments; the attachments
+// table maps them to transactions with their original name and MIME type.
+type AttachmentService struct {
+	db *database.Database
+
+	// mu keeps garbage collection from removing a blob that is being added
+	mu sync.Mutex
+}
+
+func NewAttachmentService(db *database.Database) *AttachmentService {
+	return &AttachmentService{db: db}
+}
+
+// AddAttachment copies the file at path into the store and attaches it to
+// a transaction of createdBy
+func (s *AttachmentService) AddAttachment(ctx context.Context, createdBy, transactionID, path string) (*db.Attachment, error) {
+	if _, err := getOwnedTransaction(ctx, s.db.Queries(), createdBy, transactionID); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a file", filepath.Base(path))
+	}
+	if info.Size() > maxAttachmentSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", filepath.Base(path), maxAttachmentSize>>20)
+	}
+
+	// Sniff the content type in case the extension is unknown
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	head = head[:n]
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(head)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash, size, err := s.store(io.MultiReader(bytes.NewReader(head), file))
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.db.Queries().CreateAttachment(ctx, db.CreateAttachmentParams{
+		TransactionID: transactionID,
+		FileName:      filepath.Base(path),
+		MimeType:      mimeType,
+		SizeBytes:     size,
+		Sha256:        hash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// GetAttachment retrieves attachment metadata by ID
+func (s *AttachmentService) GetAttachment(ctx context.Context, id string) (*db.Attachment, error) {
+	attachment, err := s.db.Queries().GetAttachment(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("attachment not found")
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+// ListAttachments lists the attachments of a transaction of createdBy,
+// deleted ones included
+func (s *AttachmentService) ListAttachments(ctx context.Context, createdBy, transactionID string) ([]db.Attachment, error) {
+	if _, err := getOwnedTransactionWithDeleted(ctx, s.db.Queries(), createdBy, transactionID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.db.Queries().ListAttachmentsByTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// RemoveAttachment detaches a file from a transaction of createdBy,
+// deleting the stored file if nothing else uses it
+func (s *AttachmentService) RemoveAttachment(ctx context.Context, createdBy, id string) error {
+	attachment, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := getOwnedTransactionWithDeleted(ctx, s.db.Queries(), createdBy, attachment.TransactionID); err != nil {
+		return err
+	}
+	if err := s.db.Queries().DeleteAttachment(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	_, err = s.CollectGarbage(ctx)
+	return err
+}
+
+// CollectGarbage deletes stored files no attachment refers to, such as
+// those of purged transactions, and returns how many were removed.
+// Attachments of soft-deleted transactions are kept so they can be restored.
+func (s *AttachmentService) CollectGarbage(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	root, err := s.root()
+	if err != nil {
+		return 0, err
+	}
+	hashes, err := s.db.Queries().ListAttachmentHashes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	inUse := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		inUse[hash] = true
+	}
+
+	removed := 0
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || inUse[d.Name()] {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		// Drop the shard directory once it is empty
+		if dir := filepath.Dir(path); dir != root {
+			os.Remove(dir)
+		}
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to collect attachments: %w", err)
+	}
+	return removed, nil
+}
+
+// Handler serves attachment contents by ID under AttachmentURLPrefix, for
+// use as the Wails asset server handler. Only attachments of transactions of
+// the user currentUser returns are found.
+func (s *AttachmentService) Handler(currentUser func() string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutPrefix(r.URL.Path, AttachmentURLPrefix)
+		if !ok || id == "" || strings.Contains(id, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		attachment, err := s.GetAttachment(r.Context(), id)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := getOwnedTransactionWithDeleted(r.Context(), s.db.Queries(), currentUser(), attachment.TransactionID); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		path, err := s.blobPath(attachment.Sha256)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", attachment.MimeType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+		http.ServeContent(w, r, attachment.FileName, info.ModTime(), file)
+	})
+}
+
+// AttachmentURL returns the asset server URL of an attachment
+func AttachmentURL(id string) string {
+	return AttachmentURLPrefix + id
+}
+
+// store writes r into the store and returns its hash and size. Callers hold mu.
+func (s *AttachmentService) store(r io.Reader) (string, int64, error) {
+	root, err := s.root()
+	if err != nil {
+		return "", 0, err
+	}
+
+	tmp, err := os.CreateTemp(root, "upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	path, err := s.blobPath(hash)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, fmt.Errorf("failed to store attachment: %w", err)
+	}
+	return hash, size, nil
+}
+
+// blobPath returns where the file with the given hash is stored, sharded by
+// the first two hex digits
+func (s *AttachmentService) blobPath(hash string) (string, error) {
+	if len(hash) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid attachment hash %q", hash)
+	}
+	root, err := s.root()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, hash[:2], hash), nil
+}
+
+// root returns the store directory, creating it if needed
+func (s *AttachmentService) root() (string, error) {
+	dataDir, err := database.DataDir()
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(dataDir, "attachments")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	return root, nil
+}
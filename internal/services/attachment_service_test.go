@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cashflow/internal/models"
+)
+
+func TestAttachmentHandlerServesOnlyTheOwner(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewAttachmentService(d)
+
+	other, err := NewUserService(d).CreateUser(ctx, &models.UserCreateRequest{Name: "Other", Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction := createTestTransaction(t, NewTransactionService(d), 10, nil)
+	path := filepath.Join(t.TempDir(), "receipt.txt")
+	if err := os.WriteFile(path, []byte("paid"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	attachment, err := s.AddAttachment(ctx, DefaultUserID, transaction.ID, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		user   string
+		status int
+	}{
+		{DefaultUserID, http.StatusOK},
+		{other.ID, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Handler(func() string { return tt.user }).ServeHTTP(w, httptest.NewRequest(http.MethodGet, AttachmentURL(attachment.ID), nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.user, w.Code, tt.status)
+		}
+		if tt.status == http.StatusOK && w.Body.String() != "paid" {
+			t.Errorf("%s: body %q, want the file", tt.user, w.Body.String())
+		}
+	}
+}
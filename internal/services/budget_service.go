@@ -0,0 +1,497 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// BudgetService keeps spending limits per category and compares them with
+// what was actually spent. A budget on a category also covers its
+// subcategories. Spending is the total of the category's transactions after
+// discount and with tax, cancelled ones left out, converted to the budget's
+// currency.
+type BudgetService struct {
+	db          *database.Database
+	currencies  *CurrencyService
+	rates       *ExchangeRateService
+	preferences *PreferencesService
+}
+
+func NewBudgetService(db *database.Database) *BudgetService {
+	return &BudgetService{
+		db:          db,
+		currencies:  NewCurrencyService(db),
+		rates:       NewExchangeRateService(db),
+		preferences: NewPreferencesService(db),
+	}
+}
+
+// BudgetParams describes a budget. Period is month, quarter or year. With
+// Rollover, what is left in a period is added to the next one, counting from
+// the period containing StartDate. AlertThreshold is the share of the
+// budget at which spending raises an alert, 1 meaning all of it.
+type BudgetParams struct {
+	CategoryID     string  `json:"category_id"`
+	Period         string  `json:"period"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency,omitempty"` // defaults to the base currency
+	Rollover       bool    `json:"rollover,omitempty"`
+	AlertThreshold float64 `json:"alert_threshold,omitempty"` // defaults to 1
+	StartDate      string  `json:"start_date,omitempty"`      // defaults to today
+	CreatedBy      string  `json:"created_by"`
+}
+
+// BudgetStatus compares a budget with the spending of one of its periods.
+// Available is the budgeted amount plus what rolled over from earlier
+// periods; Alert is set once spending reaches the alert threshold.
+type BudgetStatus struct {
+	ReportBucket
+	BudgetID       string  `json:"budget_id"`
+	CategoryID     string  `json:"category_id"`
+	CategoryName   string  `json:"category_name"`
+	Period         string  `json:"period"`
+	Currency       string  `json:"currency"`
+	Budgeted       float64 `json:"budgeted"`
+	RolledOver     float64 `json:"rolled_over"`
+	Available      float64 `json:"available"`
+	Actual         float64 `json:"actual"`
+	Remaining      float64 `json:"remaining"`
+	PercentUsed    float64 `json:"percent_used"`
+	AlertThreshold float64 `json:"alert_threshold"`
+	Alert          bool    `json:"alert"`
+	OverBudget     bool    `json:"over_budget"`
+}
+
+// budgetState is a budget status with its amounts in minor units
+type budgetState struct {
+	status    BudgetStatus
+	actual    int64
+	threshold int64
+}
+
+// CreateBudget sets a budget for a category that has none yet
+func (s *BudgetService) CreateBudget(ctx context.Context, params BudgetParams) (*db.Budget, error) {
+	arg, err := s.validateBudget(ctx, "", params)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := s.db.Queries().CreateBudget(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// GetBudget retrieves a budget of createdBy by ID; those of other users are
+// not found
+func (s *BudgetService) GetBudget(ctx context.Context, createdBy, id string) (*db.Budget, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	budget, err := s.db.Queries().GetBudget(ctx, id)
+	if err == sql.ErrNoRows || err == nil && budget.CreatedBy != createdBy {
+		return nil, fmt.Errorf("budget not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// ListBudgets lists the budgets of a user
+func (s *BudgetService) ListBudgets(ctx context.Context, createdBy string) ([]db.Budget, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	budgets, err := s.db.Queries().ListBudgets(ctx, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// UpdateBudget changes a budget
+func (s *BudgetService) UpdateBudget(ctx context.Context, createdBy, id string, params BudgetParams) (*db.Budget, error) {
+	existing, err := s.GetBudget(ctx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	params.CreatedBy = existing.CreatedBy
+	arg, err := s.validateBudget(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := s.db.Queries().UpdateBudget(ctx, db.UpdateBudgetParams{
+		CategoryID:     arg.CategoryID,
+		Period:         arg.Period,
+		Amount:         arg.Amount,
+		Currency:       arg.Currency,
+		Rollover:       arg.Rollover,
+		AlertThreshold: arg.AlertThreshold,
+		StartDate:      arg.StartDate,
+		ID:             id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// DeleteBudget removes a budget
+func (s *BudgetService) DeleteBudget(ctx context.Context, createdBy, id string) error {
+	if _, err := s.GetBudget(ctx, createdBy, id); err != nil {
+		return err
+	}
+	if err := s.db.Queries().DeleteBudget(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	return nil
+}
+
+// GetBudgetVsActual compares each budget of a user with the spending of its
+// period containing date, which defaults to today. Budgets starting after
+// that period are left out.
+func (s *BudgetService) GetBudgetVsActual(ctx context.Context, createdBy, date string) ([]BudgetStatus, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	day := truncateToDate(time.Now())
+	if date != "" {
+		var err error
+		if day, err = time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+
+	budgets, err := s.ListBudgets(ctx, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	states, err := s.budgetStates(ctx, createdBy, budgets, day)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]BudgetStatus, 0, len(states))
+	for _, state := range states {
+		statuses = append(statuses, state.status)
+	}
+	return statuses, nil
+}
+
+// WatchTransactions calls alert for each budget a committed creation or
+// change of a transaction takes to its alert threshold, whichever service
+// made it. The transaction is saved either way, so failures are only logged.
+func (s *BudgetService) WatchTransactions(alert func(ctx context.Context, status BudgetStatus)) {
+	s.db.OnTransactionCommit(func(ctx context.Context, before, after *db.Transaction) {
+		alerts, err := s.CheckTransaction(ctx, before, after)
+		if err != nil {
+			log.Printf("budgets: %v", err)
+			return
+		}
+		for _, status := range alerts {
+			alert(ctx, status)
+		}
+	})
+}
+
+// CheckTransaction returns the budgets a change to a transaction took to
+// their alert threshold, so they can be warned about once rather than on
+// every later transaction. before is the transaction as it was, nil for a
+// new one.
+func (s *BudgetService) CheckTransaction(ctx context.Context, before, after *db.Transaction) ([]BudgetStatus, error) {
+	if !countsTowardsBudgets(after) {
+		return nil, nil
+	}
+
+	budgets, err := s.ListBudgets(ctx, after.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.db.Queries().ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	parents := categoryParents(categories)
+
+	var affected []db.Budget
+	for _, b := range budgets {
+		if categoryWithin(parents, after.CategoryID.String, b.CategoryID) {
+			affected = append(affected, b)
+		}
+	}
+	if len(affected) == 0 {
+		return nil, nil
+	}
+
+	states, err := s.budgetStates(ctx, after.CreatedBy, affected, truncateToDate(after.TransactionDate))
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []BudgetStatus
+	for _, state := range states {
+		if !state.status.Alert {
+			continue
+		}
+		converter, err := s.rates.newConverter(ctx, after.CreatedBy, state.status.Currency)
+		if err != nil {
+			return nil, err
+		}
+		amount, err := converter.convert(ctx, transactionTotal(after), after.Currency.String, after.TransactionDate, after.ExchangeRate)
+		if err != nil {
+			return nil, err
+		}
+		// Spending in the period before the change; only the change that
+		// crosses the threshold raises the alert
+		previous := state.actual - amount
+		if before != nil && countsTowardsBudgets(before) &&
+			categoryWithin(parents, before.CategoryID.String, state.status.CategoryID) {
+			if date := before.TransactionDate.Format("2006-01-02"); date >= state.status.StartDate && date <= state.status.EndDate {
+				was, err := converter.convert(ctx, transactionTotal(before), before.Currency.String, before.TransactionDate, before.ExchangeRate)
+				if err != nil {
+					return nil, err
+				}
+				previous += was
+			}
+		}
+		if previous <= 0 || previous < state.threshold {
+			alerts = append(alerts, state.status)
+		}
+	}
+	return alerts, nil
+}
+
+// countsTowardsBudgets reports whether a transaction is part of the spending
+// budgets are compared with
+func countsTowardsBudgets(t *db.Transaction) bool {
+	return t.CategoryID.Valid && t.CategoryID.String != "" && !t.DeletedAt.Valid && t.PaymentStatus.String != "cancelled"
+}
+
+// budgetStates works out each budget's status for its period containing day
+func (s *BudgetService) budgetStates(ctx context.Context, createdBy string, budgets []db.Budget, day time.Time) ([]budgetState, error) {
+	categories, err := s.db.Queries().ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+	parents := categoryParents(categories)
+	names := make(map[string]string, len(categories))
+	for _, c := range categories {
+		names[c.ID] = c.Name
+	}
+
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+
+	// Spending is loaded once, from the earliest period any budget needs
+	type budgetRange struct {
+		first, current ReportBucket
+	}
+	ranges := make([]budgetRange, len(budgets))
+	var from, to string
+	for i, b := range budgets {
+		current := reportBucketFor(day, b.Period)
+		first := current
+		if b.Rollover {
+			first = reportBucketFor(b.StartDate, b.Period)
+		}
+		ranges[i] = budgetRange{first: first, current: current}
+		if from == "" || first.StartDate < from {
+			from = first.StartDate
+		}
+		if current.EndDate > to {
+			to = current.EndDate
+		}
+	}
+	rows, err := s.db.Queries().GetCategorySpending(ctx, db.GetCategorySpendingParams{
+		CreatedBy: createdBy,
+		FromDate:  from,
+		ToDate:    to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category spending: %w", err)
+	}
+
+	converters := make(map[string]*currencyConverter)
+	var states []budgetState
+	for i, b := range budgets {
+		r := ranges[i]
+		if reportBucketFor(b.StartDate, b.Period).StartDate > r.current.StartDate {
+			continue
+		}
+
+		converter, ok := converters[b.Currency]
+		if !ok {
+			if converter, err = s.rates.newConverter(ctx, createdBy, b.Currency); err != nil {
+				return nil, err
+			}
+			converters[b.Currency] = converter
+		}
+
+		// Spending per period, keyed by the period's start date
+		spent := make(map[string]int64)
+		for _, row := range rows {
+			if !categoryWithin(parents, row.CategoryID.String, b.CategoryID) {
+				continue
+			}
+			date := row.TransactionDate.Format("2006-01-02")
+			if date < r.first.StartDate || date > r.current.EndDate {
+				continue
+			}
+			amount, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+			if err != nil {
+				return nil, err
+			}
+			spent[reportBucketFor(row.TransactionDate, b.Period).StartDate] += amount
+		}
+
+		// Carry what is left of each earlier period into the next
+		var carried int64
+		for bucket := r.first; bucket.StartDate < r.current.StartDate; {
+			if left := b.Amount + carried - spent[bucket.StartDate]; left > 0 {
+				carried = left
+			} else {
+				carried = 0
+			}
+			end, _ := time.Parse("2006-01-02", bucket.EndDate)
+			bucket = reportBucketFor(end.AddDate(0, 0, 1), b.Period)
+		}
+
+		available := b.Amount + carried
+		actual := spent[r.current.StartDate]
+		threshold := int64(math.Round(float64(available) * b.AlertThreshold))
+		exponent := s.currencies.Exponent(ctx, b.Currency)
+		percent := 0.0
+		if available > 0 {
+			percent = math.Round(float64(actual)/float64(available)*10000) / 100
+		}
+		states = append(states, budgetState{
+			status: BudgetStatus{
+				ReportBucket:   r.current,
+				BudgetID:       b.ID,
+				CategoryID:     b.CategoryID,
+				CategoryName:   names[b.CategoryID],
+				Period:         b.Period,
+				Currency:       b.Currency,
+				Budgeted:       fromMinorUnits(b.Amount, exponent),
+				RolledOver:     fromMinorUnits(carried, exponent),
+				Available:      fromMinorUnits(available, exponent),
+				Actual:         fromMinorUnits(actual, exponent),
+				Remaining:      fromMinorUnits(available-actual, exponent),
+				PercentUsed:    percent,
+				AlertThreshold: b.AlertThreshold,
+				Alert:          actual > 0 && actual >= threshold,
+				OverBudget:     actual > available,
+			},
+			actual:    actual,
+			threshold: threshold,
+		})
+	}
+	return states, nil
+}
+
+// validateBudget checks params and turns them into the stored budget. id is
+// the budget being updated, if any.
+func (s *BudgetService) validateBudget(ctx context.Context, id string, params BudgetParams) (db.CreateBudgetParams, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	switch params.Period {
+	case PeriodMonth, PeriodQuarter, PeriodYear:
+	default:
+		return db.CreateBudgetParams{}, fmt.Errorf("invalid budget period %q", params.Period)
+	}
+	if params.Amount < 0 {
+		return db.CreateBudgetParams{}, fmt.Errorf("budget amount cannot be negative")
+	}
+	if params.AlertThreshold < 0 {
+		return db.CreateBudgetParams{}, fmt.Errorf("alert threshold cannot be negative")
+	}
+	if params.AlertThreshold == 0 {
+		params.AlertThreshold = 1
+	}
+
+	if _, err := s.db.Queries().GetCategory(ctx, params.CategoryID); err != nil {
+		if err == sql.ErrNoRows {
+			return db.CreateBudgetParams{}, fmt.Errorf("category not found")
+		}
+		return db.CreateBudgetParams{}, fmt.Errorf("failed to get category: %w", err)
+	}
+	budgets, err := s.ListBudgets(ctx, params.CreatedBy)
+	if err != nil {
+		return db.CreateBudgetParams{}, err
+	}
+	for _, b := range budgets {
+		if b.CategoryID == params.CategoryID && b.ID != id {
+			return db.CreateBudgetParams{}, fmt.Errorf("category already has a budget")
+		}
+	}
+
+	if params.Currency == "" {
+		prefs, err := s.preferences.GetPreferences(ctx, params.CreatedBy)
+		if err != nil {
+			return db.CreateBudgetParams{}, err
+		}
+		params.Currency = prefs.BaseCurrency
+	}
+	params.Currency = normalizeCurrency(params.Currency)
+	if _, err := s.db.Queries().GetCurrency(ctx, params.Currency); err != nil {
+		if err == sql.ErrNoRows {
+			return db.CreateBudgetParams{}, fmt.Errorf("unknown currency %s", params.Currency)
+		}
+		return db.CreateBudgetParams{}, fmt.Errorf("failed to get currency: %w", err)
+	}
+
+	start := truncateToDate(time.Now())
+	if params.StartDate != "" {
+		if start, err = time.Parse("2006-01-02", params.StartDate); err != nil {
+			return db.CreateBudgetParams{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.StartDate)
+		}
+	}
+	start, _ = time.Parse("2006-01-02", reportBucketFor(start, params.Period).StartDate)
+
+	return db.CreateBudgetParams{
+		CategoryID:     params.CategoryID,
+		Period:         params.Period,
+		Amount:         toMinorUnits(params.Amount, s.currencies.Exponent(ctx, params.Currency)),
+		Currency:       params.Currency,
+		Rollover:       params.Rollover,
+		AlertThreshold: params.AlertThreshold,
+		StartDate:      start,
+		CreatedBy:      params.CreatedBy,
+	}, nil
+}
+
+// categoryParents maps each category to its parent
+func categoryParents(categories []db.Category) map[string]string {
+	parents := make(map[string]string, len(categories))
+	for _, c := range categories {
+		if c.ParentID.Valid && c.ParentID.String != "" {
+			parents[c.ID] = c.ParentID.String
+		}
+	}
+	return parents
+}
+
+// categoryWithin reports whether id is ancestor or one of its
+// subcategories. Walking up stops after as many steps as there are
+// categories, in case of a cycle.
+func categoryWithin(parents map[string]string, id, ancestor string) bool {
+	for i := 0; i <= len(parents) && id != ""; i++ {
+		if id == ancestor {
+			return true
+		}
+		id = parents[id]
+	}
+	return false
+}
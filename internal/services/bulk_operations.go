@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	db "cashflow/internal/db/sqlc"
+)
+
+// BulkItemResult is the outcome of a bulk operation for one transaction;
+// Error is empty when the change could be made
+type BulkItemResult struct {
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// BulkResult is the outcome of a bulk operation. Bulk operations are all or
+// nothing: Applied is false and nothing was changed when any transaction
+// failed.
+type BulkResult struct {
+	Applied bool             `json:"applied"`
+	Results []BulkItemResult `json:"results"`
+}
+
+// BulkDelete moves transactions to the trash
+func (s *TransactionService) BulkDelete(ctx context.Context, createdBy string, ids []string) (*BulkResult, error) {
+	return s.bulk(ctx, createdBy, ids, func(ctx context.Context, q *db.Queries, t db.Transaction) error {
+		if err := q.DeleteTransaction(ctx, t.ID); err != nil {
+			return fmt.Errorf("failed to delete transaction: %w", err)
+		}
+		after, err := q.GetTransactionWithDeleted(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get transaction: %w", err)
+		}
+		return recordHistory(ctx, q, HistoryDelete, &t, &after)
+	})
+}
+
+// BulkUpdateCategory moves transactions to a category; an empty categoryID
+// leaves them without one
+func (s *TransactionService) BulkUpdateCategory(ctx context.Context, createdBy string, ids []string, categoryID string) (*BulkResult, error) {
+	if categoryID != "" {
+		if _, err := s.db.Queries().GetCategory(ctx, categoryID); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("category not found")
+			}
+			return nil, fmt.Errorf("failed to get category: %w", err)
+		}
+	}
+	return s.bulkUpdate(ctx, createdBy, ids, func(t *db.Transaction) error {
+		t.CategoryID = toSqlNullString(categoryID)
+		return nil
+	})
+}
+
+// BulkChangePaymentMethod sets the payment method of transactions; an empty
+// paymentMethodID clears it
+func (s *TransactionService) BulkChangePaymentMethod(ctx context.Context, createdBy string, ids []string, paymentMethodID string) (*BulkResult, error) {
+	if paymentMethodID != "" {
+		if _, err := s.db.Queries().GetPaymentMethod(ctx, paymentMethodID); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("payment method not found")
+			}
+			return nil, fmt.Errorf("failed to get payment method: %w", err)
+		}
+	}
+	return s.bulkUpdate(ctx, createdBy, ids, func(t *db.Transaction) error {
+		t.PaymentMethodID = toSqlNullString(paymentMethodID)
+		return nil
+	})
+}
+
+// BulkSetPaymentStatus sets the payment status of transactions. Completed
+// leaves nothing due and pending all of it; a transaction marked partial
+// must already have a due amount below its total. Transactions with
+// recorded payments only take the status their payments give them, or
+// cancelled.
+func (s *TransactionService) BulkSetPaymentStatus(ctx context.Context, createdBy string, ids []string, status string) (*BulkResult, error) {
+	switch status {
+	case "pending", "partial", "completed", "cancelled":
+	default:
+		return nil, fmt.Errorf("invalid payment status %q", status)
+	}
+
+	return s.bulk(ctx, createdBy, ids, func(ctx context.Context, q *db.Queries, t db.Transaction) error {
+		totals, err := q.GetPaymentTotals(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get payments: %w", err)
+		}
+
+		updated := t
+		updated.PaymentStatus = toSqlNullString(status)
+		if totals.PaymentCount == 0 {
+			switch status {
+			case "completed":
+				updated.DueAmount = sql.NullInt64{Int64: 0, Valid: true}
+			case "pending":
+				updated.DueAmount = sql.NullInt64{Int64: transactionTotal(&t), Valid: true}
+			case "partial":
+				if t.DueAmount.Int64 <= 0 || t.DueAmount.Int64 >= transactionTotal(&t) {
+					return fmt.Errorf("a partially paid transaction needs a due amount below its total")
+				}
+			}
+		}
+
+		if err := s.saveUpdate(ctx, q, &t, &updated); err != nil {
+			return err
+		}
+		if updated.PaymentStatus.String != status {
+			return fmt.Errorf("payment status follows from the payments recorded, which make it %s", updated.PaymentStatus.String)
+		}
+		return nil
+	})
+}
+
+// BulkAddTags adds tags to transactions, skipping those they already have
+func (s *TransactionService) BulkAddTags(ctx context.Context, createdBy string, ids []string, tags []string) (*BulkResult, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags given")
+	}
+	return s.bulkUpdate(ctx, createdBy, ids, func(t *db.Transaction) error {
+		current, err := transactionTags(t)
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if tag != "" && !containsString(current, tag) {
+				current = append(current, tag)
+			}
+		}
+		return setTransactionTags(t, current)
+	})
+}
+
+// BulkRemoveTags removes tags from transactions
+func (s *TransactionService) BulkRemoveTags(ctx context.Context, createdBy string, ids []string, tags []string) (*BulkResult, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags given")
+	}
+	return s.bulkUpdate(ctx, createdBy, ids, func(t *db.Transaction) error {
+		current, err := transactionTags(t)
+		if err != nil {
+			return err
+		}
+		kept := []string{}
+		for _, tag := range current {
+			if !containsString(tags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		return setTransactionTags(t, kept)
+	})
+}
+
+// bulkUpdate applies change to a copy of each transaction and saves it
+func (s *TransactionService) bulkUpdate(ctx context.Context, createdBy string, ids []string, change func(t *db.Transaction) error) (*BulkResult, error) {
+	return s.bulk(ctx, createdBy, ids, func(ctx context.Context, q *db.Queries, t db.Transaction) error {
+		updated := t
+		if err := change(&updated); err != nil {
+			return err
+		}
+		return s.saveUpdate(ctx, q, &t, &updated)
+	})
+}
+
+// bulk runs apply for each transaction of createdBy in one database
+// transaction, which is only committed when apply succeeded for all of them.
+// Transactions of other users fail as not found.
+func (s *TransactionService) bulk(ctx context.Context, createdBy string, ids []string, apply func(ctx context.Context, q *db.Queries, t db.Transaction) error) (*BulkResult, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no transactions selected")
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	result := &BulkResult{Results: []BulkItemResult{}}
+	seen := make(map[string]bool, len(ids))
+	var applied []db.Transaction
+	failed := false
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item := BulkItemResult{ID: id}
+		transaction, err := getOwnedTransaction(ctx, qtx, createdBy, id)
+		if err == nil {
+			if err = checkUnlocked(&transaction); err == nil {
+				err = apply(ctx, qtx, transaction)
+			}
+		}
+		if err != nil {
+			item.Error = err.Error()
+			failed = true
+		} else {
+			applied = append(applied, transaction)
+		}
+		result.Results = append(result.Results, item)
+	}
+	if failed {
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	result.Applied = true
+
+	// Transactions moved to the trash are left out
+	for i := range applied {
+		after, err := s.db.Queries().GetTransaction(ctx, applied[i].ID)
+		if err == nil {
+			s.db.TransactionCommitted(ctx, &applied[i], &after)
+		}
+	}
+	return result, nil
+}
+
+// saveUpdate stores the changes made to a transaction, settles it against
+// its payments and records the change. after is updated to what was saved.
+func (s *TransactionService) saveUpdate(ctx context.Context, q *db.Queries, before, after *db.Transaction) error {
+	saved, err := q.UpdateTransaction(ctx, db.UpdateTransactionParams{
+		ID:                 after.ID,
+		Type:               after.Type,
+		Description:        after.Description,
+		Amount:             after.Amount,
+		TransactionDate:    after.TransactionDate,
+		CategoryID:         after.CategoryID,
+		Tags:               after.Tags,
+		CustomerVendor:     after.CustomerVendor,
+		PaymentMethodID:    after.PaymentMethodID,
+		PaymentStatus:      after.PaymentStatus,
+		ReferenceNumber:    after.ReferenceNumber,
+		InvoiceNumber:      after.InvoiceNumber,
+		Notes:              after.Notes,
+		Attachments:        after.Attachments,
+		TaxAmount:          after.TaxAmount,
+		DiscountAmount:     after.DiscountAmount,
+		DueAmount:          after.DueAmount,
+		Currency:           after.Currency,
+		ExchangeRate:       after.ExchangeRate,
+		IsRecurring:        after.IsRecurring,
+		RecurringFrequency: after.RecurringFrequency,
+		RecurringEndDate:   after.RecurringEndDate,
+		DueDate:            after.DueDate,
+		ContactID:          after.ContactID,
+		AccountID:          after.AccountID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+	*after = saved
+	if err := settleTransaction(ctx, q, after); err != nil {
+		return err
+	}
+	return recordHistory(ctx, q, HistoryUpdate, before, after)
+}
+
+// transactionTags returns the tags of a transaction
+func transactionTags(t *db.Transaction) ([]string, error) {
+	tags := []string{}
+	if t.Tags.Valid && t.Tags.String != "" && t.Tags.String != "null" {
+		if err := json.Unmarshal([]byte(t.Tags.String), &tags); err != nil {
+			return nil, fmt.Errorf("failed to parse tags: %w", err)
+		}
+	}
+	return tags, nil
+}
+
+// setTransactionTags stores tags on a transaction as a JSON array
+func setTransactionTags(t *db.Transaction, tags []string) error {
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	t.Tags = toSqlNullString(string(tagsJSON))
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	db "cashflow/internal/db/sqlc"
+	"cashflow/internal/models"
+)
+
+func TestBulkIsAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+
+	other, err := NewUserService(d).CreateUser(ctx, &models.UserCreateRequest{Name: "Other", Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// setup returns the IDs to select and the error expected for each
+		setup       func(t *testing.T) ([]string, map[string]string)
+		wantApplied bool
+	}{
+		{
+			name: "all unlocked",
+			setup: func(t *testing.T) ([]string, map[string]string) {
+				a := createTestTransaction(t, s, 10, nil)
+				b := createTestTransaction(t, s, 20, nil)
+				return []string{a.ID, b.ID}, nil
+			},
+			wantApplied: true,
+		},
+		{
+			name: "one reconciled",
+			setup: func(t *testing.T) ([]string, map[string]string) {
+				a := createTestTransaction(t, s, 10, nil)
+				locked := createTestTransaction(t, s, 20, nil)
+				c := createTestTransaction(t, s, 30, nil)
+				if err := d.Queries().SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionReconciled, ID: locked.ID}); err != nil {
+					t.Fatal(err)
+				}
+				return []string{a.ID, locked.ID, c.ID}, map[string]string{locked.ID: "reconciled"}
+			},
+		},
+		{
+			name: "one of another user",
+			setup: func(t *testing.T) ([]string, map[string]string) {
+				a := createTestTransaction(t, s, 10, nil)
+				theirs := createTestTransaction(t, s, 20, func(p *CreateTransactionParams) { p.CreatedBy = other.ID })
+				return []string{a.ID, theirs.ID}, map[string]string{theirs.ID: "not found"}
+			},
+		},
+		{
+			name: "one missing",
+			setup: func(t *testing.T) ([]string, map[string]string) {
+				a := createTestTransaction(t, s, 10, nil)
+				return []string{a.ID, "missing"}, map[string]string{"missing": "not found"}
+			},
+		},
+	}
+
+	operations := []struct {
+		name string
+		run  func(ids []string) (*BulkResult, error)
+		// changed reports whether the operation was applied to a transaction
+		changed func(t *testing.T, id string) bool
+	}{
+		{
+			name: "add tags",
+			run: func(ids []string) (*BulkResult, error) {
+				return s.BulkAddTags(ctx, DefaultUserID, ids, []string{"bulk"})
+			},
+			changed: func(t *testing.T, id string) bool {
+				transaction, err := d.Queries().GetTransactionWithDeleted(ctx, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tags, err := transactionTags(&transaction)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return containsString(tags, "bulk")
+			},
+		},
+		{
+			name: "delete",
+			run: func(ids []string) (*BulkResult, error) {
+				return s.BulkDelete(ctx, DefaultUserID, ids)
+			},
+			changed: func(t *testing.T, id string) bool {
+				transaction, err := d.Queries().GetTransactionWithDeleted(ctx, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return transaction.DeletedAt.Valid
+			},
+		},
+	}
+
+	for _, op := range operations {
+		for _, tt := range tests {
+			t.Run(op.name+"/"+tt.name, func(t *testing.T) {
+				ids, wantErrors := tt.setup(t)
+				result, err := op.run(ids)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Applied != tt.wantApplied {
+					t.Errorf("applied = %v, want %v", result.Applied, tt.wantApplied)
+				}
+				if len(result.Results) != len(ids) {
+					t.Fatalf("%d results for %d transactions", len(result.Results), len(ids))
+				}
+				for _, item := range result.Results {
+					want := wantErrors[item.ID]
+					switch {
+					case want == "" && item.Error != "":
+						t.Errorf("%s failed: %s", item.ID, item.Error)
+					case want != "" && !strings.Contains(item.Error, want):
+						t.Errorf("%s: error %q, want it to mention %q", item.ID, item.Error, want)
+					}
+				}
+				for _, id := range ids {
+					if id == "missing" {
+						continue
+					}
+					if changed := op.changed(t, id); changed != tt.wantApplied {
+						t.Errorf("%s changed = %v, want %v", id, changed, tt.wantApplied)
+					}
+				}
+			})
+		}
+	}
+}
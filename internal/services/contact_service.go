@@ -0,0 +1,504 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// ContactService manages the customers and vendors transactions are linked
+// to. A transaction's customer_vendor always holds the name of its contact.
+type ContactService struct {
+	db *database.Database
+}
+
+func NewContactService(db *database.Database) *ContactService {
+	return &ContactService{db: db}
+}
+
+// ContactParams describes a contact. Type is customer, vendor or both (the
+// default); the default category and payment method are filled in on new
+// transactions with the contact that leave them empty.
+type ContactParams struct {
+	Name                 string `json:"name"`
+	Type                 string `json:"type"`
+	Email                string `json:"email"`
+	Phone                string `json:"phone"`
+	Address              string `json:"address"`
+	TaxID                string `json:"tax_id"`
+	DefaultCategory      string `json:"default_category"`
+	DefaultPaymentMethod string `json:"default_payment_method"`
+	CreatedBy            string `json:"created_by"`
+}
+
+// CreateContact creates a contact. Names must be unique per user, ignoring
+// case, punctuation and spacing.
+func (s *ContactService) CreateContact(ctx context.Context, params ContactParams) (*db.Contact, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	if err := validateContact(&params); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameFree(ctx, params.CreatedBy, params.Name, ""); err != nil {
+		return nil, err
+	}
+
+	contact, err := s.db.Queries().CreateContact(ctx, db.CreateContactParams{
+		Name:                   params.Name,
+		Type:                   params.Type,
+		Email:                  toSqlNullString(params.Email),
+		Phone:                  toSqlNullString(params.Phone),
+		Address:                toSqlNullString(params.Address),
+		TaxID:                  toSqlNullString(params.TaxID),
+		DefaultCategoryID:      toSqlNullString(params.DefaultCategory),
+		DefaultPaymentMethodID: toSqlNullString(params.DefaultPaymentMethod),
+		CreatedBy:              params.CreatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contact: %w", err)
+	}
+	return &contact, nil
+}
+
+// GetContact retrieves a contact of createdBy by ID; those of other users
+// are not found
+func (s *ContactService) GetContact(ctx context.Context, createdBy, id string) (*db.Contact, error) {
+	contact, err := getOwnedContact(ctx, s.db.Queries(), createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// ListContacts lists the contacts of a user by name. A contactType of
+// customer or vendor also lists contacts that are both.
+func (s *ContactService) ListContacts(ctx context.Context, createdBy, contactType string) ([]db.Contact, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	contacts, err := s.db.Queries().ListContacts(ctx, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	if contactType == "" {
+		return contacts, nil
+	}
+
+	filtered := []db.Contact{}
+	for _, c := range contacts {
+		if c.Type == contactType || c.Type == "both" {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered, nil
+}
+
+// UpdateContact updates a contact. Renaming it renames it on its
+// transactions too.
+func (s *ContactService) UpdateContact(ctx context.Context, createdBy, id string, params ContactParams) (*db.Contact, error) {
+	if err := validateContact(&params); err != nil {
+		return nil, err
+	}
+	current, err := s.GetContact(ctx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNameFree(ctx, current.CreatedBy, params.Name, id); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	contact, err := qtx.UpdateContact(ctx, db.UpdateContactParams{
+		ID:                     id,
+		Name:                   params.Name,
+		Type:                   params.Type,
+		Email:                  toSqlNullString(params.Email),
+		Phone:                  toSqlNullString(params.Phone),
+		Address:                toSqlNullString(params.Address),
+		TaxID:                  toSqlNullString(params.TaxID),
+		DefaultCategoryID:      toSqlNullString(params.DefaultCategory),
+		DefaultPaymentMethodID: toSqlNullString(params.DefaultPaymentMethod),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+	if contact.Name != current.Name {
+		if err := relinkTransactions(ctx, qtx, id, &contact); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &contact, nil
+}
+
+// DeleteContact deletes a contact. Its transactions keep its name but are
+// no longer linked to it.
+func (s *ContactService) DeleteContact(ctx context.Context, createdBy, id string) error {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if _, err := getOwnedContact(ctx, qtx, createdBy, id); err != nil {
+		return err
+	}
+	if err := relinkTransactions(ctx, qtx, id, nil); err != nil {
+		return err
+	}
+	if err := qtx.DeleteContact(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// FindDuplicateContacts groups the contacts of a user whose names look like
+// the same customer or vendor, such as "Acme" and "Acme Ltd" or names a
+// typo apart. Each group has at least two contacts.
+func (s *ContactService) FindDuplicateContacts(ctx context.Context, createdBy string) ([][]db.Contact, error) {
+	contacts, err := s.ListContacts(ctx, createdBy, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over every pair of similar names
+	parent := make([]int, len(contacts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	keys := make([]string, len(contacts))
+	cores := make([]string, len(contacts))
+	for i, c := range contacts {
+		keys[i] = contactKey(c.Name)
+		cores[i] = contactCore(c.Name)
+	}
+	for i := range contacts {
+		for j := i + 1; j < len(contacts); j++ {
+			if similarNames(keys[i], keys[j]) || similarNames(cores[i], cores[j]) {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	groups := map[int][]db.Contact{}
+	var order []int
+	for i, c := range contacts {
+		r := root(i)
+		if _, ok := groups[r]; !ok {
+			order = append(order, r)
+		}
+		groups[r] = append(groups[r], c)
+	}
+	duplicates := [][]db.Contact{}
+	for _, r := range order {
+		if len(groups[r]) > 1 {
+			duplicates = append(duplicates, groups[r])
+		}
+	}
+	return duplicates, nil
+}
+
+// MergeContacts merges the sources into the target contact: their
+// transactions move to the target, details the target lacks are taken
+// from them, and the sources are deleted.
+func (s *ContactService) MergeContacts(ctx context.Context, createdBy, targetID string, sourceIDs []string) (*db.Contact, error) {
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("no contacts to merge")
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	target, err := getOwnedContact(ctx, qtx, createdBy, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	fill := func(dst *sql.NullString, src sql.NullString) {
+		if dst.String == "" {
+			*dst = src
+		}
+	}
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return nil, fmt.Errorf("cannot merge a contact into itself")
+		}
+		source, err := getOwnedContact(ctx, qtx, createdBy, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if source.Type != target.Type {
+			target.Type = "both"
+		}
+		fill(&target.Email, source.Email)
+		fill(&target.Phone, source.Phone)
+		fill(&target.Address, source.Address)
+		fill(&target.TaxID, source.TaxID)
+		fill(&target.DefaultCategoryID, source.DefaultCategoryID)
+		fill(&target.DefaultPaymentMethodID, source.DefaultPaymentMethodID)
+
+		if err := relinkTransactions(ctx, qtx, id, &target); err != nil {
+			return nil, err
+		}
+		if err := qtx.DeleteContact(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to delete contact: %w", err)
+		}
+	}
+
+	merged, err := qtx.UpdateContact(ctx, db.UpdateContactParams{
+		ID:                     target.ID,
+		Name:                   target.Name,
+		Type:                   target.Type,
+		Email:                  target.Email,
+		Phone:                  target.Phone,
+		Address:                target.Address,
+		TaxID:                  target.TaxID,
+		DefaultCategoryID:      target.DefaultCategoryID,
+		DefaultPaymentMethodID: target.DefaultPaymentMethodID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &merged, nil
+}
+
+// checkNameFree fails when another contact of the user has the same name
+func (s *ContactService) checkNameFree(ctx context.Context, createdBy, name, exceptID string) error {
+	contacts, err := s.db.Queries().ListContacts(ctx, createdBy)
+	if err != nil {
+		return fmt.Errorf("failed to list contacts: %w", err)
+	}
+	key := contactKey(name)
+	for _, c := range contacts {
+		if c.ID != exceptID && contactKey(c.Name) == key {
+			return fmt.Errorf("a contact named %q already exists", c.Name)
+		}
+	}
+	return nil
+}
+
+func validateContact(params *ContactParams) error {
+	params.Name = strings.TrimSpace(params.Name)
+	if contactKey(params.Name) == "" {
+		return fmt.Errorf("contact name is required")
+	}
+	if params.Type == "" {
+		params.Type = "both"
+	}
+	switch params.Type {
+	case "customer", "vendor", "both":
+	default:
+		return fmt.Errorf("invalid contact type %q", params.Type)
+	}
+	return nil
+}
+
+// relinkTransactions moves the transactions of a contact to another one,
+// or unlinks them when to is nil, recording the change in their history
+func relinkTransactions(ctx context.Context, q *db.Queries, contactID string, to *db.Contact) error {
+	transactions, err := q.ListTransactionsByContact(ctx, toSqlNullString(contactID))
+	if err != nil {
+		return fmt.Errorf("failed to list transactions: %w", err)
+	}
+	for _, t := range transactions {
+		after := t
+		after.ContactID = sql.NullString{}
+		if to != nil {
+			after.ContactID = toSqlNullString(to.ID)
+			after.CustomerVendor = toSqlNullString(to.Name)
+		}
+		if err := q.SetTransactionContact(ctx, db.SetTransactionContactParams{
+			ContactID:      after.ContactID,
+			CustomerVendor: after.CustomerVendor,
+			ID:             t.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update transaction: %w", err)
+		}
+		if err := recordHistory(ctx, q, HistoryUpdate, &t, &after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOwnedContact reads a contact by ID, turning one that does not belong
+// to createdBy into not found
+func getOwnedContact(ctx context.Context, q *db.Queries, createdBy, id string) (db.Contact, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	contact, err := q.GetContact(ctx, id)
+	if err == sql.ErrNoRows || err == nil && contact.CreatedBy != createdBy {
+		return db.Contact{}, fmt.Errorf("contact not found")
+	}
+	if err != nil {
+		return db.Contact{}, fmt.Errorf("failed to get contact: %w", err)
+	}
+	return contact, nil
+}
+
+// resolveContact finds the contact of a transaction: the one with
+// contactID, else the one named name, which is created when there is none.
+// It returns nil when neither is given. A contact used for both sales and
+// purchases becomes both a customer and a vendor.
+func resolveContact(ctx context.Context, q *db.Queries, createdBy, contactID, name, transactionType string) (*db.Contact, error) {
+	role := "vendor"
+	if transactionType == "income" || transactionType == "sale" {
+		role = "customer"
+	}
+
+	var contact *db.Contact
+	if contactID != "" {
+		c, err := getOwnedContact(ctx, q, createdBy, contactID)
+		if err != nil {
+			return nil, err
+		}
+		contact = &c
+	} else {
+		key := contactKey(name)
+		if key == "" {
+			return nil, nil
+		}
+		contacts, err := q.ListContacts(ctx, createdBy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list contacts: %w", err)
+		}
+		for i := range contacts {
+			if contactKey(contacts[i].Name) == key {
+				contact = &contacts[i]
+				break
+			}
+		}
+		if contact == nil {
+			c, err := q.CreateContact(ctx, db.CreateContactParams{
+				Name:      strings.TrimSpace(name),
+				Type:      role,
+				CreatedBy: createdBy,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create contact: %w", err)
+			}
+			return &c, nil
+		}
+	}
+
+	if contact.Type != role && contact.Type != "both" {
+		if err := q.SetContactType(ctx, db.SetContactTypeParams{Type: "both", ID: contact.ID}); err != nil {
+			return nil, fmt.Errorf("failed to update contact: %w", err)
+		}
+		contact.Type = "both"
+	}
+	return contact, nil
+}
+
+// contactKey reduces a name to what tells contacts apart: lower case
+// letters and digits separated by single spaces, so "ACME Ltd." and
+// "Acme  Ltd" are the same contact
+func contactKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// legalSuffixes are words of a business name that do not tell businesses
+// apart
+var legalSuffixes = map[string]bool{
+	"the": true, "ltd": true, "limited": true, "inc": true, "incorporated": true,
+	"llc": true, "llp": true, "plc": true, "co": true, "corp": true,
+	"corporation": true, "company": true, "gmbh": true, "ag": true, "sa": true,
+	"sarl": true, "bv": true, "pty": true, "srl": true,
+}
+
+// contactCore is the key of a name without legal suffixes
+func contactCore(name string) string {
+	words := strings.Fields(contactKey(name))
+	kept := words[:0:0]
+	for _, w := range words {
+		if !legalSuffixes[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		kept = words
+	}
+	return strings.Join(kept, " ")
+}
+
+// similarNames reports whether two name keys are probably the same
+// contact: equal, or a typo apart in names long enough for that to mean
+// something
+func similarNames(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ra, rb := []rune(a), []rune(b)
+	shortest := len(ra)
+	if len(rb) < shortest {
+		shortest = len(rb)
+	}
+	if shortest < 5 {
+		return false
+	}
+	allowed := 1
+	if shortest >= 10 {
+		allowed = 2
+	}
+	return editDistance(ra, rb) <= allowed
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
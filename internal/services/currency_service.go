@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// defaultCurrencyExponent is used for currencies missing from the table
+const defaultCurrencyExponent = 2
+
+// CurrencyService converts between decimal amounts and the integer minor
+// units (cents, pence, ...) that money is stored in
+type CurrencyService struct {
+	db        *database.Database
+	mu        sync.RWMutex
+	exponents map[string]int
+}
+
+func NewCurrencyService(db *database.Database) *CurrencyService {
+	return &CurrencyService{db: db}
+}
+
+// ListCurrencies lists all known currencies
+func (s *CurrencyService) ListCurrencies(ctx context.Context) ([]db.Currency, error) {
+	currencies, err := s.db.Queries().ListCurrencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	return currencies, nil
+}
+
+// Exponent returns the number of minor unit digits of a currency
+func (s *CurrencyService) Exponent(ctx context.Context, code string) int {
+	code = normalizeCurrency(code)
+
+	s.mu.RLock()
+	exponent, ok := s.exponents[code]
+	s.mu.RUnlock()
+	if ok {
+		return exponent
+	}
+
+	exponent = defaultCurrencyExponent
+	if currency, err := s.db.Queries().GetCurrency(ctx, code); err == nil {
+		exponent = int(currency.Exponent)
+	}
+
+	s.mu.Lock()
+	if s.exponents == nil {
+		s.exponents = make(map[string]int)
+	}
+	s.exponents[code] = exponent
+	s.mu.Unlock()
+	return exponent
+}
+
+// ToMinorUnits converts a decimal amount into minor units of the currency
+func (s *CurrencyService) ToMinorUnits(ctx context.Context, amount float64, currency string) int64 {
+	return toMinorUnits(amount, s.Exponent(ctx, currency))
+}
+
+// FromMinorUnits converts minor units of the currency into a decimal amount
+func (s *CurrencyService) FromMinorUnits(ctx context.Context, minor int64, currency string) float64 {
+	return fromMinorUnits(minor, s.Exponent(ctx, currency))
+}
+
+// normalizeCurrency upper-cases a currency code, defaulting to USD
+func normalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "USD"
+	}
+	return code
+}
+
+// toMinorUnits rounds amount to the nearest minor unit
+func toMinorUnits(amount float64, exponent int) int64 {
+	return int64(math.Round(amount * math.Pow10(exponent)))
+}
+
+// fromMinorUnits returns the decimal value of minor units. The division is
+// correctly rounded, so the result is the float closest to the exact amount.
+func fromMinorUnits(minor int64, exponent int) float64 {
+	return float64(minor) / math.Pow10(exponent)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		exponent int
+		minor    int64
+		back     float64 // what the minor units convert back to
+	}{
+		{12.34, 2, 1234, 12.34},
+		{0.29, 2, 29, 0.29},
+		{19.99, 2, 1999, 19.99},
+		{0.1 + 0.2, 2, 30, 0.3},
+		{1234567.89, 2, 123456789, 1234567.89},
+		{-5.5, 2, -550, -5.5},
+		{1500, 0, 1500, 1500},
+		{1.234, 3, 1234, 1.234},
+		{0, 2, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := toMinorUnits(tt.amount, tt.exponent); got != tt.minor {
+			t.Errorf("toMinorUnits(%v, %d) = %d, want %d", tt.amount, tt.exponent, got, tt.minor)
+		}
+		if got := fromMinorUnits(tt.minor, tt.exponent); got != tt.back {
+			t.Errorf("fromMinorUnits(%d, %d) = %v, want %v", tt.minor, tt.exponent, got, tt.back)
+		}
+	}
+}
+
+func TestMinorUnitsRoundTrip(t *testing.T) {
+	for _, exponent := range []int{0, 2, 3} {
+		for minor := int64(-2000); minor <= 2000; minor++ {
+			if got := toMinorUnits(fromMinorUnits(minor, exponent), exponent); got != minor {
+				t.Fatalf("exponent %d: %d minor units came back as %d", exponent, minor, got)
+			}
+		}
+	}
+}
+
+func TestTransactionAmountsInMinorUnits(t *testing.T) {
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+
+	tests := []struct {
+		currency string
+		exponent int
+		amount   float64
+		tax      float64
+		minor    int64
+		minorTax int64
+	}{
+		{"USD", 2, 12.34, 0.99, 1234, 99},
+		{"JPY", 0, 1500, 120, 1500, 120},
+		{"KWD", 3, 1.234, 0.05, 1234, 50},
+		{"jpy", 0, 980, 0, 980, 0},
+		// Currencies missing from the table use 2 digits
+		{"XYZ", 2, 7.5, 0, 750, 0},
+	}
+	for _, tt := range tests {
+		if got := s.currencies.Exponent(context.Background(), tt.currency); got != tt.exponent {
+			t.Errorf("exponent of %s = %d, want %d", tt.currency, got, tt.exponent)
+		}
+		transaction := createTestTransaction(t, s, tt.amount, func(p *CreateTransactionParams) {
+			p.Currency = tt.currency
+			p.TaxAmount = tt.tax
+		})
+		if transaction.Amount != tt.minor || transaction.TaxAmount.Int64 != tt.minorTax {
+			t.Errorf("%v %s stored as %d with tax %d, want %d with tax %d",
+				tt.amount, tt.currency, transaction.Amount, transaction.TaxAmount.Int64, tt.minor, tt.minorTax)
+		}
+	}
+}
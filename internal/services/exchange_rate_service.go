@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+type ExchangeRateService struct {
+	db          *database.Database
+	currencies  *CurrencyService
+	preferences *PreferencesService
+}
+
+// errNoExchangeRate is returned when no rate converts between two currencies
+var errNoExchangeRate = errors.New("no exchange rate")
+
+// UnconvertedAmount is what a report left out of its totals for want of an
+// exchange rate into the reporting currency: the income (with sales) and
+// expenses (with purchases) in one currency
+type UnconvertedAmount struct {
+	Currency string  `json:"currency"`
+	Income   float64 `json:"income"`
+	Expenses float64 `json:"expenses"`
+}
+
+func NewExchangeRateService(db *database.Database) *ExchangeRateService {
+	return &ExchangeRateService{
+		db:          db,
+		currencies:  NewCurrencyService(db),
+		preferences: NewPreferencesService(db),
+	}
+}
+
+// SetExchangeRateParams describes one dated rate: one unit of FromCurrency
+// is worth Rate units of ToCurrency from RateDate on
+type SetExchangeRateParams struct {
+	FromCurrency string  `json:"from_currency"`
+	ToCurrency   string  `json:"to_currency"`
+	Rate         float64 `json:"rate"`
+	RateDate     string  `json:"rate_date"`
+	Source       string  `json:"source"`
+}
+
+// ExchangeRateImportResult summarizes a CSV import
+type ExchangeRateImportResult struct {
+	Imported int `json:"imported"`
+}
+
+// SetExchangeRate creates or replaces the rate of a currency pair on a date
+func (s *ExchangeRateService) SetExchangeRate(ctx context.Context, params SetExchangeRateParams) (*db.ExchangeRate, error) {
+	arg, err := s.validateRate(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := s.db.Queries().UpsertExchangeRate(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+	return &rate, nil
+}
+
+// ListExchangeRates lists stored rates, optionally only those involving currency
+func (s *ExchangeRateService) ListExchangeRates(ctx context.Context, currency string) ([]db.ExchangeRate, error) {
+	rates, err := s.db.Queries().ListExchangeRates(ctx, strings.ToUpper(strings.TrimSpace(currency)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// DeleteExchangeRate deletes a stored rate
+func (s *ExchangeRateService) DeleteExchangeRate(ctx context.Context, id string) error {
+	if err := s.db.Queries().DeleteExchangeRate(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete exchange rate: %w", err)
+	}
+	return nil
+}
+
+// ImportExchangeRatesCSV imports rates from CSV with a header row naming the
+// date, from, to and rate columns (an optional source column is also read).
+// The import is all or nothing.
+func (s *ExchangeRateService) ImportExchangeRatesCSV(ctx context.Context, r io.Reader, source string) (*ExchangeRateImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch name {
+		case "date", "rate_date":
+			columns["date"] = i
+		case "from", "from_currency":
+			columns["from"] = i
+		case "to", "to_currency":
+			columns["to"] = i
+		case "rate":
+			columns["rate"] = i
+		case "source":
+			columns["source"] = i
+		}
+	}
+	for _, required := range []string{"date", "from", "to", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rates []db.UpsertExchangeRateParams
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rate, err := strconv.ParseFloat(field(record, "rate"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, field(record, "rate"))
+		}
+		params := SetExchangeRateParams{
+			FromCurrency: field(record, "from"),
+			ToCurrency:   field(record, "to"),
+			Rate:         rate,
+			RateDate:     field(record, "date"),
+			Source:       source,
+		}
+		if rowSource := field(record, "source"); rowSource != "" {
+			params.Source = rowSource
+		}
+
+		arg, err := s.validateRate(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rates = append(rates, arg)
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	for _, arg := range rates {
+		if _, err := qtx.UpsertExchangeRate(ctx, arg); err != nil {
+			return nil, fmt.Errorf("failed to save exchange rate: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit exchange rates: %w", err)
+	}
+	return &ExchangeRateImportResult{Imported: len(rates)}, nil
+}
+
+// validateRate checks a rate and converts it to query parameters
+func (s *ExchangeRateService) validateRate(ctx context.Context, params SetExchangeRateParams) (db.UpsertExchangeRateParams, error) {
+	from := normalizeCurrency(params.FromCurrency)
+	to := normalizeCurrency(params.ToCurrency)
+	if from == to {
+		return db.UpsertExchangeRateParams{}, fmt.Errorf("exchange rate currencies must differ")
+	}
+	for _, code := range []string{from, to} {
+		if _, err := s.db.Queries().GetCurrency(ctx, code); err != nil {
+			if err == sql.ErrNoRows {
+				return db.UpsertExchangeRateParams{}, fmt.Errorf("unknown currency %s", code)
+			}
+			return db.UpsertExchangeRateParams{}, fmt.Errorf("failed to get currency: %w", err)
+		}
+	}
+	if params.Rate <= 0 {
+		return db.UpsertExchangeRateParams{}, fmt.Errorf("exchange rate must be positive")
+	}
+	rateDate, err := time.Parse("2006-01-02", params.RateDate)
+	if err != nil {
+		return db.UpsertExchangeRateParams{}, fmt.Errorf("invalid rate date %q, expected YYYY-MM-DD", params.RateDate)
+	}
+
+	return db.UpsertExchangeRateParams{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         params.Rate,
+		RateDate:     rateDate,
+		Source:       toSqlNullString(params.Source),
+	}, nil
+}
+
+// newConverter returns a converter into reportingCurrency, which defaults to
+// the user's base currency
+func (s *ExchangeRateService) newConverter(ctx context.Context, userID, reportingCurrency string) (*currencyConverter, error) {
+	prefs, err := s.preferences.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	target := prefs.BaseCurrency
+	if reportingCurrency != "" {
+		target = normalizeCurrency(reportingCurrency)
+	}
+
+	return &currencyConverter{
+		queries:     s.db.Queries(),
+		currencies:  s.currencies,
+		base:        prefs.BaseCurrency,
+		target:      target,
+		rates:       make(map[rateKey]*big.Rat),
+		unconverted: make(map[string]*[2]int64),
+	}, nil
+}
+
+type rateKey struct {
+	from, to string
+	date     time.Time
+}
+
+// currencyConverter converts minor unit amounts into a reporting currency.
+// A transaction's own exchange rate is relative to the user's base currency
+// and is used when reporting in it; otherwise the stored rate in effect on
+// the transaction date is used, directly, inverted or through the base
+// currency.
+type currencyConverter struct {
+	queries    *db.Queries
+	currencies *CurrencyService
+	base       string
+	target     string
+	rates      map[rateKey]*big.Rat
+	// unconverted holds the income and expenses set aside per currency,
+	// in its minor units
+	unconverted map[string]*[2]int64
+}
+
+// convert returns minor units of currency on date as minor units of the
+// reporting currency, rounded half away from zero
+func (c *currencyConverter) convert(ctx context.Context, minor int64, currency string, date time.Time, ownRate sql.NullFloat64) (int64, error) {
+	currency = normalizeCurrency(currency)
+	if currency == c.target || minor == 0 {
+		return minor, nil
+	}
+
+	// A NULL or 0 rate was never entered
+	var rate *big.Rat
+	if c.target == c.base && ownRate.Valid && ownRate.Float64 > 0 {
+		rate = decimalRat(ownRate.Float64)
+	} else {
+		var err error
+		rate, err = c.lookup(ctx, currency, c.target, truncateToDate(date))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	amount := new(big.Rat).SetFrac(big.NewInt(minor), pow10(c.currencies.Exponent(ctx, currency)))
+	amount.Mul(amount, rate)
+	amount.Mul(amount, new(big.Rat).SetInt(pow10(c.currencies.Exponent(ctx, c.target))))
+	return roundRat(amount), nil
+}
+
+// setAside handles an error from convert in a report total. When there was
+// no exchange rate, minor units of currency of a transactionType are kept
+// for unconvertedAmounts instead, and it returns nil so the report can
+// leave them out; any other error is returned.
+func (c *currencyConverter) setAside(err error, currency, transactionType string, minor int64) error {
+	if !errors.Is(err, errNoExchangeRate) {
+		return err
+	}
+	if minor == 0 {
+		return nil
+	}
+	currency = normalizeCurrency(currency)
+	amounts := c.unconverted[currency]
+	if amounts == nil {
+		amounts = new([2]int64)
+		c.unconverted[currency] = amounts
+	}
+	switch transactionType {
+	case "income", "sale":
+		amounts[0] += minor
+	default:
+		amounts[1] += minor
+	}
+	return nil
+}
+
+// unconvertedAmounts lists what setAside kept, by currency, and starts
+// over
+func (c *currencyConverter) unconvertedAmounts(ctx context.Context) []UnconvertedAmount {
+	if len(c.unconverted) == 0 {
+		return nil
+	}
+	kept := c.unconverted
+	c.unconverted = make(map[string]*[2]int64)
+	result := make([]UnconvertedAmount, 0, len(kept))
+	for currency, amounts := range kept {
+		exponent := c.currencies.Exponent(ctx, currency)
+		result = append(result, UnconvertedAmount{
+			Currency: currency,
+			Income:   fromMinorUnits(amounts[0], exponent),
+			Expenses: fromMinorUnits(amounts[1], exponent),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Currency < result[j].Currency })
+	return result
+}
+
+// lookup finds the rate from one currency to another in effect on date
+func (c *currencyConverter) lookup(ctx context.Context, from, to string, date time.Time) (*big.Rat, error) {
+	key := rateKey{from, to, date}
+	if rate, ok := c.rates[key]; ok {
+		return rate, nil
+	}
+
+	rate, err := c.pairRate(ctx, from, to, date)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil && from != c.base && to != c.base {
+		viaFrom, err := c.pairRate(ctx, from, c.base, date)
+		if err != nil {
+			return nil, err
+		}
+		viaTo, err := c.pairRate(ctx, c.base, to, date)
+		if err != nil {
+			return nil, err
+		}
+		if viaFrom != nil && viaTo != nil {
+			rate = new(big.Rat).Mul(viaFrom, viaTo)
+		}
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("%w from %s to %s on or before %s", errNoExchangeRate, from, to, date.Format("2006-01-02"))
+	}
+
+	c.rates[key] = rate
+	return rate, nil
+}
+
+// pairRate returns the stored rate for a pair or the inverse of the reverse
+// pair, preferring the most recent of the two. It returns nil if neither exists.
+func (c *currencyConverter) pairRate(ctx context.Context, from, to string, date time.Time) (*big.Rat, error) {
+	direct, err := c.queries.GetEffectiveExchangeRate(ctx, db.GetEffectiveExchangeRateParams{
+		FromCurrency: from,
+		ToCurrency:   to,
+		RateDate:     date,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	hasDirect := err == nil
+
+	inverse, err := c.queries.GetEffectiveExchangeRate(ctx, db.GetEffectiveExchangeRateParams{
+		FromCurrency: to,
+		ToCurrency:   from,
+		RateDate:     date,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+	}
+	hasInverse := err == nil
+
+	switch {
+	case hasDirect && (!hasInverse || !inverse.RateDate.After(direct.RateDate)):
+		return decimalRat(direct.Rate), nil
+	case hasInverse:
+		return new(big.Rat).Inv(decimalRat(inverse.Rate)), nil
+	}
+	return nil, nil
+}
+
+// decimalRat returns the shortest decimal representation of f as a Rat, so
+// a rate entered as 1.0837 is used as exactly 1.0837
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return r
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundRat rounds r to the nearest integer, half away from zero
+func roundRat(r *big.Rat) int64 {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTotalsWithoutExchangeRate(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+	reports := NewReportService(d)
+
+	categories, err := s.GetCategories(ctx, "expense")
+	if err != nil || len(categories) == 0 {
+		t.Fatalf("no expense categories: %v", err)
+	}
+	category := categories[0].ID
+
+	createTestTransaction(t, s, 10, func(p *CreateTransactionParams) { p.Category = category })
+	// No rate from EUR to USD is stored, so this one cannot be converted
+	createTestTransaction(t, s, 20, func(p *CreateTransactionParams) {
+		p.Category = category
+		p.Currency = "EUR"
+		p.PaymentStatus = "pending"
+	})
+	// A rate of 1 is a rate like any other
+	createTestTransaction(t, s, 5, func(p *CreateTransactionParams) {
+		p.Type = "income"
+		p.Currency = "EUR"
+		p.ExchangeRate = 1
+	})
+
+	stats, err := s.GetTransactionStats(ctx, StatsParams{FromDate: "2024-01-01", ToDate: "2024-01-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalIncome != 5 || stats.TotalExpenses != 10 || stats.TotalTransactions != 2 {
+		t.Errorf("stats: income %v, expenses %v, %d transaction(s); want 5, 10 and 2", stats.TotalIncome, stats.TotalExpenses, stats.TotalTransactions)
+	}
+	want := []UnconvertedAmount{{Currency: "EUR", Expenses: 20}}
+	if !reflect.DeepEqual(stats.Unconverted, want) {
+		t.Errorf("stats unconverted: got %+v, want %+v", stats.Unconverted, want)
+	}
+
+	byCategory, err := s.GetTransactionsByCategory(ctx, StatsParams{FromDate: "2024-01-01", ToDate: "2024-01-31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCategories := []CategoryTotal{
+		{CategoryID: category, Type: "expense", Count: 1, TotalAmount: 10, Currency: "USD"},
+		{CategoryID: category, Type: "expense", Count: 1, TotalAmount: 20, Currency: "EUR"},
+	}
+	if !reflect.DeepEqual(byCategory, wantCategories) {
+		t.Errorf("by category: got %+v, want %+v", byCategory, wantCategories)
+	}
+
+	cashFlow, err := reports.GetCashFlow(ctx, ReportParams{FromDate: "2024-01-01", ToDate: "2024-01-31", Period: PeriodMonth})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cashFlow.TotalIncome != 5 || cashFlow.TotalExpenses != 10 {
+		t.Errorf("cash flow: income %v, expenses %v; want 5 and 10", cashFlow.TotalIncome, cashFlow.TotalExpenses)
+	}
+	if !reflect.DeepEqual(cashFlow.Unconverted, want) {
+		t.Errorf("cash flow unconverted: got %+v, want %+v", cashFlow.Unconverted, want)
+	}
+
+	aging, err := reports.GetAging(ctx, AgingParams{Kind: AgingPayable, AsOf: "2024-02-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aging.Total != 0 || !reflect.DeepEqual(aging.Unconverted, want) {
+		t.Errorf("aging: total %v, unconverted %+v; want 0 and %+v", aging.Total, aging.Unconverted, want)
+	}
+	items, err := reports.GetAgingDetails(ctx, AgingParams{Kind: AgingPayable, AsOf: "2024-02-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Outstanding != 20 || items[0].Currency != "EUR" {
+		t.Errorf("aging details: got %+v, want 20 EUR outstanding", items)
+	}
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// Export formats. PDF is only offered for reports.
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+	ExportFormatJSON = "json"
+	ExportFormatPDF  = "pdf"
+)
+
+// exportPageSize is how many transactions are read from the database at a time
+const exportPageSize = 500
+
+// ExportService writes filtered transactions to files
+type ExportService struct {
+	db           *database.Database
+	transactions *TransactionService
+}
+
+func NewExportService(db *database.Database) *ExportService {
+	return &ExportService{
+		db:           db,
+		transactions: NewTransactionService(db),
+	}
+}
+
+// ExportResult summarizes a finished export
+type ExportResult struct {
+	Path string `json:"path"`
+	Rows int    `json:"rows"`
+}
+
+// exportRecord is one exported transaction. Amounts are in the transaction
+// currency; BaseAmount is the amount in the user's base currency, or nil
+// when there is no exchange rate for it.
+type exportRecord struct {
+	ID              string   `json:"id"`
+	Date            string   `json:"date"`
+	Type            string   `json:"type"`
+	Description     string   `json:"description"`
+	Category        string   `json:"category"`
+	CustomerVendor  string   `json:"customer_vendor"`
+	PaymentMethod   string   `json:"payment_method"`
+	PaymentStatus   string   `json:"payment_status"`
+	ReferenceNumber string   `json:"reference_number"`
+	InvoiceNumber   string   `json:"invoice_number"`
+	Tags            []string `json:"tags"`
+	Notes           string   `json:"notes"`
+	Currency        string   `json:"currency"`
+	Amount          float64  `json:"amount"`
+	TaxAmount       float64  `json:"tax_amount"`
+	DiscountAmount  float64  `json:"discount_amount"`
+	DueAmount       float64  `json:"due_amount"`
+	NetAmount       float64  `json:"net_amount"`
+	ExchangeRate    float64  `json:"exchange_rate"`
+	BaseCurrency    string   `json:"base_currency"`
+	BaseAmount      *float64 `json:"base_amount"`
+}
+
+var exportColumns = []string{
+	"Date", "Type", "Description", "Category", "Customer/Vendor", "Payment Method",
+	"Payment Status", "Reference Number", "Invoice Number", "Tags", "Notes", "Currency",
+	"Amount", "Tax Amount", "Discount Amount", "Due Amount", "Net Amount", "Exchange Rate",
+	"Base Currency", "Base Amount", "ID",
+}
+
+// exportEncoder writes records in one of the export formats
+type exportEncoder interface {
+	Write(r *exportRecord) error
+	Close() error
+}
+
+// ExportTransactions writes every transaction matching params to w in the
+// given format and returns how many were written. Limit and Offset are
+// ignored; as in ListTransactions, the default filter applies when params
+// set no filter.
+func (s *ExportService) ExportTransactions(ctx context.Context, w io.Writer, params ListTransactionParams, format string) (int, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+
+	var encoder exportEncoder
+	switch strings.ToLower(format) {
+	case ExportFormatCSV:
+		encoder = newCSVExportEncoder(w)
+	case ExportFormatXLSX:
+		x, err := newXLSXWriter(w, "Transactions")
+		if err != nil {
+			return 0, fmt.Errorf("failed to write export: %w", err)
+		}
+		encoder = &xlsxExportEncoder{x: x}
+	case ExportFormatJSON:
+		encoder = newJSONExportEncoder(w)
+	default:
+		return 0, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	converter, err := s.transactions.rates.newConverter(ctx, params.CreatedBy, "")
+	if err != nil {
+		return 0, err
+	}
+	names := newExportNames(s.db.Queries())
+
+	count := 0
+	params.Limit = exportPageSize
+	for params.Offset = 0; ; params.Offset += exportPageSize {
+		transactions, err := s.transactions.ListTransactions(ctx, params)
+		if err != nil {
+			return count, fmt.Errorf("failed to list transactions: %w", err)
+		}
+		for i := range transactions {
+			record := s.newExportRecord(ctx, &transactions[i], names, converter)
+			if err := encoder.Write(record); err != nil {
+				return count, fmt.Errorf("failed to write export: %w", err)
+			}
+			count++
+		}
+		if len(transactions) < exportPageSize {
+			break
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return count, fmt.Errorf("failed to write export: %w", err)
+	}
+	return count, nil
+}
+
+func (s *ExportService) newExportRecord(ctx context.Context, t *db.Transaction, names *exportNames, converter *currencyConverter) *exportRecord {
+	var tags []string
+	if t.Tags.Valid && t.Tags.String != "" {
+		json.Unmarshal([]byte(t.Tags.String), &tags)
+	}
+
+	currency := normalizeCurrency(t.Currency.String)
+	exponent := s.transactions.currencies.Exponent(ctx, currency)
+	record := &exportRecord{
+		ID:              t.ID,
+		Date:            t.TransactionDate.Format("2006-01-02"),
+		Type:            t.Type,
+		Description:     t.Description,
+		Category:        names.category(ctx, t.CategoryID.String),
+		CustomerVendor:  t.CustomerVendor.String,
+		PaymentMethod:   names.paymentMethod(ctx, t.PaymentMethodID.String),
+		PaymentStatus:   t.PaymentStatus.String,
+		ReferenceNumber: t.ReferenceNumber.String,
+		InvoiceNumber:   t.InvoiceNumber.String,
+		Tags:            tags,
+		Notes:           t.Notes.String,
+		Currency:        currency,
+		Amount:          fromMinorUnits(t.Amount, exponent),
+		TaxAmount:       fromMinorUnits(t.TaxAmount.Int64, exponent),
+		DiscountAmount:  fromMinorUnits(t.DiscountAmount.Int64, exponent),
+		DueAmount:       fromMinorUnits(t.DueAmount.Int64, exponent),
+		NetAmount:       fromMinorUnits(t.NetAmount.Int64, exponent),
+		ExchangeRate:    t.ExchangeRate.Float64,
+		BaseCurrency:    converter.target,
+	}
+	if base, err := converter.convert(ctx, t.Amount, currency, t.TransactionDate, t.ExchangeRate); err == nil {
+		amount := fromMinorUnits(base, s.transactions.currencies.Exponent(ctx, converter.target))
+		record.BaseAmount = &amount
+	}
+	return record
+}
+
+// exportNames resolves category and payment method names, remembering them
+// for the rest of the export
+type exportNames struct {
+	queries        *db.Queries
+	categories     map[string]string
+	paymentMethods map[string]string
+}
+
+func newExportNames(queries *db.Queries) *exportNames {
+	return &exportNames{
+		queries:        queries,
+		categories:     make(map[string]string),
+		paymentMethods: make(map[string]string),
+	}
+}
+
+func (n *exportNames) category(ctx context.Context, id string) string {
+	if id == "" {
+		return ""
+	}
+	if name, ok := n.categories[id]; ok {
+		return name
+	}
+	name, _ := n.queries.GetCategoryName(ctx, id)
+	n.categories[id] = name
+	return name
+}
+
+func (n *exportNames) paymentMethod(ctx context.Context, id string) string {
+	if id == "" {
+		return ""
+	}
+	if name, ok := n.paymentMethods[id]; ok {
+		return name
+	}
+	name, _ := n.queries.GetPaymentMethodName(ctx, id)
+	n.paymentMethods[id] = name
+	return name
+}
+
+// cells returns the record in exportColumns order. Dates stay time.Time and
+// amounts float64 so spreadsheets get typed cells.
+func (r *exportRecord) cells() []xlsxCell {
+	date, _ := time.Parse("2006-01-02", r.Date)
+	var base xlsxCell
+	if r.BaseAmount != nil {
+		base = *r.BaseAmount
+	}
+	return []xlsxCell{
+		date, r.Type, r.Description, r.Category, r.CustomerVendor, r.PaymentMethod,
+		r.PaymentStatus, r.ReferenceNumber, r.InvoiceNumber, strings.Join(r.Tags, ", "), r.Notes, r.Currency,
+		r.Amount, r.TaxAmount, r.DiscountAmount, r.DueAmount, r.NetAmount, r.ExchangeRate,
+		r.BaseCurrency, base, r.ID,
+	}
+}
+
+type csvExportEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVExportEncoder(w io.Writer) *csvExportEncoder {
+	return &csvExportEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvExportEncoder) Write(r *exportRecord) error {
+	if !e.header {
+		e.header = true
+		if err := e.w.Write(exportColumns); err != nil {
+			return err
+		}
+	}
+
+	fields := make([]string, 0, len(exportColumns))
+	for _, cell := range r.cells() {
+		switch v := cell.(type) {
+		case nil:
+			fields = append(fields, "")
+		case time.Time:
+			fields = append(fields, v.Format("2006-01-02"))
+		case float64:
+			fields = append(fields, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			fields = append(fields, fmt.Sprint(v))
+		}
+	}
+	return e.w.Write(fields)
+}
+
+func (e *csvExportEncoder) Close() error {
+	if !e.header {
+		e.header = true
+		e.w.Write(exportColumns)
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type xlsxExportEncoder struct {
+	x      *xlsxWriter
+	header bool
+}
+
+func (e *xlsxExportEncoder) writeHeader() error {
+	e.header = true
+	header := make([]xlsxCell, len(exportColumns))
+	for i, name := range exportColumns {
+		header[i] = name
+	}
+	return e.x.WriteRow(header)
+}
+
+func (e *xlsxExportEncoder) Write(r *exportRecord) error {
+	if !e.header {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+	return e.x.WriteRow(r.cells())
+}
+
+func (e *xlsxExportEncoder) Close() error {
+	if !e.header {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+	return e.x.Close()
+}
+
+// jsonExportEncoder writes a JSON array one element at a time
+type jsonExportEncoder struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newJSONExportEncoder(w io.Writer) *jsonExportEncoder {
+	return &jsonExportEncoder{w: bufio.NewWriter(w)}
+}
+
+func (e *jsonExportEncoder) Write(r *exportRecord) error {
+	data, err := json.MarshalIndent(r, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	if e.count == 0 {
+		e.w.WriteString("[\n  ")
+	} else {
+		e.w.WriteString(",\n  ")
+	}
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonExportEncoder) Close() error {
+	if e.count == 0 {
+		e.w.WriteString("[")
+	}
+	e.w.WriteString("\n]\n")
+	return e.w.Flush()
+}
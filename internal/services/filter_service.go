@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// FilterService stores named ListTransactionParams presets
+type FilterService struct {
+	db *database.Database
+}
+
+func NewFilterService(db *database.Database) *FilterService {
+	return &FilterService{db: db}
+}
+
+// SavedFilter is a stored filter preset with its decoded parameters
+type SavedFilter struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name"`
+	Filter    ListTransactionParams `json:"filter"`
+	IsDefault bool                  `json:"is_default"`
+	CreatedAt string                `json:"created_at"`
+	UpdatedAt string                `json:"updated_at"`
+}
+
+var (
+	validTransactionTypes = map[string]bool{"income": true, "expense": true, "sale": true, "purchase": true}
+	validPaymentStatuses  = map[string]bool{"pending": true, "completed": true, "partial": true, "cancelled": true}
+)
+
+// SaveFilter stores params under a new name, optionally as the default
+func (s *FilterService) SaveFilter(ctx context.Context, createdBy, name string, params ListTransactionParams, isDefault bool) (*SavedFilter, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	name = strings.TrimSpace(name)
+	if err := s.checkName(ctx, createdBy, name, ""); err != nil {
+		return nil, err
+	}
+
+	config, err := encodeFilterConfig(params)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if isDefault {
+		if err := qtx.ClearDefaultSavedFilters(ctx, createdBy); err != nil {
+			return nil, fmt.Errorf("failed to clear default filter: %w", err)
+		}
+	}
+	filter, err := qtx.CreateSavedFilter(ctx, db.CreateSavedFilterParams{
+		Name:         name,
+		FilterConfig: config,
+		IsDefault:    toSqlNullBool(isDefault),
+		CreatedBy:    createdBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save filter: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save filter: %w", err)
+	}
+	return convertSavedFilter(&filter)
+}
+
+// ListFilters lists the saved filters, the default first
+func (s *FilterService) ListFilters(ctx context.Context, createdBy string) ([]SavedFilter, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+
+	filters, err := s.db.Queries().ListSavedFilters(ctx, createdBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filters: %w", err)
+	}
+
+	result := make([]SavedFilter, 0, len(filters))
+	for _, f := range filters {
+		filter, err := convertSavedFilter(&f)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *filter)
+	}
+	return result, nil
+}
+
+// GetDefaultFilter returns the default filter, or nil if there is none
+func (s *FilterService) GetDefaultFilter(ctx context.Context, createdBy string) (*SavedFilter, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+
+	filter, err := s.db.Queries().GetDefaultSavedFilter(ctx, createdBy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get default filter: %w", err)
+	}
+	return convertSavedFilter(&filter)
+}
+
+// RenameFilter renames a saved filter
+func (s *FilterService) RenameFilter(ctx context.Context, createdBy, id, name string) error {
+	filter, err := s.getFilter(ctx, createdBy, id)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimSpace(name)
+	if err := s.checkName(ctx, filter.CreatedBy, name, id); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Queries().RenameSavedFilter(ctx, db.RenameSavedFilterParams{
+		Name: name,
+		ID:   id,
+	}); err != nil {
+		return fmt.Errorf("failed to rename filter: %w", err)
+	}
+	return nil
+}
+
+// SetDefaultFilter makes a saved filter the default, replacing any other
+func (s *FilterService) SetDefaultFilter(ctx context.Context, createdBy, id string) error {
+	filter, err := s.getFilter(ctx, createdBy, id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if err := qtx.ClearDefaultSavedFilters(ctx, filter.CreatedBy); err != nil {
+		return fmt.Errorf("failed to clear default filter: %w", err)
+	}
+	if _, err := qtx.SetDefaultSavedFilter(ctx, id); err != nil {
+		return fmt.Errorf("failed to set default filter: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ClearDefaultFilter removes the default mark from every filter
+func (s *FilterService) ClearDefaultFilter(ctx context.Context, createdBy string) error {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	if err := s.db.Queries().ClearDefaultSavedFilters(ctx, createdBy); err != nil {
+		return fmt.Errorf("failed to clear default filter: %w", err)
+	}
+	return nil
+}
+
+// DeleteFilter deletes a saved filter
+func (s *FilterService) DeleteFilter(ctx context.Context, createdBy, id string) error {
+	if _, err := s.getFilter(ctx, createdBy, id); err != nil {
+		return err
+	}
+	if err := s.db.Queries().DeleteSavedFilter(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete filter: %w", err)
+	}
+	return nil
+}
+
+// getFilter retrieves a saved filter of createdBy; those of other users are
+// not found
+func (s *FilterService) getFilter(ctx context.Context, createdBy, id string) (*db.SavedTransactionFilter, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	filter, err := s.db.Queries().GetSavedFilter(ctx, id)
+	if err == sql.ErrNoRows || err == nil && filter.CreatedBy != createdBy {
+		return nil, fmt.Errorf("filter not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter: %w", err)
+	}
+	return &filter, nil
+}
+
+// checkName rejects empty names and names used by another of the user's filters
+func (s *FilterService) checkName(ctx context.Context, createdBy, name, id string) error {
+	if name == "" {
+		return fmt.Errorf("filter name is required")
+	}
+	existing, err := s.db.Queries().GetSavedFilterByName(ctx, db.GetSavedFilterByNameParams{
+		CreatedBy: createdBy,
+		Name:      name,
+	})
+	if err == nil && existing.ID != id {
+		return fmt.Errorf("a filter named %q already exists", name)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check filter name: %w", err)
+	}
+	return nil
+}
+
+// encodeFilterConfig validates params and encodes the filter fields as JSON.
+// Paging and ownership are not part of a filter and are dropped.
+func encodeFilterConfig(params ListTransactionParams) (string, error) {
+	params.CreatedBy = ""
+	params.Limit = 0
+	params.Offset = 0
+	params.IgnoreDefaultFilter = false
+	if err := validateFilter(params); err != nil {
+		return "", err
+	}
+
+	config, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode filter: %w", err)
+	}
+	return string(config), nil
+}
+
+// decodeFilterConfig parses stored filter JSON, rejecting unknown fields
+func decodeFilterConfig(config string) (ListTransactionParams, error) {
+	var params ListTransactionParams
+	decoder := json.NewDecoder(bytes.NewReader([]byte(config)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&params); err != nil {
+		return ListTransactionParams{}, fmt.Errorf("invalid filter config: %w", err)
+	}
+	if err := validateFilter(params); err != nil {
+		return ListTransactionParams{}, fmt.Errorf("invalid filter config: %w", err)
+	}
+	return params, nil
+}
+
+// validateFilter checks the values of the filter fields
+func validateFilter(params ListTransactionParams) error {
+	for _, date := range []string{params.FromDate, params.ToDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if params.FromDate != "" && params.ToDate != "" && params.FromDate > params.ToDate {
+		return fmt.Errorf("from date is after to date")
+	}
+	for _, t := range params.TypeFilter {
+		if !validTransactionTypes[t] {
+			return fmt.Errorf("invalid transaction type %q", t)
+		}
+	}
+	for _, status := range params.PaymentStatusFilter {
+		if !validPaymentStatuses[status] {
+			return fmt.Errorf("invalid payment status %q", status)
+		}
+	}
+	if params.MinDueAmount < 0 || params.MaxDueAmount < 0 {
+		return fmt.Errorf("due amounts cannot be negative")
+	}
+	if params.MaxDueAmount > 0 && params.MinDueAmount > params.MaxDueAmount {
+		return fmt.Errorf("minimum due amount is greater than the maximum")
+	}
+	return nil
+}
+
+func convertSavedFilter(f *db.SavedTransactionFilter) (*SavedFilter, error) {
+	params, err := decodeFilterConfig(f.FilterConfig)
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", f.Name, err)
+	}
+
+	filter := &SavedFilter{
+		ID:        f.ID,
+		Name:      f.Name,
+		Filter:    params,
+		IsDefault: f.IsDefault.Valid && f.IsDefault.Bool,
+	}
+	if f.CreatedAt.Valid {
+		filter.CreatedAt = f.CreatedAt.Time.Format("2006-01-02")
+	}
+	if f.UpdatedAt.Valid {
+		filter.UpdatedAt = f.UpdatedAt.Time.Format("2006-01-02")
+	}
+	return filter, nil
+}
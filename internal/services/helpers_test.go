@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// newTestDatabase opens a freshly migrated database under a temporary home
+// directory
+func newTestDatabase(t *testing.T) *database.Database {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	d, err := database.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// createTestTransaction creates an expense of amount USD on 2024-01-15,
+// with params filled in from change
+func createTestTransaction(t *testing.T, s *TransactionService, amount float64, change func(p *CreateTransactionParams)) *db.Transaction {
+	t.Helper()
+	params := CreateTransactionParams{
+		Type:            "expense",
+		Description:     "Test",
+		Amount:          amount,
+		TransactionDate: "2024-01-15",
+		Currency:        "USD",
+		PaymentStatus:   "completed",
+	}
+	if change != nil {
+		change(&params)
+	}
+	transaction, err := s.CreateTransaction(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transaction
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// ImportService turns bank statements into transactions. Files are first
+// parsed into a preview; committing imports every row or none.
+type ImportService struct {
+	db           *database.Database
+	transactions *TransactionService
+}
+
+func NewImportService(db *database.Database) *ImportService {
+	return &ImportService{
+		db:           db,
+		transactions: NewTransactionService(db),
+	}
+}
+
+// CSVImportOptions describes the layout of a CSV file. Mapping maps a
+// transaction field to the CSV column holding it, by header name or, for
+// files without a header, by 1-based column number. A signed amount column
+// or separate debit and credit columns decide the type when no type column
+// is mapped: credits are income, debits expenses. Of debit and credit
+// columns the one with a non-zero amount is used.
+type CSVImportOptions struct {
+	Delimiter        string            `json:"delimiter"`
+	HasHeader        bool              `json:"has_header"`
+	DateFormat       string            `json:"date_format"`
+	DecimalSeparator string            `json:"decimal_separator"`
+	Mapping          map[string]string `json:"mapping"`
+	Currency         string            `json:"currency"`
+	CreatedBy        string            `json:"created_by"`
+}
+
+// ImportRow is one parsed statement line with any validation errors.
+// Duplicate rows were imported before and are skipped.
+type ImportRow struct {
+	Line        int                     `json:"line"`
+	Transaction CreateTransactionParams `json:"transaction"`
+	Errors      []string                `json:"errors"`
+	Duplicate   bool                    `json:"duplicate"`
+}
+
+// ImportPreview is the result of parsing a file without importing it
+type ImportPreview struct {
+	Rows          []ImportRow `json:"rows"`
+	ValidRows     int         `json:"valid_rows"`
+	ErrorRows     int         `json:"error_rows"`
+	DuplicateRows int         `json:"duplicate_rows"`
+}
+
+// ImportResult summarizes a committed import
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// csvImportFields are the fields a CSV column can be mapped to
+var csvImportFields = map[string]bool{
+	"transaction_date": true, "description": true, "amount": true, "debit": true, "credit": true,
+	"type": true, "category": true, "payment_method": true, "payment_status": true,
+	"customer_vendor": true, "reference_number": true, "invoice_number": true, "notes": true,
+	"tags": true, "currency": true, "tax_amount": true, "discount_amount": true, "due_amount": true,
+}
+
+// PreviewCSV parses a CSV file and validates every row without importing
+func (s *ImportService) PreviewCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (*ImportPreview, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	delimiter, err := csvDelimiter(opts.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	reader.Comma = delimiter
+
+	layout := dateLayout(opts.DateFormat)
+	decimal := opts.DecimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	if decimal != "." && decimal != "," {
+		return nil, fmt.Errorf("decimal separator must be \".\" or \",\"")
+	}
+
+	var header []string
+	line := 1
+	if opts.HasHeader {
+		header, err = reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+		line++
+	}
+	columns, err := mapColumns(opts.Mapping, header)
+	if err != nil {
+		return nil, err
+	}
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !hasDebit && !hasCredit {
+		return nil, fmt.Errorf("map the amount column, or the debit and credit columns")
+	}
+	if _, ok := columns["transaction_date"]; !ok {
+		return nil, fmt.Errorf("map the transaction_date column")
+	}
+
+	resolver := newNameResolver(s.db.Queries())
+	var rows []ImportRow
+	for ; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row := ImportRow{Line: line}
+		params := CreateTransactionParams{
+			Description:     field("description"),
+			Type:            strings.ToLower(field("type")),
+			CustomerVendor:  field("customer_vendor"),
+			PaymentStatus:   strings.ToLower(field("payment_status")),
+			ReferenceNumber: field("reference_number"),
+			InvoiceNumber:   field("invoice_number"),
+			Notes:           field("notes"),
+			Tags:            splitTags(field("tags")),
+			Currency:        opts.Currency,
+			CreatedBy:       opts.CreatedBy,
+		}
+		if currency := field("currency"); currency != "" {
+			params.Currency = currency
+		}
+
+		// An unparsable date is kept as is for validateRow to report
+		params.TransactionDate = field("transaction_date")
+		if date, err := time.Parse(layout, params.TransactionDate); err == nil {
+			params.TransactionDate = date.Format("2006-01-02")
+		}
+
+		// The sign of the amount gives the type unless a type column says
+		// otherwise. Banks fill the unused one of the debit and credit columns
+		// with 0, so the non-zero one counts, whatever its sign.
+		amountField := func(name string) float64 {
+			value, err := parseDecimal(field(name), decimal)
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("invalid %s %q", name, field(name)))
+			}
+			return value
+		}
+		amount, credit := 0.0, false
+		if field("amount") != "" {
+			amount = amountField("amount")
+			credit = amount >= 0
+		} else {
+			var debitAmount, creditAmount float64
+			if field("debit") != "" {
+				debitAmount = amountField("debit")
+			}
+			if field("credit") != "" {
+				creditAmount = amountField("credit")
+			}
+			switch {
+			case debitAmount != 0 && creditAmount != 0:
+				row.Errors = append(row.Errors, "both debit and credit have an amount")
+				amount = debitAmount
+			case creditAmount != 0:
+				amount, credit = creditAmount, true
+			default:
+				amount = debitAmount
+			}
+		}
+		if amount < 0 {
+			amount = -amount
+		}
+		params.Amount = amount
+		if params.Type == "" {
+			params.Type = "expense"
+			if credit {
+				params.Type = "income"
+			}
+		}
+
+		for _, extra := range []struct {
+			name   string
+			target *float64
+		}{
+			{"tax_amount", &params.TaxAmount},
+			{"discount_amount", &params.DiscountAmount},
+			{"due_amount", &params.DueAmount},
+		} {
+			if value := field(extra.name); value != "" {
+				parsed, err := parseDecimal(value, decimal)
+				if err != nil {
+					row.Errors = append(row.Errors, fmt.Sprintf("invalid %s %q", strings.ReplaceAll(extra.name, "_", " "), value))
+				}
+				*extra.target = parsed
+			}
+		}
+
+		if name := field("category"); name != "" {
+			id, err := resolver.category(ctx, name)
+			if err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+			params.Category = id
+		}
+		if name := field("payment_method"); name != "" {
+			id, err := resolver.paymentMethod(ctx, name)
+			if err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+			params.PaymentMethod = id
+		}
+
+		row.Transaction = params
+		row.Errors = append(row.Errors, s.validateRow(ctx, params)...)
+		rows = append(rows, row)
+	}
+	return newImportPreview(rows), nil
+}
+
+// ImportCSV parses a CSV file and imports it in a single database
+// transaction. Nothing is imported if any row has errors.
+func (s *ImportService) ImportCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (*ImportResult, error) {
+	preview, err := s.PreviewCSV(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.commit(ctx, preview)
+}
+
+// commit creates the transactions of a preview, all or nothing
+func (s *ImportService) commit(ctx context.Context, preview *ImportPreview) (*ImportResult, error) {
+	if preview.ErrorRows > 0 {
+		return nil, fmt.Errorf("%d rows have errors; nothing was imported", preview.ErrorRows)
+	}
+
+	// Look up currency exponents first: the only connection is held by the
+	// transaction once it begins
+	for _, row := range preview.Rows {
+		s.transactions.currencies.Exponent(ctx, row.Transaction.Currency)
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	result := &ImportResult{}
+	var created []*db.Transaction
+	for _, row := range preview.Rows {
+		if row.Duplicate {
+			result.Skipped++
+			continue
+		}
+		transaction, err := s.transactions.createTransaction(ctx, qtx, row.Transaction)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: failed to create transaction: %w", row.Line, err)
+		}
+		created = append(created, transaction)
+		result.Imported++
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	for _, transaction := range created {
+		s.db.TransactionCommitted(ctx, nil, transaction)
+	}
+	return result, nil
+}
+
+// validateRow checks the fields every imported transaction needs
+func (s *ImportService) validateRow(ctx context.Context, params CreateTransactionParams) []string {
+	var errs []string
+	if params.TransactionDate == "" {
+		errs = append(errs, "date is required")
+	} else if _, err := time.Parse("2006-01-02", params.TransactionDate); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid date %q", params.TransactionDate))
+	}
+	if params.Description == "" {
+		errs = append(errs, "description is required")
+	}
+	if params.Amount == 0 {
+		errs = append(errs, "amount is required")
+	}
+	if !validTransactionTypes[params.Type] {
+		errs = append(errs, fmt.Sprintf("invalid transaction type %q", params.Type))
+	}
+	if params.PaymentStatus != "" && !validPaymentStatuses[params.PaymentStatus] {
+		errs = append(errs, fmt.Sprintf("invalid payment status %q", params.PaymentStatus))
+	}
+	currency := normalizeCurrency(params.Currency)
+	if _, err := s.db.Queries().GetCurrency(ctx, currency); err != nil {
+		errs = append(errs, fmt.Sprintf("unknown currency %s", currency))
+	}
+	return errs
+}
+
+func newImportPreview(rows []ImportRow) *ImportPreview {
+	preview := &ImportPreview{Rows: rows}
+	if preview.Rows == nil {
+		preview.Rows = []ImportRow{}
+	}
+	for _, row := range rows {
+		switch {
+		case len(row.Errors) > 0:
+			preview.ErrorRows++
+		case row.Duplicate:
+			preview.DuplicateRows++
+		default:
+			preview.ValidRows++
+		}
+	}
+	return preview
+}
+
+// nameResolver looks up category and payment method IDs by name, caching
+// the answers for the rest of the file
+type nameResolver struct {
+	queries        *db.Queries
+	categories     map[string]string
+	paymentMethods map[string]string
+}
+
+func newNameResolver(queries *db.Queries) *nameResolver {
+	return &nameResolver{
+		queries:        queries,
+		categories:     make(map[string]string),
+		paymentMethods: make(map[string]string),
+	}
+}
+
+func (r *nameResolver) category(ctx context.Context, name string) (string, error) {
+	if id, ok := r.categories[name]; ok {
+		return id, nil
+	}
+	category, err := r.queries.GetCategoryByName(ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("unknown category %q", name)
+		}
+		return "", fmt.Errorf("failed to get category: %w", err)
+	}
+	r.categories[name] = category.ID
+	return category.ID, nil
+}
+
+func (r *nameResolver) paymentMethod(ctx context.Context, name string) (string, error) {
+	if id, ok := r.paymentMethods[name]; ok {
+		return id, nil
+	}
+	method, err := r.queries.GetPaymentMethodByName(ctx, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("unknown payment method %q", name)
+		}
+		return "", fmt.Errorf("failed to get payment method: %w", err)
+	}
+	r.paymentMethods[name] = method.ID
+	return method.ID, nil
+}
+
+// mapColumns resolves the column mapping to column indexes
+func mapColumns(mapping map[string]string, header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(mapping))
+	for field, column := range mapping {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if !csvImportFields[field] {
+			return nil, fmt.Errorf("unknown import field %q", field)
+		}
+
+		index := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			if n, err := strconv.Atoi(column); err == nil && n > 0 {
+				index = n - 1
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("column %q for %s not found", column, field)
+		}
+		columns[field] = index
+	}
+	return columns, nil
+}
+
+// csvDelimiter parses a delimiter option; "tab" and "\t" mean a tab
+func csvDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q", delimiter)
+	}
+	return r, nil
+}
+
+// dateLayout turns a format such as DD/MM/YYYY into a Go time layout.
+// Formats without these tokens are taken to be Go layouts already.
+func dateLayout(format string) string {
+	if format == "" {
+		return "2006-01-02"
+	}
+	return strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(format)
+}
+
+// parseDecimal parses an amount written with the given decimal separator,
+// ignoring thousands separators, currency symbols and a leading plus.
+// Parenthesized amounts are negative.
+func parseDecimal(value, decimal string) (float64, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	value = strings.Trim(value, "()")
+	value = strings.TrimFunc(value, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '-' && r != '+' && r != '.' && r != ','
+	})
+
+	thousands := ","
+	if decimal == "," {
+		thousands = "."
+	}
+	value = strings.NewReplacer(thousands, "", " ", "", "\u00a0", "", "'", "").Replace(value)
+	value = strings.Replace(value, decimal, ".", 1)
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// splitTags splits a tag list separated by commas or semicolons
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
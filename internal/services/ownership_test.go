@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cashflow/internal/models"
+)
+
+func TestOtherUsersRecordsAreNotFound(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+
+	other, err := NewUserService(d).CreateUser(ctx, &models.UserCreateRequest{Name: "Other", Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	categories, err := NewTransactionService(d).GetCategories(ctx, "expense")
+	if err != nil || len(categories) == 0 {
+		t.Fatalf("no expense categories: %v", err)
+	}
+
+	templates := NewTemplateService(d)
+	filters := NewFilterService(d)
+	contacts := NewContactService(d)
+	accounts := NewAccountService(d)
+	budgets := NewBudgetService(d)
+
+	createAccount := func(t *testing.T, name string) string {
+		account, err := accounts.CreateAccount(ctx, AccountParams{Name: name, Currency: "USD", OpeningDate: "2024-01-01"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return account.ID
+	}
+
+	tests := []struct {
+		name string
+		// create makes a record of the default user and returns its ID
+		create func(t *testing.T) string
+		// actions read, change or delete the record as createdBy
+		actions map[string]func(createdBy, id string) error
+		// exists reports whether the owner still has the record
+		exists func(t *testing.T, id string) bool
+	}{
+		{
+			name: "template",
+			create: func(t *testing.T) string {
+				template, err := templates.CreateTemplate(ctx, CreateTemplateParams{Name: "Rent", Type: "expense", Amount: 500})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return template.ID
+			},
+			exists: func(t *testing.T, id string) bool {
+				_, err := templates.GetTemplate(ctx, DefaultUserID, id)
+				return err == nil
+			},
+			actions: map[string]func(createdBy, id string) error{
+				"get": func(createdBy, id string) error {
+					_, err := templates.GetTemplate(ctx, createdBy, id)
+					return err
+				},
+				"update": func(createdBy, id string) error {
+					_, err := templates.UpdateTemplate(ctx, createdBy, id, UpdateTemplateParams{Name: "Changed", Type: "expense"})
+					return err
+				},
+				"favorite": func(createdBy, id string) error {
+					return templates.SetTemplateFavorite(ctx, createdBy, id, true)
+				},
+				"delete": func(createdBy, id string) error {
+					return templates.DeleteTemplate(ctx, createdBy, id)
+				},
+			},
+		},
+		{
+			name: "filter",
+			create: func(t *testing.T) string {
+				filter, err := filters.SaveFilter(ctx, DefaultUserID, "Expenses", ListTransactionParams{TypeFilter: []string{"expense"}}, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return filter.ID
+			},
+			exists: func(t *testing.T, id string) bool {
+				_, err := filters.getFilter(ctx, DefaultUserID, id)
+				return err == nil
+			},
+			actions: map[string]func(createdBy, id string) error{
+				"get": func(createdBy, id string) error {
+					_, err := filters.getFilter(ctx, createdBy, id)
+					return err
+				},
+				"rename": func(createdBy, id string) error {
+					return filters.RenameFilter(ctx, createdBy, id, "Changed")
+				},
+				"set default": func(createdBy, id string) error {
+					return filters.SetDefaultFilter(ctx, createdBy, id)
+				},
+				"delete": func(createdBy, id string) error {
+					return filters.DeleteFilter(ctx, createdBy, id)
+				},
+			},
+		},
+		{
+			name: "contact",
+			create: func(t *testing.T) string {
+				contact, err := contacts.CreateContact(ctx, ContactParams{Name: "Acme"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return contact.ID
+			},
+			exists: func(t *testing.T, id string) bool {
+				_, err := contacts.GetContact(ctx, DefaultUserID, id)
+				return err == nil
+			},
+			actions: map[string]func(createdBy, id string) error{
+				"get": func(createdBy, id string) error {
+					_, err := contacts.GetContact(ctx, createdBy, id)
+					return err
+				},
+				"update": func(createdBy, id string) error {
+					_, err := contacts.UpdateContact(ctx, createdBy, id, ContactParams{Name: "Changed"})
+					return err
+				},
+				"merge": func(createdBy, id string) error {
+					theirs, err := contacts.CreateContact(ctx, ContactParams{Name: "Acme " + createdBy, CreatedBy: createdBy})
+					if err != nil {
+						return err
+					}
+					_, err = contacts.MergeContacts(ctx, createdBy, theirs.ID, []string{id})
+					return err
+				},
+				"delete": func(createdBy, id string) error {
+					return contacts.DeleteContact(ctx, createdBy, id)
+				},
+			},
+		},
+		{
+			name:   "account",
+			create: func(t *testing.T) string { return createAccount(t, "Checking") },
+			exists: func(t *testing.T, id string) bool {
+				_, err := accounts.GetAccount(ctx, DefaultUserID, id)
+				return err == nil
+			},
+			actions: map[string]func(createdBy, id string) error{
+				"get": func(createdBy, id string) error {
+					_, err := accounts.GetAccount(ctx, createdBy, id)
+					return err
+				},
+				"update": func(createdBy, id string) error {
+					_, err := accounts.UpdateAccount(ctx, createdBy, id, AccountParams{Name: "Changed", Currency: "USD", IsActive: true})
+					return err
+				},
+				"delete": func(createdBy, id string) error {
+					return accounts.DeleteAccount(ctx, createdBy, id)
+				},
+			},
+		},
+		{
+			name: "transfer",
+			create: func(t *testing.T) string {
+				transfer, err := accounts.CreateTransfer(ctx, TransferParams{
+					FromAccount:  createAccount(t, "From"),
+					ToAccount:    createAccount(t, "To"),
+					Amount:       25,
+					TransferDate: "2024-01-15",
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return transfer.ID
+			},
+			exists: func(t *testing.T, id string) bool {
+				transfers, err := accounts.ListTransfers(ctx, DefaultUserID, "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, transfer := range transfers {
+					if transfer.ID == id {
+						return true
+					}
+				}
+				return false
+			},
+			actions: map[string]func(createdBy, id string) error{
+				"delete": func(createdBy, id string) error {
+					return accounts.DeleteTransfer(ctx, createdBy, id)
+				},
+			},
+		},
+		{
+			name: "budget",
+			create: func(t *testing.T) string {
+				budget, err := budgets.CreateBudget(ctx, BudgetParams{CategoryID: categories[0].ID, Period: PeriodMonth, Amount: 100, StartDate: "2024-01-01"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return budget.ID
+			},
+			exists: func(t *testing.T, id string) bool {
+				_, err := budgets.GetBudget(ctx, DefaultUserID, id)
+				return err == nil
+			},
+			actions: map[string]func(createdBy, id string) error{
+				"get": func(createdBy, id string) error {
+					_, err := budgets.GetBudget(ctx, createdBy, id)
+					return err
+				},
+				"update": func(createdBy, id string) error {
+					_, err := budgets.UpdateBudget(ctx, createdBy, id, BudgetParams{CategoryID: categories[0].ID, Period: PeriodMonth, Amount: 1})
+					return err
+				},
+				"delete": func(createdBy, id string) error {
+					return budgets.DeleteBudget(ctx, createdBy, id)
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := tt.create(t)
+			for name, action := range tt.actions {
+				if err := action(other.ID, id); err == nil || !strings.Contains(err.Error(), "not found") {
+					t.Errorf("%s: got %v, want not found", name, err)
+				}
+			}
+			if !tt.exists(t, id) {
+				t.Error("the owner lost it to the other user")
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// PaymentService records payments against transactions. Once a transaction
+// has payments, its due amount and payment status follow from them: what is
+// still due is its total less the payments that have not been voided.
+type PaymentService struct {
+	db         *database.Database
+	currencies *CurrencyService
+}
+
+func NewPaymentService(db *database.Database) *PaymentService {
+	return &PaymentService{db: db, currencies: NewCurrencyService(db)}
+}
+
+// RecordPaymentParams describes a payment; Amount is in the currency of the
+// transaction it pays
+type RecordPaymentParams struct {
+	TransactionID string  `json:"transaction_id"`
+	Amount        float64 `json:"amount"`
+	PaymentDate   string  `json:"payment_date,omitempty"` // defaults to today
+	PaymentMethod string  `json:"payment_method,omitempty"`
+	Reference     string  `json:"reference,omitempty"`
+	Notes         string  `json:"notes,omitempty"`
+	CreatedBy     string  `json:"created_by"`
+}
+
+// RecordPayment records a payment against a transaction of params.CreatedBy
+// and updates what is still due on it. A payment cannot be more than is due.
+func (s *PaymentService) RecordPayment(ctx context.Context, params RecordPaymentParams) (*db.Payment, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	paymentDate := truncateToDate(time.Now())
+	if params.PaymentDate != "" {
+		var err error
+		if paymentDate, err = time.Parse("2006-01-02", params.PaymentDate); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.PaymentDate)
+		}
+	}
+
+	transaction, err := getOwnedTransaction(ctx, s.db.Queries(), params.CreatedBy, params.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look the exponent up before the database transaction holds the
+	// only connection
+	exponent := s.currencies.Exponent(ctx, transaction.Currency.String)
+	amount := toMinorUnits(params.Amount, exponent)
+	if amount <= 0 {
+		return nil, fmt.Errorf("payment amount must be positive")
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	transaction, err = getOwnedTransaction(ctx, qtx, params.CreatedBy, params.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.PaymentStatus.String == "cancelled" {
+		return nil, fmt.Errorf("cannot record a payment against a cancelled transaction")
+	}
+	if err := checkUnlocked(&transaction); err != nil {
+		return nil, err
+	}
+
+	totals, err := qtx.GetPaymentTotals(ctx, transaction.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payments: %w", err)
+	}
+	paid := totals.TotalPaid
+	if totals.PaymentCount == 0 {
+		// Whatever was settled before payments were tracked becomes the
+		// first payment, so it keeps counting once due amounts are derived
+		paid = transactionTotal(&transaction) - outstandingAmount(&transaction)
+		if paid > 0 {
+			if _, err := qtx.CreatePayment(ctx, db.CreatePaymentParams{
+				TransactionID:   transaction.ID,
+				Amount:          paid,
+				PaymentDate:     truncateToDate(transaction.TransactionDate),
+				PaymentMethodID: transaction.PaymentMethodID,
+				Notes:           toSqlNullString("Paid before payments were recorded"),
+				CreatedBy:       params.CreatedBy,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to record payment: %w", err)
+			}
+		}
+	}
+
+	due := transactionTotal(&transaction) - paid
+	if due <= 0 {
+		return nil, fmt.Errorf("transaction is already paid in full")
+	}
+	if amount > due {
+		return nil, fmt.Errorf("payment of %s is more than the %s still due",
+			formatAmount(fromMinorUnits(amount, exponent), exponent), formatAmount(fromMinorUnits(due, exponent), exponent))
+	}
+
+	payment, err := qtx.CreatePayment(ctx, db.CreatePaymentParams{
+		TransactionID:   transaction.ID,
+		Amount:          amount,
+		PaymentDate:     paymentDate,
+		PaymentMethodID: toSqlNullString(params.PaymentMethod),
+		Reference:       toSqlNullString(params.Reference),
+		Notes:           toSqlNullString(params.Notes),
+		CreatedBy:       params.CreatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+	before := transaction
+	if err := settleTransaction(ctx, qtx, &transaction); err != nil {
+		return nil, err
+	}
+	if err := recordHistory(ctx, qtx, HistoryUpdate, &before, &transaction); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &payment, nil
+}
+
+// ListPayments lists the payments of a transaction of createdBy, voided
+// ones included, oldest first
+func (s *PaymentService) ListPayments(ctx context.Context, createdBy, transactionID string) ([]db.Payment, error) {
+	if _, err := getOwnedTransactionWithDeleted(ctx, s.db.Queries(), createdBy, transactionID); err != nil {
+		return nil, err
+	}
+	payments, err := s.db.Queries().ListPaymentsByTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+	return payments, nil
+}
+
+// VoidPayment voids a payment on a transaction of createdBy, which then no
+// longer counts towards what has been paid on it. The payment itself is
+// kept.
+func (s *PaymentService) VoidPayment(ctx context.Context, createdBy, id string) error {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	payment, err := qtx.GetPayment(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("payment not found")
+		}
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+	transaction, err := getOwnedTransaction(ctx, qtx, createdBy, payment.TransactionID)
+	if err != nil {
+		return err
+	}
+	if err := checkUnlocked(&transaction); err != nil {
+		return err
+	}
+	n, err := qtx.VoidPayment(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to void payment: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("payment is already voided")
+	}
+	before := transaction
+	if err := settleTransaction(ctx, qtx, &transaction); err != nil {
+		return err
+	}
+	if err := recordHistory(ctx, qtx, HistoryUpdate, &before, &transaction); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// settleTransaction derives the due amount and payment status of t from its
+// payments and stores them. Transactions without payments keep what was set
+// on them by hand, and cancelled ones stay cancelled.
+func settleTransaction(ctx context.Context, q *db.Queries, t *db.Transaction) error {
+	totals, err := q.GetPaymentTotals(ctx, t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get payments: %w", err)
+	}
+	if totals.PaymentCount == 0 {
+		return nil
+	}
+
+	due := transactionTotal(t) - totals.TotalPaid
+	if due < 0 {
+		due = 0
+	}
+	status := t.PaymentStatus.String
+	switch {
+	case status == "cancelled":
+	case due == 0:
+		status = "completed"
+	case totals.TotalPaid > 0:
+		status = "partial"
+	default:
+		status = "pending"
+	}
+
+	t.DueAmount = sql.NullInt64{Int64: due, Valid: true}
+	t.PaymentStatus = toSqlNullString(status)
+	if err := q.SetTransactionSettlement(ctx, db.SetTransactionSettlementParams{
+		DueAmount:     t.DueAmount,
+		PaymentStatus: t.PaymentStatus,
+		ID:            t.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+	return nil
+}
+
+// transactionTotal is what a transaction comes to in minor units, after
+// discount and with tax
+func transactionTotal(t *db.Transaction) int64 {
+	return t.Amount - t.DiscountAmount.Int64 + t.TaxAmount.Int64
+}
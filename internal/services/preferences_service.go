@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// UserPreferences are the settings stored as JSON in users.preferences
+type UserPreferences struct {
+	BaseCurrency string `json:"base_currency"`
+
+	// TrashRetentionDays is how long deleted transactions are kept before
+	// they are purged on startup; 0 keeps them until purged by hand
+	TrashRetentionDays int `json:"trash_retention_days"`
+}
+
+type PreferencesService struct {
+	db *database.Database
+}
+
+func NewPreferencesService(db *database.Database) *PreferencesService {
+	return &PreferencesService{db: db}
+}
+
+// GetPreferences returns the preferences of a user with defaults applied
+func (s *PreferencesService) GetPreferences(ctx context.Context, userID string) (*UserPreferences, error) {
+	if userID == "" {
+		userID = DefaultUserID
+	}
+
+	raw, err := s.db.Queries().GetUserPreferences(ctx, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	prefs := &UserPreferences{}
+	if raw.Valid && raw.String != "" {
+		if err := json.Unmarshal([]byte(raw.String), prefs); err != nil {
+			return nil, fmt.Errorf("failed to parse preferences: %w", err)
+		}
+	}
+	prefs.BaseCurrency = normalizeCurrency(prefs.BaseCurrency)
+	return prefs, nil
+}
+
+// UpdatePreferences saves the preferences of a user. Keys this version does
+// not know about are kept as they are.
+func (s *PreferencesService) UpdatePreferences(ctx context.Context, userID string, prefs UserPreferences) (*UserPreferences, error) {
+	if userID == "" {
+		userID = DefaultUserID
+	}
+
+	if prefs.TrashRetentionDays < 0 {
+		return nil, fmt.Errorf("trash retention cannot be negative")
+	}
+	prefs.BaseCurrency = normalizeCurrency(prefs.BaseCurrency)
+	if _, err := s.db.Queries().GetCurrency(ctx, prefs.BaseCurrency); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("unknown currency %s", prefs.BaseCurrency)
+		}
+		return nil, fmt.Errorf("failed to get currency: %w", err)
+	}
+
+	raw, err := s.db.Queries().GetUserPreferences(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	merged := make(map[string]json.RawMessage)
+	if raw.Valid && raw.String != "" {
+		if err := json.Unmarshal([]byte(raw.String), &merged); err != nil {
+			return nil, fmt.Errorf("failed to parse preferences: %w", err)
+		}
+	}
+	updated, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(updated, &merged); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Queries().UpdateUserPreferences(ctx, db.UpdateUserPreferencesParams{
+		Preferences: toSqlNullString(string(data)),
+		ID:          userID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update preferences: %w", err)
+	}
+	return &prefs, nil
+}
@@ -0,0 +1,556 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	db "cashflow/internal/db/sqlc"
+)
+
+// Profit and loss comparisons
+const (
+	ComparePreviousPeriod = "previous_period"
+	ComparePreviousYear   = "previous_year"
+)
+
+// maxComparePeriods limits how many comparison columns a statement can have
+const maxComparePeriods = 5
+
+// ProfitLossParams selects the period of a profit and loss statement.
+// Compare adds columns for the periods before it, either of the same length
+// or a year earlier; Periods is how many (one by default).
+type ProfitLossParams struct {
+	CreatedBy string `json:"created_by"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+	Compare   string `json:"compare,omitempty"`
+	Periods   int    `json:"periods,omitempty"`
+	Currency  string `json:"currency,omitempty"` // reporting currency, defaults to the base currency
+}
+
+// ProfitLossColumn is the period of one column of amounts. Unconverted
+// lists the amounts of the period left out for want of an exchange rate.
+type ProfitLossColumn struct {
+	Label       string              `json:"label"`
+	FromDate    string              `json:"from_date"`
+	ToDate      string              `json:"to_date"`
+	Unconverted []UnconvertedAmount `json:"unconverted,omitempty"`
+}
+
+// ProfitLossLine is one category of a statement, with one amount per
+// column. Amounts of a category include its sub-categories, which follow it
+// at a greater depth; Subtotal marks such lines. A category's own
+// transactions are listed as "Other" beneath it when it has sub-categories.
+type ProfitLossLine struct {
+	CategoryID string    `json:"category_id"`
+	Name       string    `json:"name"`
+	Depth      int       `json:"depth"`
+	Subtotal   bool      `json:"subtotal"`
+	Amounts    []float64 `json:"amounts"`
+}
+
+// ProfitLossSection is the income or the expense part of a statement
+type ProfitLossSection struct {
+	Lines  []ProfitLossLine `json:"lines"`
+	Totals []float64        `json:"totals"`
+}
+
+// ProfitLossReport is a profit and loss statement. Income counts income and
+// sale transactions and expenses count expenses and purchases, at their
+// amount less discounts and excluding tax. Gross profit is sales less
+// purchases. Tax collected and discounts given are those of income and sales.
+type ProfitLossReport struct {
+	Currency       string             `json:"currency"`
+	Columns        []ProfitLossColumn `json:"columns"`
+	Income         ProfitLossSection  `json:"income"`
+	Expenses       ProfitLossSection  `json:"expenses"`
+	Sales          []float64          `json:"sales"`
+	Purchases      []float64          `json:"purchases"`
+	GrossProfit    []float64          `json:"gross_profit"`
+	NetProfit      []float64          `json:"net_profit"`
+	TaxCollected   []float64          `json:"tax_collected"`
+	DiscountsGiven []float64          `json:"discounts_given"`
+}
+
+// GetProfitAndLoss builds a profit and loss statement with categories
+// rolled up their parents
+func (s *ReportService) GetProfitAndLoss(ctx context.Context, params ProfitLossParams) (*ProfitLossReport, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	columns, err := profitLossColumns(params)
+	if err != nil {
+		return nil, err
+	}
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.db.Queries().ListCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %w", err)
+	}
+
+	n := len(columns)
+	income := newCategoryAmounts(n)
+	expenses := newCategoryAmounts(n)
+	sales := make([]int64, n)
+	purchases := make([]int64, n)
+	tax := make([]int64, n)
+	discounts := make([]int64, n)
+	for i, column := range columns {
+		rows, err := s.db.Queries().GetProfitAndLoss(ctx, db.GetProfitAndLossParams{
+			CreatedBy: params.CreatedBy,
+			FromDate:  column.FromDate,
+			ToDate:    column.ToDate,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get profit and loss: %w", err)
+		}
+
+		for _, row := range rows {
+			amount, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+			if err != nil {
+				if err := converter.setAside(err, row.Currency, row.Type, row.TotalAmount); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			switch row.Type {
+			case "income", "sale":
+				income.add(row.CategoryID.String, i, amount)
+				if row.Type == "sale" {
+					sales[i] += amount
+				}
+				rowTax, err := converter.convert(ctx, row.TotalTax, row.Currency, row.TransactionDate, row.ExchangeRate)
+				if err != nil {
+					return nil, err
+				}
+				rowDiscount, err := converter.convert(ctx, row.TotalDiscount, row.Currency, row.TransactionDate, row.ExchangeRate)
+				if err != nil {
+					return nil, err
+				}
+				tax[i] += rowTax
+				discounts[i] += rowDiscount
+			case "expense", "purchase":
+				expenses.add(row.CategoryID.String, i, amount)
+				if row.Type == "purchase" {
+					purchases[i] += amount
+				}
+			}
+		}
+		columns[i].Unconverted = converter.unconvertedAmounts(ctx)
+	}
+
+	exponent := s.currencies.Exponent(ctx, converter.target)
+	amounts := func(minor []int64) []float64 {
+		out := make([]float64, len(minor))
+		for i, v := range minor {
+			out[i] = fromMinorUnits(v, exponent)
+		}
+		return out
+	}
+	difference := func(a, b []int64) []float64 {
+		out := make([]int64, len(a))
+		for i := range a {
+			out[i] = a[i] - b[i]
+		}
+		return amounts(out)
+	}
+
+	tree := newCategoryTree(categories)
+	incomeLines, incomeTotals := tree.lines(income, amounts)
+	expenseLines, expenseTotals := tree.lines(expenses, amounts)
+	return &ProfitLossReport{
+		Currency:       converter.target,
+		Columns:        columns,
+		Income:         ProfitLossSection{Lines: incomeLines, Totals: amounts(incomeTotals)},
+		Expenses:       ProfitLossSection{Lines: expenseLines, Totals: amounts(expenseTotals)},
+		Sales:          amounts(sales),
+		Purchases:      amounts(purchases),
+		GrossProfit:    difference(sales, purchases),
+		NetProfit:      difference(incomeTotals, expenseTotals),
+		TaxCollected:   amounts(tax),
+		DiscountsGiven: amounts(discounts),
+	}, nil
+}
+
+// ExportProfitAndLoss writes a profit and loss statement to w as CSV or PDF
+func (s *ReportService) ExportProfitAndLoss(ctx context.Context, w io.Writer, params ProfitLossParams, format string) error {
+	report, err := s.GetProfitAndLoss(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case ExportFormatCSV:
+		err = writeProfitLossCSV(w, report)
+	case ExportFormatPDF:
+		err = writeProfitLossPDF(w, report, s.currencies.Exponent(ctx, report.Currency))
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write profit and loss: %w", err)
+	}
+	return nil
+}
+
+// profitLossColumns returns the requested period followed by the periods
+// it is compared with
+func profitLossColumns(params ProfitLossParams) ([]ProfitLossColumn, error) {
+	var from, to time.Time
+	var err error
+	if params.FromDate != "" {
+		if from, err = time.Parse("2006-01-02", params.FromDate); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.FromDate)
+		}
+	}
+	if params.ToDate != "" {
+		if to, err = time.Parse("2006-01-02", params.ToDate); err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.ToDate)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, fmt.Errorf("from date is after to date")
+	}
+
+	columns := []ProfitLossColumn{{Label: periodLabel(from, to), FromDate: params.FromDate, ToDate: params.ToDate}}
+	switch params.Compare {
+	case "":
+		return columns, nil
+	case ComparePreviousPeriod, ComparePreviousYear:
+	default:
+		return nil, fmt.Errorf("invalid comparison %q", params.Compare)
+	}
+	if from.IsZero() || to.IsZero() {
+		return nil, fmt.Errorf("a comparison needs both a from and a to date")
+	}
+
+	periods := params.Periods
+	if periods <= 0 {
+		periods = 1
+	}
+	if periods > maxComparePeriods {
+		return nil, fmt.Errorf("at most %d periods can be compared", maxComparePeriods)
+	}
+	for i := 0; i < periods; i++ {
+		from, to = previousPeriod(from, to, params.Compare)
+		columns = append(columns, ProfitLossColumn{
+			Label:    periodLabel(from, to),
+			FromDate: from.Format("2006-01-02"),
+			ToDate:   to.Format("2006-01-02"),
+		})
+	}
+	return columns, nil
+}
+
+// previousPeriod returns the period a statement for from-to is compared
+// with. Whole months shift by months, so March compares with February.
+func previousPeriod(from, to time.Time, compare string) (time.Time, time.Time) {
+	wholeMonths := from.Day() == 1 && to.AddDate(0, 0, 1).Day() == 1
+	if compare == ComparePreviousYear {
+		if wholeMonths {
+			return from.AddDate(-1, 0, 0), time.Date(to.Year()-1, to.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		}
+		return from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0)
+	}
+
+	if wholeMonths {
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+		return from.AddDate(0, -months, 0), from.AddDate(0, 0, -1)
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	return from.AddDate(0, 0, -days), from.AddDate(0, 0, -1)
+}
+
+// periodLabel names a period: 2024, 2024-Q1, 2024-01 or its dates
+func periodLabel(from, to time.Time) string {
+	switch {
+	case from.IsZero() && to.IsZero():
+		return "All time"
+	case from.IsZero():
+		return "Until " + to.Format("2006-01-02")
+	case to.IsZero():
+		return "From " + from.Format("2006-01-02")
+	}
+
+	if from.Day() == 1 && to.AddDate(0, 0, 1).Day() == 1 {
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+		switch {
+		case months == 12 && from.Month() == time.January:
+			return from.Format("2006")
+		case months == 3 && (from.Month()-1)%3 == 0:
+			return fmt.Sprintf("%d-Q%d", from.Year(), (from.Month()-1)/3+1)
+		case months == 1:
+			return from.Format("2006-01")
+		}
+	}
+	return from.Format("2006-01-02") + " to " + to.Format("2006-01-02")
+}
+
+// categoryAmounts holds minor units per category and column
+type categoryAmounts struct {
+	columns int
+	own     map[string][]int64
+}
+
+func newCategoryAmounts(columns int) *categoryAmounts {
+	return &categoryAmounts{columns: columns, own: make(map[string][]int64)}
+}
+
+func (c *categoryAmounts) add(categoryID string, column int, amount int64) {
+	if c.own[categoryID] == nil {
+		c.own[categoryID] = make([]int64, c.columns)
+	}
+	c.own[categoryID][column] += amount
+}
+
+// categoryTree orders categories under their parents by name
+type categoryTree struct {
+	order    []string
+	byID     map[string]db.Category
+	children map[string][]string
+	roots    []string
+}
+
+func newCategoryTree(categories []db.Category) *categoryTree {
+	t := &categoryTree{byID: make(map[string]db.Category), children: make(map[string][]string)}
+	for _, c := range categories {
+		t.order = append(t.order, c.ID)
+		t.byID[c.ID] = c
+	}
+	for _, c := range categories {
+		parent := c.ParentID.String
+		if _, ok := t.byID[parent]; ok && parent != c.ID {
+			t.children[parent] = append(t.children[parent], c.ID)
+		} else {
+			t.roots = append(t.roots, c.ID)
+		}
+	}
+
+	byName := func(ids []string) {
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := t.byID[ids[i]], t.byID[ids[j]]
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.ID < b.ID
+		})
+	}
+	byName(t.roots)
+	for _, ids := range t.children {
+		byName(ids)
+	}
+	return t
+}
+
+// lines lays out the categories with amounts depth first and returns them
+// with the section totals. Transactions without a known category come last.
+func (t *categoryTree) lines(amounts *categoryAmounts, toFloat func([]int64) []float64) ([]ProfitLossLine, []int64) {
+	lines := []ProfitLossLine{}
+	visited := make(map[string]bool)
+
+	// walk returns the rolled-up amounts of id, appending its lines when
+	// any of them is not zero
+	var walk func(id string, depth int) []int64
+	walk = func(id string, depth int) []int64 {
+		visited[id] = true
+		total := make([]int64, amounts.columns)
+		own := amounts.own[id]
+		addTo(total, own)
+
+		at := len(lines)
+		lines = append(lines, ProfitLossLine{CategoryID: id, Name: t.byID[id].Name, Depth: depth})
+		hasChildren := false
+		for _, child := range t.children[id] {
+			if visited[child] {
+				continue
+			}
+			childStart := len(lines)
+			addTo(total, walk(child, depth+1))
+			if len(lines) > childStart {
+				hasChildren = true
+			}
+		}
+
+		if isZero(total) {
+			lines = lines[:at]
+			return total
+		}
+		if hasChildren {
+			lines[at].Subtotal = true
+			if !isZero(own) {
+				lines = append(lines, ProfitLossLine{CategoryID: id, Name: "Other", Depth: depth + 1, Amounts: toFloat(own)})
+			}
+		}
+		lines[at].Amounts = toFloat(total)
+		return total
+	}
+
+	totals := make([]int64, amounts.columns)
+	for _, id := range t.roots {
+		addTo(totals, walk(id, 0))
+	}
+	// Categories in a parent cycle are never reached from a root
+	for _, id := range t.order {
+		if !visited[id] {
+			addTo(totals, walk(id, 0))
+		}
+	}
+
+	uncategorized := make([]int64, amounts.columns)
+	for id, own := range amounts.own {
+		if _, ok := t.byID[id]; !ok {
+			addTo(uncategorized, own)
+		}
+	}
+	if !isZero(uncategorized) {
+		lines = append(lines, ProfitLossLine{Name: "Uncategorized", Amounts: toFloat(uncategorized)})
+		addTo(totals, uncategorized)
+	}
+	return lines, totals
+}
+
+func addTo(dst, src []int64) {
+	for i := range src {
+		dst[i] += src[i]
+	}
+}
+
+func isZero(values []int64) bool {
+	for _, v := range values {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// profitLossRow is a labelled row of a statement as exported
+type profitLossRow struct {
+	label   string
+	depth   int
+	bold    bool
+	amounts []float64
+}
+
+// profitLossRows flattens a statement into the rows of its exports. A nil
+// amounts slice is a heading.
+func profitLossRows(r *ProfitLossReport) []profitLossRow {
+	var rows []profitLossRow
+	section := func(title string, s ProfitLossSection) {
+		rows = append(rows, profitLossRow{label: title, bold: true})
+		for _, line := range s.Lines {
+			rows = append(rows, profitLossRow{label: line.Name, depth: line.Depth + 1, bold: line.Subtotal, amounts: line.Amounts})
+		}
+		rows = append(rows, profitLossRow{label: "Total " + strings.ToLower(title), bold: true, amounts: s.Totals})
+	}
+	section("Income", r.Income)
+	section("Expenses", r.Expenses)
+	rows = append(rows,
+		profitLossRow{label: "Net profit", bold: true, amounts: r.NetProfit},
+		profitLossRow{label: "Gross profit", bold: true},
+		profitLossRow{label: "Sales", depth: 1, amounts: r.Sales},
+		profitLossRow{label: "Purchases", depth: 1, amounts: r.Purchases},
+		profitLossRow{label: "Gross profit", depth: 1, bold: true, amounts: r.GrossProfit},
+		profitLossRow{label: "Tax and discounts", bold: true},
+		profitLossRow{label: "Tax collected", depth: 1, amounts: r.TaxCollected},
+		profitLossRow{label: "Discounts given", depth: 1, amounts: r.DiscountsGiven},
+	)
+	return rows
+}
+
+func writeProfitLossCSV(w io.Writer, r *ProfitLossReport) error {
+	out := csv.NewWriter(w)
+	header := []string{"Profit and loss (" + r.Currency + ")"}
+	for _, column := range r.Columns {
+		header = append(header, column.Label)
+	}
+	out.Write(header)
+
+	for _, row := range profitLossRows(r) {
+		record := []string{strings.Repeat("  ", row.depth) + row.label}
+		for _, amount := range row.amounts {
+			record = append(record, strconv.FormatFloat(amount, 'f', -1, 64))
+		}
+		out.Write(record)
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func writeProfitLossPDF(w io.Writer, r *ProfitLossReport, exponent int) error {
+	const (
+		margin     = 40.0
+		lineHeight = 15.0
+		fontSize   = 9.0
+		labelWidth = 200.0
+	)
+	width, height := pdfA4Width, pdfA4Height
+	if len(r.Columns) > 3 {
+		width, height = height, width
+	}
+	columnWidth := (width - 2*margin - labelWidth) / float64(len(r.Columns))
+	columnRight := func(i int) float64 {
+		return margin + labelWidth + columnWidth*float64(i+1)
+	}
+
+	pdf := newPDFWriter(width, height)
+	var y float64
+	header := func() {
+		pdf.AddPage()
+		y = margin + 16
+		pdf.Text(margin, y, 16, true, "Profit and Loss")
+		y += 18
+		pdf.Text(margin, y, fontSize, false, "Amounts in "+r.Currency)
+		y += lineHeight * 1.5
+		for i, column := range r.Columns {
+			pdf.TextRight(columnRight(i), y, fontSize, true, column.Label)
+		}
+		y += 5
+		pdf.Line(margin, y, width-margin, y)
+		y += lineHeight
+	}
+
+	header()
+	for _, row := range profitLossRows(r) {
+		if y > height-margin {
+			header()
+		}
+		if row.amounts == nil {
+			y += lineHeight / 2
+		}
+		pdf.Text(margin+float64(row.depth)*12, y, fontSize, row.bold, row.label)
+		for i, amount := range row.amounts {
+			pdf.TextRight(columnRight(i), y, fontSize, row.bold, formatAmount(amount, exponent))
+		}
+		y += lineHeight
+	}
+
+	_, err := pdf.WriteTo(w)
+	return err
+}
+
+// formatAmount formats v with exponent decimals and thousands separators
+func formatAmount(v float64, exponent int) string {
+	s := strconv.FormatFloat(v, 'f', exponent, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	if fraction != "" {
+		whole += "." + fraction
+	}
+	return sign + whole
+}
@@ -0,0 +1,895 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// Cleared statuses of a transaction
+const (
+	TransactionUncleared  = "uncleared"
+	TransactionCleared    = "cleared"
+	TransactionReconciled = "reconciled"
+)
+
+// defaultMatchWindow is how many days a statement line and the transaction
+// it stands for may be apart when matching automatically
+const defaultMatchWindow = 3
+
+// ReconciliationService reconciles accounts with bank statements. While a
+// reconciliation is open, transactions matched to its statement lines, or
+// marked by hand, are cleared; completing it, once the statement and the
+// cleared balance agree, makes them reconciled and locks them.
+type ReconciliationService struct {
+	db         *database.Database
+	currencies *CurrencyService
+	rates      *ExchangeRateService
+	imports    *ImportService
+}
+
+func NewReconciliationService(db *database.Database) *ReconciliationService {
+	return &ReconciliationService{
+		db:         db,
+		currencies: NewCurrencyService(db),
+		rates:      NewExchangeRateService(db),
+		imports:    NewImportService(db),
+	}
+}
+
+// ReconciliationParams describes a bank statement of an account.
+// StatementStartDate defaults to the day after the last statement
+// reconciled, or the account's opening date. EndingBalance is in the
+// account's currency.
+type ReconciliationParams struct {
+	Account            string  `json:"account"`
+	StatementStartDate string  `json:"statement_start_date"`
+	StatementEndDate   string  `json:"statement_end_date"`
+	EndingBalance      float64 `json:"ending_balance"`
+	CreatedBy          string  `json:"created_by"`
+}
+
+// StatementLineParams is a line of a bank statement. Amount is in the
+// account's currency, positive for money in and negative for money out.
+type StatementLineParams struct {
+	LineDate    string  `json:"line_date"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Reference   string  `json:"reference"`
+}
+
+// StatementLineDetail is a statement line with the transaction it was
+// matched to, if any. MatchType is auto or manual.
+type StatementLineDetail struct {
+	ID            string  `json:"id"`
+	LineDate      string  `json:"line_date"`
+	Description   string  `json:"description"`
+	Amount        float64 `json:"amount"`
+	Reference     string  `json:"reference"`
+	TransactionID string  `json:"transaction_id"`
+	MatchType     string  `json:"match_type"`
+}
+
+// ReconciliationDetail is a reconciliation with its statement lines. The
+// cleared balance is the account's opening balance plus its cleared and
+// reconciled transactions and the transfers up to the statement's end;
+// Difference is what the statement's ending balance is above it, and must
+// be zero to complete the reconciliation.
+type ReconciliationDetail struct {
+	ID                 string                `json:"id"`
+	AccountID          string                `json:"account_id"`
+	Account            string                `json:"account"`
+	Currency           string                `json:"currency"`
+	StatementStartDate string                `json:"statement_start_date"`
+	StatementEndDate   string                `json:"statement_end_date"`
+	Status             string                `json:"status"`
+	EndingBalance      float64               `json:"ending_balance"`
+	ClearedBalance     float64               `json:"cleared_balance"`
+	Difference         float64               `json:"difference"`
+	MatchedLines       int                   `json:"matched_lines"`
+	UnmatchedLines     int                   `json:"unmatched_lines"`
+	Lines              []StatementLineDetail `json:"lines"`
+	CreatedAt          string                `json:"created_at"`
+	CompletedAt        string                `json:"completed_at"`
+}
+
+// StartReconciliation opens a reconciliation of an account against a
+// statement. An account has at most one open reconciliation.
+func (s *ReconciliationService) StartReconciliation(ctx context.Context, params ReconciliationParams) (*db.Reconciliation, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	account, err := s.getAccount(ctx, params.Account)
+	if err != nil {
+		return nil, err
+	}
+	if account.CreatedBy != params.CreatedBy {
+		return nil, fmt.Errorf("account not found")
+	}
+	if _, err := s.db.Queries().GetOpenReconciliation(ctx, account.ID); err == nil {
+		return nil, fmt.Errorf("account %s already has an open reconciliation", account.Name)
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check reconciliations: %w", err)
+	}
+
+	if params.StatementStartDate == "" {
+		previous, err := s.db.Queries().ListReconciliations(ctx, account.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list reconciliations: %w", err)
+		}
+		start := account.OpeningDate
+		if len(previous) > 0 {
+			start = previous[0].StatementEndDate.AddDate(0, 0, 1)
+		}
+		params.StatementStartDate = start.Format("2006-01-02")
+	}
+	start, end, err := statementPeriod(params)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation, err := s.db.Queries().CreateReconciliation(ctx, db.CreateReconciliationParams{
+		AccountID:          account.ID,
+		StatementStartDate: start,
+		StatementEndDate:   end,
+		EndingBalance:      toMinorUnits(params.EndingBalance, s.currencies.Exponent(ctx, account.Currency)),
+		CreatedBy:          params.CreatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation: %w", err)
+	}
+	return &reconciliation, nil
+}
+
+// UpdateReconciliation changes the statement period and ending balance of
+// an open reconciliation
+func (s *ReconciliationService) UpdateReconciliation(ctx context.Context, id string, params ReconciliationParams) (*ReconciliationDetail, error) {
+	reconciliation, account, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if params.StatementStartDate == "" {
+		params.StatementStartDate = reconciliation.StatementStartDate.Format("2006-01-02")
+	}
+	start, end, err := statementPeriod(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Queries().UpdateReconciliation(ctx, db.UpdateReconciliationParams{
+		ID:                 id,
+		StatementStartDate: start,
+		StatementEndDate:   end,
+		EndingBalance:      toMinorUnits(params.EndingBalance, s.currencies.Exponent(ctx, account.Currency)),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update reconciliation: %w", err)
+	}
+	return s.GetReconciliation(ctx, id)
+}
+
+// GetReconciliation retrieves a reconciliation with its statement lines
+// and where it stands
+func (s *ReconciliationService) GetReconciliation(ctx context.Context, id string) (*ReconciliationDetail, error) {
+	reconciliation, err := s.db.Queries().GetReconciliation(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("reconciliation not found")
+		}
+		return nil, fmt.Errorf("failed to get reconciliation: %w", err)
+	}
+	account, err := s.getAccount(ctx, reconciliation.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.db.Queries().ListStatementLines(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+
+	exponent := s.currencies.Exponent(ctx, account.Currency)
+	detail := &ReconciliationDetail{
+		ID:                 reconciliation.ID,
+		AccountID:          account.ID,
+		Account:            account.Name,
+		Currency:           account.Currency,
+		StatementStartDate: reconciliation.StatementStartDate.Format("2006-01-02"),
+		StatementEndDate:   reconciliation.StatementEndDate.Format("2006-01-02"),
+		Status:             reconciliation.Status,
+		EndingBalance:      fromMinorUnits(reconciliation.EndingBalance, exponent),
+		Lines:              []StatementLineDetail{},
+	}
+	if reconciliation.CreatedAt.Valid {
+		detail.CreatedAt = reconciliation.CreatedAt.Time.Format(time.RFC3339)
+	}
+	if reconciliation.CompletedAt.Valid {
+		detail.CompletedAt = reconciliation.CompletedAt.Time.Format(time.RFC3339)
+	}
+	for _, line := range lines {
+		if line.TransactionID.Valid {
+			detail.MatchedLines++
+		} else {
+			detail.UnmatchedLines++
+		}
+		detail.Lines = append(detail.Lines, StatementLineDetail{
+			ID:            line.ID,
+			LineDate:      line.LineDate.Format("2006-01-02"),
+			Description:   line.Description.String,
+			Amount:        fromMinorUnits(line.Amount, exponent),
+			Reference:     line.Reference.String,
+			TransactionID: line.TransactionID.String,
+			MatchType:     line.MatchType.String,
+		})
+	}
+
+	cleared, err := s.clearedBalance(ctx, account, reconciliation.StatementEndDate)
+	if err != nil {
+		return nil, err
+	}
+	detail.ClearedBalance = fromMinorUnits(cleared, exponent)
+	detail.Difference = fromMinorUnits(reconciliation.EndingBalance-cleared, exponent)
+	return detail, nil
+}
+
+// ListReconciliations lists the reconciliations of an account, latest
+// statement first
+func (s *ReconciliationService) ListReconciliations(ctx context.Context, accountID string) ([]db.Reconciliation, error) {
+	reconciliations, err := s.db.Queries().ListReconciliations(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliations: %w", err)
+	}
+	return reconciliations, nil
+}
+
+// AddStatementLines adds lines to the statement of an open reconciliation
+func (s *ReconciliationService) AddStatementLines(ctx context.Context, id string, lines []StatementLineParams) (*ReconciliationDetail, error) {
+	_, account, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no statement lines given")
+	}
+	exponent := s.currencies.Exponent(ctx, account.Currency)
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	for i, line := range lines {
+		date, err := time.Parse("2006-01-02", line.LineDate)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q, expected YYYY-MM-DD", i+1, line.LineDate)
+		}
+		if _, err := qtx.CreateStatementLine(ctx, db.CreateStatementLineParams{
+			ReconciliationID: id,
+			LineDate:         date,
+			Description:      toSqlNullString(strings.TrimSpace(line.Description)),
+			Amount:           toMinorUnits(line.Amount, exponent),
+			Reference:        toSqlNullString(strings.TrimSpace(line.Reference)),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create statement line: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetReconciliation(ctx, id)
+}
+
+// ImportStatementLines adds the lines of an OFX/QFX or QIF statement to an
+// open reconciliation. Lines already there, by reference or else by date,
+// amount and description, are skipped.
+func (s *ReconciliationService) ImportStatementLines(ctx context.Context, id, name string, r io.Reader) (*ReconciliationDetail, error) {
+	_, account, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := s.imports.PreviewStatement(ctx, name, r, StatementImportOptions{
+		Currency:  account.Currency,
+		CreatedBy: account.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.db.Queries().ListStatementLines(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+	exponent := s.currencies.Exponent(ctx, account.Currency)
+	seen := make(map[string]bool, len(existing))
+	for _, line := range existing {
+		seen[statementLineKey(line.LineDate.Format("2006-01-02"), line.Amount, line.Description.String, line.Reference.String)] = true
+	}
+
+	var lines []StatementLineParams
+	for _, row := range preview.Rows {
+		if len(row.Errors) > 0 {
+			return nil, fmt.Errorf("line %d: %s", row.Line, strings.Join(row.Errors, "; "))
+		}
+		t := row.Transaction
+		if normalizeCurrency(t.Currency) != account.Currency {
+			return nil, fmt.Errorf("line %d: the statement is in %s but account %s is in %s", row.Line, t.Currency, account.Name, account.Currency)
+		}
+		amount := t.Amount
+		if t.Type == "expense" {
+			amount = -amount
+		}
+		key := statementLineKey(t.TransactionDate, toMinorUnits(amount, exponent), t.Description, t.ReferenceNumber)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		lines = append(lines, StatementLineParams{
+			LineDate:    t.TransactionDate,
+			Description: t.Description,
+			Amount:      amount,
+			Reference:   t.ReferenceNumber,
+		})
+	}
+	if len(lines) == 0 {
+		return s.GetReconciliation(ctx, id)
+	}
+	return s.AddStatementLines(ctx, id, lines)
+}
+
+// DeleteStatementLine removes a line from the statement of an open
+// reconciliation; the transaction it was matched to is no longer cleared
+func (s *ReconciliationService) DeleteStatementLine(ctx context.Context, lineID string) (*ReconciliationDetail, error) {
+	line, err := s.getLine(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := s.getOpen(ctx, line.ReconciliationID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if line.TransactionID.Valid {
+		if err := qtx.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionUncleared, ID: line.TransactionID.String}); err != nil {
+			return nil, fmt.Errorf("failed to unclear transaction: %w", err)
+		}
+	}
+	if err := qtx.DeleteStatementLine(ctx, lineID); err != nil {
+		return nil, fmt.Errorf("failed to delete statement line: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetReconciliation(ctx, line.ReconciliationID)
+}
+
+// AutoMatch matches the unmatched lines of an open reconciliation to
+// transactions of the account with the same amount, dated at most
+// windowDays apart (3 when zero), and clears them. A transaction whose
+// reference or invoice number is the line's reference is preferred, then
+// the one closest in date.
+func (s *ReconciliationService) AutoMatch(ctx context.Context, id string, windowDays int) (*ReconciliationDetail, error) {
+	reconciliation, account, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if windowDays <= 0 {
+		windowDays = defaultMatchWindow
+	}
+	lines, err := s.db.Queries().ListStatementLines(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+	candidates, err := s.unmatchedTransactions(ctx, reconciliation, account, lines, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	// Amounts in the account's currency, signed like statement lines
+	amounts := make([]int64, len(candidates))
+	converter, err := s.rates.newConverter(ctx, account.CreatedBy, account.Currency)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range candidates {
+		if amounts[i], err = signedAmount(ctx, converter, &t); err != nil {
+			return nil, err
+		}
+	}
+
+	window := time.Duration(windowDays) * 24 * time.Hour
+	taken := make([]bool, len(candidates))
+	matches := make(map[string]string)
+	for _, line := range lines {
+		if line.TransactionID.Valid {
+			continue
+		}
+		best := -1
+		var bestGap time.Duration
+		bestReference := false
+		for i, t := range candidates {
+			if taken[i] || amounts[i] != line.Amount {
+				continue
+			}
+			gap := truncateToDate(t.TransactionDate).Sub(line.LineDate)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > window {
+				continue
+			}
+			reference := sameReference(line.Reference.String, t.ReferenceNumber.String) || sameReference(line.Reference.String, t.InvoiceNumber.String)
+			if best < 0 || (reference && !bestReference) || (reference == bestReference && gap < bestGap) {
+				best, bestGap, bestReference = i, gap, reference
+			}
+		}
+		if best >= 0 {
+			taken[best] = true
+			matches[line.ID] = candidates[best].ID
+		}
+	}
+
+	if len(matches) > 0 {
+		tx, err := s.db.Conn().BeginTx(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		qtx := s.db.Queries().WithTx(tx)
+		for lineID, transactionID := range matches {
+			if err := match(ctx, qtx, lineID, transactionID, "auto"); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+	}
+	return s.GetReconciliation(ctx, id)
+}
+
+// ListUnmatchedTransactions lists the transactions of the account that an
+// open reconciliation's lines could still be matched to by hand: those not
+// yet reconciled or matched, dated within windowDays (3 when zero) of the
+// statement period
+func (s *ReconciliationService) ListUnmatchedTransactions(ctx context.Context, id string, windowDays int) ([]db.Transaction, error) {
+	reconciliation, account, err := s.getOpen(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if windowDays <= 0 {
+		windowDays = defaultMatchWindow
+	}
+	lines, err := s.db.Queries().ListStatementLines(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statement lines: %w", err)
+	}
+	return s.unmatchedTransactions(ctx, reconciliation, account, lines, windowDays)
+}
+
+// MatchStatementLine matches a statement line to a transaction of the
+// account by hand and clears the transaction. The amounts need not agree;
+// any difference shows in the reconciliation.
+func (s *ReconciliationService) MatchStatementLine(ctx context.Context, createdBy, lineID, transactionID string) (*ReconciliationDetail, error) {
+	line, err := s.getLine(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	_, account, err := s.getOpen(ctx, line.ReconciliationID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	transaction, err := getOwnedTransaction(ctx, qtx, createdBy, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.AccountID.String != account.ID {
+		return nil, fmt.Errorf("transaction is not in account %s", account.Name)
+	}
+	if transaction.ClearedStatus == TransactionReconciled {
+		return nil, fmt.Errorf("transaction is already reconciled")
+	}
+	other, err := qtx.GetOpenStatementLineByTransaction(ctx, toSqlNullString(transactionID))
+	switch {
+	case err == nil && other.ID != lineID:
+		return nil, fmt.Errorf("transaction is already matched to another statement line")
+	case err != nil && err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to check statement lines: %w", err)
+	}
+
+	if line.TransactionID.Valid && line.TransactionID.String != transactionID {
+		if err := qtx.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionUncleared, ID: line.TransactionID.String}); err != nil {
+			return nil, fmt.Errorf("failed to unclear transaction: %w", err)
+		}
+	}
+	if err := match(ctx, qtx, lineID, transactionID, "manual"); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetReconciliation(ctx, line.ReconciliationID)
+}
+
+// UnmatchStatementLine undoes the match of a statement line; its
+// transaction is no longer cleared
+func (s *ReconciliationService) UnmatchStatementLine(ctx context.Context, lineID string) (*ReconciliationDetail, error) {
+	line, err := s.getLine(ctx, lineID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := s.getOpen(ctx, line.ReconciliationID); err != nil {
+		return nil, err
+	}
+	if !line.TransactionID.Valid {
+		return nil, fmt.Errorf("statement line is not matched")
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if err := qtx.SetStatementLineMatch(ctx, db.SetStatementLineMatchParams{ID: lineID}); err != nil {
+		return nil, fmt.Errorf("failed to unmatch statement line: %w", err)
+	}
+	if err := qtx.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionUncleared, ID: line.TransactionID.String}); err != nil {
+		return nil, fmt.Errorf("failed to unclear transaction: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetReconciliation(ctx, line.ReconciliationID)
+}
+
+// SetTransactionCleared clears a transaction of createdBy without a
+// statement line, or unclears it, undoing any match. TransactionReconciled
+// transactions must be unlocked first.
+func (s *ReconciliationService) SetTransactionCleared(ctx context.Context, createdBy, id string, cleared bool) (*db.Transaction, error) {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	transaction, err := getOwnedTransaction(ctx, qtx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnlocked(&transaction); err != nil {
+		return nil, err
+	}
+	if !transaction.AccountID.Valid {
+		return nil, fmt.Errorf("transaction has no account to reconcile")
+	}
+
+	status := TransactionUncleared
+	if cleared {
+		status = TransactionCleared
+	} else {
+		line, err := qtx.GetOpenStatementLineByTransaction(ctx, toSqlNullString(id))
+		switch {
+		case err == nil:
+			if err := qtx.SetStatementLineMatch(ctx, db.SetStatementLineMatchParams{ID: line.ID}); err != nil {
+				return nil, fmt.Errorf("failed to unmatch statement line: %w", err)
+			}
+		case err != sql.ErrNoRows:
+			return nil, fmt.Errorf("failed to check statement lines: %w", err)
+		}
+	}
+	if err := qtx.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: status, ID: id}); err != nil {
+		return nil, fmt.Errorf("failed to set cleared status: %w", err)
+	}
+	transaction.ClearedStatus = status
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &transaction, nil
+}
+
+// CompleteReconciliation finishes an open reconciliation once the
+// statement's ending balance and the cleared balance agree. The account's
+// cleared transactions become reconciled, which locks them.
+func (s *ReconciliationService) CompleteReconciliation(ctx context.Context, id string) (*ReconciliationDetail, error) {
+	detail, err := s.GetReconciliation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if detail.Status != "open" {
+		return nil, fmt.Errorf("reconciliation is already completed")
+	}
+	if detail.Difference != 0 {
+		return nil, fmt.Errorf("the statement ending balance differs from the cleared balance by %.2f", detail.Difference)
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if _, err := qtx.ReconcileClearedTransactions(ctx, toSqlNullString(detail.AccountID)); err != nil {
+		return nil, fmt.Errorf("failed to reconcile transactions: %w", err)
+	}
+	if err := qtx.CompleteReconciliation(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to complete reconciliation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return s.GetReconciliation(ctx, id)
+}
+
+// DeleteReconciliation abandons an open reconciliation with its statement
+// lines; the transactions they were matched to are no longer cleared.
+// Transactions cleared by hand stay cleared.
+func (s *ReconciliationService) DeleteReconciliation(ctx context.Context, id string) error {
+	if _, _, err := s.getOpen(ctx, id); err != nil {
+		return err
+	}
+	lines, err := s.db.Queries().ListStatementLines(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to list statement lines: %w", err)
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	for _, line := range lines {
+		if !line.TransactionID.Valid {
+			continue
+		}
+		if err := qtx.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionUncleared, ID: line.TransactionID.String}); err != nil {
+			return fmt.Errorf("failed to unclear transaction: %w", err)
+		}
+	}
+	if err := qtx.DeleteReconciliation(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete reconciliation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UnlockTransaction takes a reconciled transaction of createdBy back to
+// cleared so it can be changed again. It stays cleared, so any change to
+// its amount shows as a difference in the next reconciliation of the
+// account. The unlock is recorded in the transaction's history.
+func (s *ReconciliationService) UnlockTransaction(ctx context.Context, createdBy, id string) (*db.Transaction, error) {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	transaction, err := getOwnedTransaction(ctx, qtx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.ClearedStatus != TransactionReconciled {
+		return nil, fmt.Errorf("transaction is not reconciled")
+	}
+	if err := qtx.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionCleared, ID: id}); err != nil {
+		return nil, fmt.Errorf("failed to unlock transaction: %w", err)
+	}
+	transaction.ClearedStatus = TransactionCleared
+	// Versions leave the cleared status out, so revert never relocks
+	unlocked := []FieldChange{{Field: "cleared_status", Old: TransactionReconciled, New: TransactionCleared}}
+	if err := appendHistory(ctx, qtx, HistoryUpdate, &transaction, unlocked); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &transaction, nil
+}
+
+// clearedBalance is the account's opening balance plus its cleared and
+// reconciled transactions and its transfers up to end, in minor units of
+// the account's currency
+func (s *ReconciliationService) clearedBalance(ctx context.Context, account *db.Account, end time.Time) (int64, error) {
+	activity, err := s.db.Queries().GetClearedActivity(ctx, toSqlNullString(account.ID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cleared transactions: %w", err)
+	}
+	transfers, err := s.db.Queries().ListTransfers(ctx, db.ListTransfersParams{
+		CreatedBy: account.CreatedBy,
+		AccountID: account.ID,
+		ToDate:    end.Format("2006-01-02"),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	converter, err := s.rates.newConverter(ctx, account.CreatedBy, account.Currency)
+	if err != nil {
+		return 0, err
+	}
+
+	balance := account.OpeningBalance
+	for _, row := range activity {
+		amount, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+		if err != nil {
+			return 0, err
+		}
+		switch row.Type {
+		case "income", "sale":
+			balance += amount
+		default:
+			balance -= amount
+		}
+	}
+	for _, t := range transfers {
+		if t.TransferDate.Before(truncateToDate(account.OpeningDate)) {
+			continue
+		}
+		if t.FromAccountID == account.ID {
+			balance -= t.Amount
+		} else {
+			balance += t.ToAmount
+		}
+	}
+	return balance, nil
+}
+
+// unmatchedTransactions lists the transactions of the account, dated within
+// windowDays of the statement period, that are neither reconciled nor
+// matched to a statement line
+func (s *ReconciliationService) unmatchedTransactions(ctx context.Context, reconciliation *db.Reconciliation, account *db.Account, lines []db.StatementLine, windowDays int) ([]db.Transaction, error) {
+	transactions, err := s.db.Queries().ListUnreconciledTransactions(ctx, db.ListUnreconciledTransactionsParams{
+		AccountID: toSqlNullString(account.ID),
+		FromDate:  reconciliation.StatementStartDate.AddDate(0, 0, -windowDays).Format("2006-01-02"),
+		ToDate:    reconciliation.StatementEndDate.AddDate(0, 0, windowDays).Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	matched := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		if line.TransactionID.Valid {
+			matched[line.TransactionID.String] = true
+		}
+	}
+
+	result := []db.Transaction{}
+	for _, t := range transactions {
+		if !matched[t.ID] {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// getOpen retrieves a reconciliation that is still open, with its account
+func (s *ReconciliationService) getOpen(ctx context.Context, id string) (*db.Reconciliation, *db.Account, error) {
+	reconciliation, err := s.db.Queries().GetReconciliation(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("reconciliation not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get reconciliation: %w", err)
+	}
+	if reconciliation.Status != "open" {
+		return nil, nil, fmt.Errorf("reconciliation is already completed")
+	}
+	account, err := s.getAccount(ctx, reconciliation.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &reconciliation, account, nil
+}
+
+func (s *ReconciliationService) getAccount(ctx context.Context, id string) (*db.Account, error) {
+	account, err := s.db.Queries().GetAccount(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("account not found")
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return &account, nil
+}
+
+func (s *ReconciliationService) getLine(ctx context.Context, id string) (*db.StatementLine, error) {
+	line, err := s.db.Queries().GetStatementLine(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("statement line not found")
+		}
+		return nil, fmt.Errorf("failed to get statement line: %w", err)
+	}
+	return &line, nil
+}
+
+// match links a statement line to a transaction and clears the transaction
+func match(ctx context.Context, q *db.Queries, lineID, transactionID, matchType string) error {
+	if err := q.SetStatementLineMatch(ctx, db.SetStatementLineMatchParams{
+		TransactionID: toSqlNullString(transactionID),
+		MatchType:     toSqlNullString(matchType),
+		ID:            lineID,
+	}); err != nil {
+		return fmt.Errorf("failed to match statement line: %w", err)
+	}
+	if err := q.SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: TransactionCleared, ID: transactionID}); err != nil {
+		return fmt.Errorf("failed to clear transaction: %w", err)
+	}
+	return nil
+}
+
+// checkUnlocked rejects changes to reconciled transactions
+func checkUnlocked(t *db.Transaction) error {
+	if t.ClearedStatus == TransactionReconciled {
+		return fmt.Errorf("transaction is reconciled; unlock it before changing it")
+	}
+	return nil
+}
+
+// signedAmount is what a transaction moved in or out of its account, in the
+// converter's currency: positive for income, negative for expenses
+func signedAmount(ctx context.Context, converter *currencyConverter, t *db.Transaction) (int64, error) {
+	amount, err := converter.convert(ctx, t.NetAmount.Int64, t.Currency.String, t.TransactionDate, t.ExchangeRate)
+	if err != nil {
+		return 0, err
+	}
+	switch t.Type {
+	case "income", "sale":
+		return amount, nil
+	}
+	return -amount, nil
+}
+
+// statementPeriod parses the dates of a statement
+func statementPeriod(params ReconciliationParams) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", params.StatementStartDate)
+	if err != nil {
+		return start, start, fmt.Errorf("invalid statement start date %q, expected YYYY-MM-DD", params.StatementStartDate)
+	}
+	end, err := time.Parse("2006-01-02", params.StatementEndDate)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid statement end date %q, expected YYYY-MM-DD", params.StatementEndDate)
+	}
+	if end.Before(start) {
+		return start, end, fmt.Errorf("statement ends before it starts")
+	}
+	return start, end, nil
+}
+
+func sameReference(a, b string) bool {
+	a = strings.TrimSpace(a)
+	return a != "" && strings.EqualFold(a, strings.TrimSpace(b))
+}
+
+func statementLineKey(date string, amount int64, description, reference string) string {
+	if reference != "" {
+		return "ref\x00" + reference
+	}
+	return fmt.Sprintf("%s\x00%d\x00%s", date, amount, strings.ToLower(strings.TrimSpace(description)))
+}
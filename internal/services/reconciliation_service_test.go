@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	db "cashflow/internal/db/sqlc"
+	"cashflow/internal/models"
+)
+
+func TestReconciledTransactionsAreLocked(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+	reconciliations := NewReconciliationService(d)
+
+	account, err := NewAccountService(d).CreateAccount(ctx, AccountParams{
+		Name:        "Checking",
+		Type:        "bank",
+		Currency:    "USD",
+		OpeningDate: "2024-01-01",
+		IsActive:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inAccount := func(p *CreateTransactionParams) { p.Account = account.ID }
+	locked := createTestTransaction(t, s, 10, inAccount)
+	open := createTestTransaction(t, s, 20, inAccount)
+
+	reconciliation, err := reconciliations.StartReconciliation(ctx, ReconciliationParams{
+		Account:            account.ID,
+		StatementStartDate: "2024-01-01",
+		StatementEndDate:   "2024-01-31",
+		EndingBalance:      -10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciliations.SetTransactionCleared(ctx, DefaultUserID, locked.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reconciliations.CompleteReconciliation(ctx, reconciliation.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[string]string{locked.ID: TransactionReconciled, open.ID: TransactionUncleared} {
+		transaction, err := s.GetTransaction(ctx, DefaultUserID, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transaction.ClearedStatus != want {
+			t.Fatalf("%s is %s after completing the reconciliation, want %s", id, transaction.ClearedStatus, want)
+		}
+	}
+
+	update := func(id string) error {
+		_, err := s.UpdateTransaction(ctx, DefaultUserID, id, UpdateTransactionParams{
+			Type:            "expense",
+			Description:     "Changed",
+			Amount:          99,
+			TransactionDate: "2024-01-15",
+			Currency:        "USD",
+			PaymentStatus:   "completed",
+			Account:         account.ID,
+		})
+		return err
+	}
+	changes := []struct {
+		name string
+		run  func(id string) error
+	}{
+		{"update", update},
+		{"delete", func(id string) error { return s.DeleteTransaction(ctx, DefaultUserID, id) }},
+		{"revert", func(id string) error {
+			_, err := s.RevertTransaction(ctx, DefaultUserID, id, 1)
+			return err
+		}},
+		{"bulk", func(id string) error {
+			result, err := s.BulkAddTags(ctx, DefaultUserID, []string{id}, []string{"locked"})
+			if err == nil && !result.Applied {
+				err = errors.New(result.Results[0].Error)
+			}
+			return err
+		}},
+		{"record payment", func(id string) error {
+			_, err := NewPaymentService(d).RecordPayment(ctx, RecordPaymentParams{TransactionID: id, Amount: 1, PaymentDate: "2024-01-20"})
+			return err
+		}},
+		{"unclear", func(id string) error {
+			_, err := reconciliations.SetTransactionCleared(ctx, DefaultUserID, id, false)
+			return err
+		}},
+	}
+	for _, change := range changes {
+		t.Run(change.name, func(t *testing.T) {
+			err := change.run(locked.ID)
+			if err == nil || !strings.Contains(err.Error(), "reconciled") {
+				t.Fatalf("got %v, want the reconciled transaction to be refused", err)
+			}
+			transaction, err := s.GetTransaction(ctx, DefaultUserID, locked.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if transaction.Amount != 1000 || transaction.Description != "Test" || transaction.ClearedStatus != TransactionReconciled {
+				t.Errorf("reconciled transaction changed: %d %q %s", transaction.Amount, transaction.Description, transaction.ClearedStatus)
+			}
+		})
+	}
+
+	// Transactions outside the reconciliation can still be changed
+	if err := update(open.ID); err != nil {
+		t.Errorf("updating an unreconciled transaction: %v", err)
+	}
+
+	// Unlocking takes the transaction back to cleared, so it can be changed
+	unlocked, err := reconciliations.UnlockTransaction(ctx, DefaultUserID, locked.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unlocked.ClearedStatus != TransactionCleared {
+		t.Errorf("unlocked transaction is %s, want %s", unlocked.ClearedStatus, TransactionCleared)
+	}
+	history, err := s.GetTransactionHistory(ctx, DefaultUserID, locked.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := history[len(history)-1]
+	if len(last.Changes) != 1 || last.Changes[0].Field != "cleared_status" || last.Changes[0].New != TransactionCleared {
+		t.Errorf("unlock recorded as %s %+v, want the cleared status change", last.Action, last.Changes)
+	}
+	if err := update(locked.ID); err != nil {
+		t.Errorf("updating an unlocked transaction: %v", err)
+	}
+	if _, err := reconciliations.UnlockTransaction(ctx, DefaultUserID, locked.ID); err == nil {
+		t.Error("unlocked a transaction that is not reconciled")
+	}
+}
+
+func TestReconcilingOtherUsersTransactions(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+	reconciliations := NewReconciliationService(d)
+
+	other, err := NewUserService(d).CreateUser(ctx, &models.UserCreateRequest{Name: "Other", Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := NewAccountService(d).CreateAccount(ctx, AccountParams{Name: "Checking", Currency: "USD", OpeningDate: "2024-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction := createTestTransaction(t, s, 10, func(p *CreateTransactionParams) { p.Account = account.ID })
+	reconciliation, err := reconciliations.StartReconciliation(ctx, ReconciliationParams{
+		Account:            account.ID,
+		StatementStartDate: "2024-01-01",
+		StatementEndDate:   "2024-01-31",
+		EndingBalance:      -10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail, err := reconciliations.AddStatementLines(ctx, reconciliation.ID, []StatementLineParams{{LineDate: "2024-01-15", Description: "Test", Amount: -10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lineID := detail.Lines[0].ID
+
+	tests := []struct {
+		name string
+		// status is the cleared status of the transaction beforehand
+		status string
+		run    func(createdBy string) error
+	}{
+		{"clear", TransactionUncleared, func(createdBy string) error {
+			_, err := reconciliations.SetTransactionCleared(ctx, createdBy, transaction.ID, true)
+			return err
+		}},
+		{"match", TransactionUncleared, func(createdBy string) error {
+			_, err := reconciliations.MatchStatementLine(ctx, createdBy, lineID, transaction.ID)
+			return err
+		}},
+		{"unlock", TransactionReconciled, func(createdBy string) error {
+			_, err := reconciliations.UnlockTransaction(ctx, createdBy, transaction.ID)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.Queries().SetTransactionClearedStatus(ctx, db.SetTransactionClearedStatusParams{ClearedStatus: tt.status, ID: transaction.ID}); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.run(other.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Fatalf("got %v, want not found", err)
+			}
+			current, err := s.GetTransaction(ctx, DefaultUserID, transaction.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if current.ClearedStatus != tt.status {
+				t.Errorf("transaction is %s, want it left %s", current.ClearedStatus, tt.status)
+			}
+			if err := tt.run(DefaultUserID); err != nil {
+				t.Errorf("owner: %v", err)
+			}
+		})
+	}
+}
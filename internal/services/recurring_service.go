@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// recurringCheckInterval is how often the scheduler looks for new occurrences
+// while the app stays open (e.g. across midnight)
+const recurringCheckInterval = time.Hour
+
+// RecurringService materializes occurrences of recurring transactions
+type RecurringService struct {
+	db     *database.Database
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func NewRecurringService(db *database.Database) *RecurringService {
+	return &RecurringService{db: db}
+}
+
+// Start catches up on every occurrence missed while the app was closed and
+// then keeps checking periodically until Stop is called
+func (s *RecurringService) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	if _, err := s.ProcessDueOccurrences(ctx, time.Now()); err != nil {
+		log.Printf("recurring: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(recurringCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if _, err := s.ProcessDueOccurrences(ctx, now); err != nil {
+					log.Printf("recurring: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop halts the periodic scheduler
+func (s *RecurringService) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// ProcessDueOccurrences creates every occurrence due on or before asOf for all
+// recurring transactions and returns the number of rows created. A recurring
+// transaction whose occurrences cannot be created, such as one with an
+// unknown frequency, is logged and skipped so the others still get theirs.
+func (s *RecurringService) ProcessDueOccurrences(ctx context.Context, asOf time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parents, err := s.db.Queries().ListRecurringTransactions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list recurring transactions: %w", err)
+	}
+
+	until := truncateToDate(asOf)
+	created := 0
+	for _, parent := range parents {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+		n, err := s.materialize(ctx, parent, until)
+		if err != nil {
+			log.Printf("recurring: skipping %s: failed to create occurrences: %v", parent.ID, err)
+			continue
+		}
+		created += n
+	}
+	return created, nil
+}
+
+// materialize inserts the missing occurrences of a single recurring
+// transaction inside one database transaction
+func (s *RecurringService) materialize(ctx context.Context, parent db.Transaction, until time.Time) (int, error) {
+	if parent.RecurringEndDate.Valid {
+		if end := truncateToDate(parent.RecurringEndDate.Time); end.Before(until) {
+			until = end
+		}
+	}
+
+	anchor := truncateToDate(parent.TransactionDate)
+	parentID := toSqlNullString(parent.ID)
+
+	// Occurrences on or before the latest one already created (even if it
+	// was deleted since) are never generated again
+	latest, err := s.db.Queries().GetLatestRecurringOccurrenceDate(ctx, parentID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	watermark := anchor
+	if err == nil && truncateToDate(latest).After(watermark) {
+		watermark = truncateToDate(latest)
+	}
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	queries := s.db.Queries().WithTx(tx)
+
+	var created []db.Transaction
+	for i := 1; ; i++ {
+		date, err := recurringOccurrence(anchor, parent.RecurringFrequency.String, i)
+		if err != nil {
+			return 0, err
+		}
+		if date.After(until) {
+			break
+		}
+		if !date.After(watermark) {
+			continue
+		}
+
+		rows, err := queries.CreateRecurringOccurrence(ctx, db.CreateRecurringOccurrenceParams{
+			Type:                parent.Type,
+			Description:         parent.Description,
+			Amount:              parent.Amount,
+			TransactionDate:     date,
+			CategoryID:          parent.CategoryID,
+			Tags:                parent.Tags,
+			CustomerVendor:      parent.CustomerVendor,
+			PaymentMethodID:     parent.PaymentMethodID,
+			PaymentStatus:       parent.PaymentStatus,
+			ReferenceNumber:     parent.ReferenceNumber,
+			InvoiceNumber:       parent.InvoiceNumber,
+			Notes:               parent.Notes,
+			Attachments:         parent.Attachments,
+			TaxAmount:           parent.TaxAmount,
+			DiscountAmount:      parent.DiscountAmount,
+			DueAmount:           parent.DueAmount,
+			Currency:            parent.Currency,
+			ExchangeRate:        parent.ExchangeRate,
+			ParentTransactionID: parentID,
+			CreatedBy:           parent.CreatedBy,
+			ContactID:           parent.ContactID,
+			AccountID:           parent.AccountID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if rows == 0 {
+			continue
+		}
+		occurrence, err := queries.GetRecurringOccurrence(ctx, db.GetRecurringOccurrenceParams{
+			ParentTransactionID: parentID,
+			TransactionDate:     date,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := recordHistory(ctx, queries, HistoryCreate, nil, &occurrence); err != nil {
+			return 0, err
+		}
+		created = append(created, occurrence)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	for i := range created {
+		s.db.TransactionCommitted(ctx, nil, &created[i])
+	}
+	return len(created), nil
+}
+
+// recurringOccurrence returns the n-th occurrence after anchor. Month based
+// frequencies are always computed from the anchor so that e.g. the 31st
+// clamps to the end of shorter months without drifting afterwards.
+func recurringOccurrence(anchor time.Time, frequency string, n int) (time.Time, error) {
+	switch frequency {
+	case "daily":
+		return anchor.AddDate(0, 0, n), nil
+	case "weekly":
+		return anchor.AddDate(0, 0, 7*n), nil
+	case "monthly":
+		return addMonthsClamped(anchor, n), nil
+	case "quarterly":
+		return addMonthsClamped(anchor, 3*n), nil
+	case "yearly":
+		return addMonthsClamped(anchor, 12*n), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported recurring frequency %q", frequency)
+	}
+}
+
+// addMonthsClamped adds months to t, clamping the day to the last day of the
+// resulting month instead of overflowing into the next one
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, months, 0)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+// truncateToDate drops the time of day, keeping the calendar date in UTC the
+// same way transaction dates are parsed and stored
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func mustDate(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestAddMonthsClamped(t *testing.T) {
+	tests := []struct {
+		from   string
+		months int
+		want   string
+	}{
+		{"2024-01-15", 1, "2024-02-15"},
+		{"2024-01-31", 1, "2024-02-29"},
+		{"2023-01-31", 1, "2023-02-28"},
+		{"2024-01-31", 2, "2024-03-31"},
+		{"2024-01-31", 3, "2024-04-30"},
+		{"2024-03-31", -1, "2024-02-29"},
+		{"2024-10-31", 4, "2025-02-28"},
+		{"2024-12-31", 1, "2025-01-31"},
+		{"2024-02-29", 12, "2025-02-28"},
+		{"2024-02-29", 48, "2028-02-29"},
+		{"2024-05-31", 0, "2024-05-31"},
+	}
+	for _, tt := range tests {
+		if got := addMonthsClamped(mustDate(tt.from), tt.months).Format("2006-01-02"); got != tt.want {
+			t.Errorf("addMonthsClamped(%s, %d) = %s, want %s", tt.from, tt.months, got, tt.want)
+		}
+	}
+}
+
+func TestRecurringOccurrence(t *testing.T) {
+	tests := []struct {
+		anchor    string
+		frequency string
+		n         int
+		want      string
+	}{
+		{"2024-01-31", "daily", 1, "2024-02-01"},
+		{"2024-02-26", "weekly", 1, "2024-03-04"},
+		{"2024-01-31", "monthly", 1, "2024-02-29"},
+		// Computed from the anchor, so the 31st comes back after February
+		{"2024-01-31", "monthly", 2, "2024-03-31"},
+		{"2024-01-31", "monthly", 3, "2024-04-30"},
+		{"2024-11-30", "quarterly", 1, "2025-02-28"},
+		{"2024-11-30", "quarterly", 2, "2025-05-30"},
+		{"2024-02-29", "yearly", 1, "2025-02-28"},
+		{"2024-02-29", "yearly", 4, "2028-02-29"},
+	}
+	for _, tt := range tests {
+		got, err := recurringOccurrence(mustDate(tt.anchor), tt.frequency, tt.n)
+		if err != nil {
+			t.Errorf("recurringOccurrence(%s, %s, %d): %v", tt.anchor, tt.frequency, tt.n, err)
+			continue
+		}
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("recurringOccurrence(%s, %s, %d) = %s, want %s", tt.anchor, tt.frequency, tt.n, got.Format("2006-01-02"), tt.want)
+		}
+	}
+
+	if _, err := recurringOccurrence(mustDate("2024-01-01"), "fortnightly", 1); err == nil {
+		t.Error("recurringOccurrence accepted an unsupported frequency")
+	}
+}
+
+func TestProcessDueOccurrencesSkipsBadFrequency(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+
+	bad := createTestTransaction(t, s, 10, func(p *CreateTransactionParams) {
+		p.IsRecurring = true
+		p.RecurringFrequency = "monthly"
+		p.TransactionDate = "2024-01-01"
+	})
+	// The frequency is checked on save, so only an older database can have
+	// one that is not known
+	if _, err := d.Conn().ExecContext(ctx, `PRAGMA ignore_check_constraints = ON`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Conn().ExecContext(ctx, `UPDATE transactions SET recurring_frequency = 'fortnightly' WHERE id = ?`, bad.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Conn().ExecContext(ctx, `PRAGMA ignore_check_constraints = OFF`); err != nil {
+		t.Fatal(err)
+	}
+	good := createTestTransaction(t, s, 20, func(p *CreateTransactionParams) {
+		p.IsRecurring = true
+		p.RecurringFrequency = "monthly"
+		p.TransactionDate = "2024-01-01"
+	})
+
+	created, err := NewRecurringService(d).ProcessDueOccurrences(ctx, mustDate("2024-03-15"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 2 {
+		t.Errorf("created %d occurrence(s), want 2 of %s", created, good.ID)
+	}
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+// Report periods
+const (
+	PeriodDay     = "day"
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+)
+
+// defaultTopCounterparties is how many counterparties a report lists per
+// period when no limit is given
+const defaultTopCounterparties = 10
+
+// ReportService builds time-series reports for the dashboard. Amounts are
+// converted to the reporting currency like the other statistics.
+type ReportService struct {
+	db         *database.Database
+	currencies *CurrencyService
+	rates      *ExchangeRateService
+}
+
+func NewReportService(db *database.Database) *ReportService {
+	return &ReportService{
+		db:         db,
+		currencies: NewCurrencyService(db),
+		rates:      NewExchangeRateService(db),
+	}
+}
+
+// ReportParams selects the transactions of a report and how they are
+// grouped. Types limits the report to some transaction types. Period is
+// one of day, week, month, quarter or year; each report has its own default.
+type ReportParams struct {
+	CreatedBy string   `json:"created_by"`
+	FromDate  string   `json:"from_date"`
+	ToDate    string   `json:"to_date"`
+	Types     []string `json:"types,omitempty"`
+	Period    string   `json:"period,omitempty"`
+	Currency  string   `json:"currency,omitempty"` // reporting currency, defaults to the base currency
+	Limit     int      `json:"limit,omitempty"`    // top counterparties per period
+}
+
+// ReportBucket is one period of a report. StartDate and EndDate are
+// inclusive; Label is 2024-01-15, 2024-W03, 2024-01, 2024-Q1 or 2024.
+type ReportBucket struct {
+	Label     string `json:"label"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// TypeTotal is the count and net amount of one transaction type
+type TypeTotal struct {
+	Count int     `json:"count"`
+	Total float64 `json:"total"`
+}
+
+// TrendPoint holds the totals per transaction type of one period
+type TrendPoint struct {
+	ReportBucket
+	Types map[string]TypeTotal `json:"types"`
+}
+
+// TrendReport is the net amount per transaction type over time
+type TrendReport struct {
+	Currency    string              `json:"currency"`
+	Period      string              `json:"period"`
+	Points      []TrendPoint        `json:"points"`
+	Unconverted []UnconvertedAmount `json:"unconverted,omitempty"`
+}
+
+// CashFlowPoint holds the income and expenses of one period. Income counts
+// income and sales, expenses count expenses and purchases.
+type CashFlowPoint struct {
+	ReportBucket
+	Income           float64 `json:"income"`
+	Expenses         float64 `json:"expenses"`
+	Net              float64 `json:"net"`
+	TransactionCount int     `json:"transaction_count"`
+}
+
+// CashFlowReport is income, expenses and their difference over time
+type CashFlowReport struct {
+	Currency      string              `json:"currency"`
+	Period        string              `json:"period"`
+	Points        []CashFlowPoint     `json:"points"`
+	TotalIncome   float64             `json:"total_income"`
+	TotalExpenses float64             `json:"total_expenses"`
+	TotalNet      float64             `json:"total_net"`
+	Unconverted   []UnconvertedAmount `json:"unconverted,omitempty"`
+}
+
+// CounterpartyTotal is the net amount of one customer or vendor and type
+type CounterpartyTotal struct {
+	Name             string  `json:"name"`
+	Type             string  `json:"type"`
+	TransactionCount int     `json:"transaction_count"`
+	Total            float64 `json:"total"`
+}
+
+// CounterpartyPoint lists the largest counterparties of one period
+type CounterpartyPoint struct {
+	ReportBucket
+	Counterparties []CounterpartyTotal `json:"counterparties"`
+}
+
+// CounterpartyReport ranks customers and vendors by net amount. Without a
+// period it has a single point covering the whole date range.
+type CounterpartyReport struct {
+	Currency    string              `json:"currency"`
+	Period      string              `json:"period"`
+	Points      []CounterpartyPoint `json:"points"`
+	Unconverted []UnconvertedAmount `json:"unconverted,omitempty"`
+}
+
+// GetTrend totals each transaction type per period, monthly by default
+func (s *ReportService) GetTrend(ctx context.Context, params ReportParams) (*TrendReport, error) {
+	params, err := normalizeReportParams(params, PeriodMonth)
+	if err != nil {
+		return nil, err
+	}
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Queries().GetMonthlyTrend(ctx, db.GetMonthlyTrendParams{
+		CreatedBy:  params.CreatedBy,
+		FromDate:   params.FromDate,
+		ToDate:     params.ToDate,
+		TypeFilter: arrayToCommaSeparated(params.Types),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trend: %w", err)
+	}
+
+	type key struct {
+		bucket string
+		txType string
+	}
+	totals := make(map[key]int64)
+	counts := make(map[key]int)
+	var dates []time.Time
+	for _, row := range rows {
+		converted, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+		if err != nil {
+			if err := converter.setAside(err, row.Currency, row.Type, row.TotalAmount); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		k := key{reportBucketFor(row.TransactionDate, params.Period).Label, row.Type}
+		totals[k] += converted
+		counts[k] += int(row.Count)
+		dates = append(dates, row.TransactionDate)
+	}
+
+	exponent := s.currencies.Exponent(ctx, converter.target)
+	report := &TrendReport{Currency: converter.target, Period: params.Period, Points: []TrendPoint{}}
+	for _, bucket := range reportBuckets(params, dates) {
+		point := TrendPoint{ReportBucket: bucket, Types: make(map[string]TypeTotal)}
+		for k, total := range totals {
+			if k.bucket == bucket.Label {
+				point.Types[k.txType] = TypeTotal{Count: counts[k], Total: fromMinorUnits(total, exponent)}
+			}
+		}
+		report.Points = append(report.Points, point)
+	}
+	report.Unconverted = converter.unconvertedAmounts(ctx)
+	return report, nil
+}
+
+// GetCashFlow totals income and expenses per period, daily by default
+func (s *ReportService) GetCashFlow(ctx context.Context, params ReportParams) (*CashFlowReport, error) {
+	params, err := normalizeReportParams(params, PeriodDay)
+	if err != nil {
+		return nil, err
+	}
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Queries().GetDailyTransactionSummary(ctx, db.GetDailyTransactionSummaryParams{
+		CreatedBy:  params.CreatedBy,
+		FromDate:   params.FromDate,
+		ToDate:     params.ToDate,
+		TypeFilter: arrayToCommaSeparated(params.Types),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cash flow: %w", err)
+	}
+
+	type totals struct {
+		income, expenses int64
+		count            int
+	}
+	byBucket := make(map[string]*totals)
+	var dates []time.Time
+	for _, row := range rows {
+		income, err := converter.convert(ctx, row.DailyIncome, row.Currency, row.TransactionDate, row.ExchangeRate)
+		var expenses int64
+		if err == nil {
+			expenses, err = converter.convert(ctx, row.DailyExpense, row.Currency, row.TransactionDate, row.ExchangeRate)
+		}
+		if err != nil {
+			if err := converter.setAside(err, row.Currency, "income", row.DailyIncome); err != nil {
+				return nil, err
+			}
+			converter.setAside(err, row.Currency, "expense", row.DailyExpense)
+			continue
+		}
+		label := reportBucketFor(row.TransactionDate, params.Period).Label
+		t := byBucket[label]
+		if t == nil {
+			t = &totals{}
+			byBucket[label] = t
+		}
+		t.income += income
+		t.expenses += expenses
+		t.count += int(row.TransactionCount)
+		dates = append(dates, row.TransactionDate)
+	}
+
+	exponent := s.currencies.Exponent(ctx, converter.target)
+	report := &CashFlowReport{Currency: converter.target, Period: params.Period, Points: []CashFlowPoint{}}
+	var income, expenses int64
+	for _, bucket := range reportBuckets(params, dates) {
+		point := CashFlowPoint{ReportBucket: bucket}
+		if t := byBucket[bucket.Label]; t != nil {
+			point.Income = fromMinorUnits(t.income, exponent)
+			point.Expenses = fromMinorUnits(t.expenses, exponent)
+			point.Net = fromMinorUnits(t.income-t.expenses, exponent)
+			point.TransactionCount = t.count
+			income += t.income
+			expenses += t.expenses
+		}
+		report.Points = append(report.Points, point)
+	}
+	report.TotalIncome = fromMinorUnits(income, exponent)
+	report.TotalExpenses = fromMinorUnits(expenses, exponent)
+	report.TotalNet = fromMinorUnits(income-expenses, exponent)
+	report.Unconverted = converter.unconvertedAmounts(ctx)
+	return report, nil
+}
+
+// GetTopCounterparties ranks customers and vendors by net amount, per period
+// when one is given and over the whole date range otherwise
+func (s *ReportService) GetTopCounterparties(ctx context.Context, params ReportParams) (*CounterpartyReport, error) {
+	params, err := normalizeReportParams(params, "")
+	if err != nil {
+		return nil, err
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultTopCounterparties
+	}
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Queries().GetTopCustomersVendors(ctx, db.GetTopCustomersVendorsParams{
+		CreatedBy:  params.CreatedBy,
+		FromDate:   params.FromDate,
+		ToDate:     params.ToDate,
+		TypeFilter: arrayToCommaSeparated(params.Types),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top customers and vendors: %w", err)
+	}
+
+	type key struct {
+		bucket, name, txType string
+	}
+	totals := make(map[key]int64)
+	counts := make(map[key]int)
+	var dates []time.Time
+	for _, row := range rows {
+		converted, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+		if err != nil {
+			if err := converter.setAside(err, row.Currency, row.Type, row.TotalAmount); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		label := ""
+		if params.Period != "" {
+			label = reportBucketFor(row.TransactionDate, params.Period).Label
+		}
+		k := key{label, row.CustomerVendor.String, row.Type}
+		totals[k] += converted
+		counts[k] += int(row.TransactionCount)
+		dates = append(dates, row.TransactionDate)
+	}
+
+	buckets := []ReportBucket{{StartDate: params.FromDate, EndDate: params.ToDate}}
+	if params.Period != "" {
+		buckets = reportBuckets(params, dates)
+	}
+
+	exponent := s.currencies.Exponent(ctx, converter.target)
+	report := &CounterpartyReport{Currency: converter.target, Period: params.Period, Points: []CounterpartyPoint{}}
+	for _, bucket := range buckets {
+		point := CounterpartyPoint{ReportBucket: bucket, Counterparties: []CounterpartyTotal{}}
+		for k, total := range totals {
+			if k.bucket == bucket.Label {
+				point.Counterparties = append(point.Counterparties, CounterpartyTotal{
+					Name:             k.name,
+					Type:             k.txType,
+					TransactionCount: counts[k],
+					Total:            fromMinorUnits(total, exponent),
+				})
+			}
+		}
+		sort.Slice(point.Counterparties, func(i, j int) bool {
+			a, b := point.Counterparties[i], point.Counterparties[j]
+			if a.Total != b.Total {
+				return a.Total > b.Total
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			return a.Type < b.Type
+		})
+		if len(point.Counterparties) > params.Limit {
+			point.Counterparties = point.Counterparties[:params.Limit]
+		}
+		report.Points = append(report.Points, point)
+	}
+	report.Unconverted = converter.unconvertedAmounts(ctx)
+	return report, nil
+}
+
+// normalizeReportParams fills in defaults and validates the period and dates
+func normalizeReportParams(params ReportParams, defaultPeriod string) (ReportParams, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	params.Period = strings.ToLower(strings.TrimSpace(params.Period))
+	if params.Period == "" {
+		params.Period = defaultPeriod
+	}
+	switch params.Period {
+	case "", PeriodDay, PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear:
+	default:
+		return params, fmt.Errorf("invalid report period %q", params.Period)
+	}
+
+	for _, date := range []string{params.FromDate, params.ToDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return params, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", date)
+		}
+	}
+	if params.FromDate != "" && params.ToDate != "" && params.FromDate > params.ToDate {
+		return params, fmt.Errorf("from date is after to date")
+	}
+	return params, nil
+}
+
+// reportBucketFor returns the period containing date. Weeks start on Monday
+// and are labelled with their ISO week number.
+func reportBucketFor(date time.Time, period string) ReportBucket {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	var start, end time.Time
+	var label string
+	switch period {
+	case PeriodWeek:
+		start = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		end = start.AddDate(0, 0, 6)
+		year, week := day.ISOWeek()
+		label = fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodMonth:
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, -1)
+		label = start.Format("2006-01")
+	case PeriodQuarter:
+		quarter := (int(day.Month()) - 1) / 3
+		start = time.Date(day.Year(), time.Month(quarter*3+1), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 3, -1)
+		label = fmt.Sprintf("%d-Q%d", day.Year(), quarter+1)
+	case PeriodYear:
+		start = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(1, 0, -1)
+		label = start.Format("2006")
+	default:
+		start, end = day, day
+		label = day.Format("2006-01-02")
+	}
+	return ReportBucket{Label: label, StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02")}
+}
+
+// reportBuckets lists every period from the start of the date range, or the
+// first transaction, to the end of the range, or the last transaction, so
+// periods without transactions still appear in charts
+func reportBuckets(params ReportParams, dates []time.Time) []ReportBucket {
+	var first, last time.Time
+	for _, date := range dates {
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if last.IsZero() || date.After(last) {
+			last = date
+		}
+	}
+	if from, err := time.Parse("2006-01-02", params.FromDate); err == nil {
+		first = from
+	}
+	if to, err := time.Parse("2006-01-02", params.ToDate); err == nil {
+		last = to
+	}
+	if first.IsZero() || last.IsZero() {
+		return nil
+	}
+
+	var buckets []ReportBucket
+	for bucket := reportBucketFor(first, params.Period); bucket.StartDate <= last.Format("2006-01-02"); {
+		buckets = append(buckets, bucket)
+		end, _ := time.Parse("2006-01-02", bucket.EndDate)
+		bucket = reportBucketFor(end.AddDate(0, 0, 1), params.Period)
+	}
+	return buckets
+}
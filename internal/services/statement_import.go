@@ -0,0 +1,372 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	db "cashflow/internal/db/sqlc"
+)
+
+// Statement file formats
+const (
+	StatementFormatOFX = "ofx"
+	StatementFormatQIF = "qif"
+)
+
+// StatementImportOptions applies to OFX/QFX and QIF files. Currency is used
+// when the file does not name one. DateFormat is only read for QIF, whose
+// dates are month first unless it says otherwise. Account is the account
+// the statement is for, which imported transactions are put in.
+type StatementImportOptions struct {
+	Currency   string `json:"currency"`
+	DateFormat string `json:"date_format"`
+	Account    string `json:"account,omitempty"`
+	CreatedBy  string `json:"created_by"`
+}
+
+// DetectStatementFormat tells OFX/QFX from QIF by file extension, falling
+// back to the start of the contents. It returns "" for anything else.
+func DetectStatementFormat(name string, head []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".ofx", ".qfx":
+		return StatementFormatOFX
+	case ".qif":
+		return StatementFormatQIF
+	}
+
+	upper := bytes.ToUpper(bytes.TrimSpace(head))
+	switch {
+	case bytes.Contains(upper, []byte("OFXHEADER")), bytes.Contains(upper, []byte("<OFX>")):
+		return StatementFormatOFX
+	case bytes.HasPrefix(upper, []byte("!TYPE:")), bytes.HasPrefix(upper, []byte("!ACCOUNT")), bytes.HasPrefix(upper, []byte("!OPTION")):
+		return StatementFormatQIF
+	}
+	return ""
+}
+
+// PreviewStatement parses an OFX/QFX or QIF file, detecting the format from
+// its name and contents
+func (s *ImportService) PreviewStatement(ctx context.Context, name string, r io.Reader, opts StatementImportOptions) (*ImportPreview, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	switch DetectStatementFormat(name, data[:min(len(data), 512)]) {
+	case StatementFormatOFX:
+		return s.PreviewOFX(ctx, bytes.NewReader(data), opts)
+	case StatementFormatQIF:
+		return s.PreviewQIF(ctx, bytes.NewReader(data), opts)
+	}
+	return nil, fmt.Errorf("%s is not an OFX, QFX or QIF file", filepath.Base(name))
+}
+
+// ImportStatement imports an OFX/QFX or QIF file in a single database
+// transaction, skipping transactions imported before
+func (s *ImportService) ImportStatement(ctx context.Context, name string, r io.Reader, opts StatementImportOptions) (*ImportResult, error) {
+	preview, err := s.PreviewStatement(ctx, name, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.commit(ctx, preview)
+}
+
+// PreviewOFX parses an OFX or QFX statement, SGML (1.x) or XML (2.x).
+// Credits become income and debits expenses. The bank's FITID is kept as
+// the reference number. FITIDs are only unique within a bank account, so
+// transactions are marked as duplicates when their FITID was imported into
+// the same account before, or appears twice for one ACCTID in the file.
+func (s *ImportService) PreviewOFX(ctx context.Context, r io.Reader, opts StatementImportOptions) (*ImportPreview, error) {
+	statement, err := parseOFX(r)
+	if err != nil {
+		return nil, err
+	}
+
+	createdBy := opts.CreatedBy
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	type fitKey struct{ acctID, fitID string }
+	seen := make(map[fitKey]bool)
+	var rows []ImportRow
+	for _, t := range statement {
+		row := ImportRow{Line: t.line}
+		params := CreateTransactionParams{
+			Description:     t.fields["NAME"],
+			CustomerVendor:  t.fields["NAME"],
+			ReferenceNumber: t.fields["FITID"],
+			Account:         opts.Account,
+			Currency:        t.currency,
+			CreatedBy:       opts.CreatedBy,
+		}
+		if params.Currency == "" {
+			params.Currency = opts.Currency
+		}
+		if memo := t.fields["MEMO"]; memo != "" {
+			if params.Description == "" {
+				params.Description = memo
+			} else if memo != params.Description {
+				params.Notes = memo
+			}
+		}
+
+		// Dates are YYYYMMDD, optionally followed by a time and zone
+		params.TransactionDate = t.fields["DTPOSTED"]
+		if len(params.TransactionDate) >= 8 {
+			if date, err := time.Parse("20060102", params.TransactionDate[:8]); err == nil {
+				params.TransactionDate = date.Format("2006-01-02")
+			}
+		}
+
+		amount, err := strconv.ParseFloat(strings.Replace(t.fields["TRNAMT"], ",", ".", 1), 64)
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("invalid amount %q", t.fields["TRNAMT"]))
+		}
+		params.Type = "income"
+		if amount < 0 {
+			params.Type = "expense"
+			amount = -amount
+		}
+		params.Amount = amount
+
+		row.Transaction = params
+		row.Errors = append(row.Errors, s.validateRow(ctx, params)...)
+		if fitID := params.ReferenceNumber; fitID != "" {
+			key := fitKey{t.acctID, fitID}
+			if seen[key] {
+				row.Duplicate = true
+			} else {
+				count, err := s.db.Queries().CountTransactionsByReference(ctx, db.CountTransactionsByReferenceParams{
+					CreatedBy:       createdBy,
+					AccountID:       opts.Account,
+					ReferenceNumber: toSqlNullString(fitID),
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to check for imported transactions: %w", err)
+				}
+				row.Duplicate = count > 0
+			}
+			seen[key] = true
+		}
+		rows = append(rows, row)
+	}
+	return newImportPreview(rows), nil
+}
+
+// ImportOFX imports an OFX or QFX statement, see PreviewOFX
+func (s *ImportService) ImportOFX(ctx context.Context, r io.Reader, opts StatementImportOptions) (*ImportResult, error) {
+	preview, err := s.PreviewOFX(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.commit(ctx, preview)
+}
+
+// PreviewQIF parses the bank, cash and credit card sections of a QIF file.
+// Positive amounts become income and negative ones expenses. Categories
+// are matched by name, or by their last part for Quicken's Parent:Child
+// names, and left empty when there is no match.
+func (s *ImportService) PreviewQIF(ctx context.Context, r io.Reader, opts StatementImportOptions) (*ImportPreview, error) {
+	layouts := []string{"1/2/2006", "1/2/06"}
+	if opts.DateFormat != "" {
+		layouts = []string{dateLayout(opts.DateFormat)}
+	}
+
+	resolver := newNameResolver(s.db.Queries())
+	var rows []ImportRow
+	var record map[byte]string
+	recordLine := 0
+	inTransactions := false
+
+	flush := func() {
+		if record == nil {
+			return
+		}
+		row := ImportRow{Line: recordLine}
+		params := CreateTransactionParams{
+			Description:     record['P'],
+			CustomerVendor:  record['P'],
+			ReferenceNumber: record['N'],
+			Account:         opts.Account,
+			Currency:        opts.Currency,
+			CreatedBy:       opts.CreatedBy,
+		}
+		if memo := record['M']; memo != "" {
+			if params.Description == "" {
+				params.Description = memo
+			} else if memo != params.Description {
+				params.Notes = memo
+			}
+		}
+
+		params.TransactionDate = record['D']
+		value := strings.NewReplacer("'", "/", " ", "").Replace(record['D'])
+		for _, layout := range layouts {
+			if date, err := time.Parse(layout, value); err == nil {
+				params.TransactionDate = date.Format("2006-01-02")
+				break
+			}
+		}
+
+		amountText := record['T']
+		if amountText == "" {
+			amountText = record['U']
+		}
+		amount, err := parseDecimal(amountText, ".")
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("invalid amount %q", amountText))
+		}
+		params.Type = "income"
+		if amount < 0 {
+			params.Type = "expense"
+			amount = -amount
+		}
+		params.Amount = amount
+
+		// Bracketed categories are transfers between accounts
+		if category := record['L']; category != "" && !strings.HasPrefix(category, "[") {
+			if id, err := resolver.category(ctx, category); err == nil {
+				params.Category = id
+			} else if i := strings.LastIndex(category, ":"); i >= 0 {
+				if id, err := resolver.category(ctx, category[i+1:]); err == nil {
+					params.Category = id
+				}
+			}
+		}
+
+		row.Transaction = params
+		row.Errors = append(row.Errors, s.validateRow(ctx, params)...)
+		rows = append(rows, row)
+		record = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if text[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(text))
+			switch {
+			case strings.HasPrefix(header, "!type:"):
+				kind := strings.TrimSpace(strings.TrimPrefix(header, "!type:"))
+				inTransactions = kind == "bank" || kind == "cash" || kind == "ccard" || kind == "oth a" || kind == "oth l"
+			case strings.HasPrefix(header, "!account"):
+				inTransactions = false
+			}
+			continue
+		}
+		if !inTransactions {
+			continue
+		}
+
+		if text[0] == '^' {
+			flush()
+			continue
+		}
+		if record == nil {
+			record = make(map[byte]string)
+			recordLine = line
+		}
+		// Split lines (S, E, $) and addresses are not imported
+		if _, ok := record[text[0]]; !ok {
+			record[text[0]] = strings.TrimSpace(text[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read QIF file: %w", err)
+	}
+	flush()
+	return newImportPreview(rows), nil
+}
+
+// ImportQIF imports a QIF file, see PreviewQIF
+func (s *ImportService) ImportQIF(ctx context.Context, r io.Reader, opts StatementImportOptions) (*ImportResult, error) {
+	preview, err := s.PreviewQIF(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.commit(ctx, preview)
+}
+
+// ofxTransaction is a STMTTRN aggregate with the currency and bank account
+// of its statement
+type ofxTransaction struct {
+	line     int
+	currency string
+	acctID   string
+	fields   map[string]string
+}
+
+// parseOFX collects the statement transactions of an OFX document. Elements
+// are read as <TAG>value, which covers both the SGML form, where closing
+// tags are optional, and XML.
+func parseOFX(r io.Reader) ([]ofxTransaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OFX file: %w", err)
+	}
+	text := string(data)
+	start := strings.Index(strings.ToUpper(text), "<OFX>")
+	if start < 0 {
+		return nil, fmt.Errorf("not an OFX file: no <OFX> element")
+	}
+
+	var transactions []ofxTransaction
+	var current *ofxTransaction
+	currency, acctID := "", ""
+	line := 1 + strings.Count(text[:start], "\n")
+	for pos := start; pos < len(text); {
+		open := strings.IndexByte(text[pos:], '<')
+		if open < 0 {
+			break
+		}
+		line += strings.Count(text[pos:pos+open], "\n")
+		pos += open
+		end := strings.IndexByte(text[pos:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: unterminated tag", line)
+		}
+		tag := strings.ToUpper(strings.TrimSpace(text[pos+1 : pos+end]))
+		pos += end + 1
+
+		next := strings.IndexByte(text[pos:], '<')
+		if next < 0 {
+			next = len(text) - pos
+		}
+		value := html.UnescapeString(strings.TrimSpace(text[pos : pos+next]))
+
+		switch {
+		case tag == "STMTTRN":
+			current = &ofxTransaction{line: line, currency: currency, acctID: acctID, fields: make(map[string]string)}
+		case tag == "/STMTTRN":
+			if current != nil {
+				transactions = append(transactions, *current)
+				current = nil
+			}
+		case tag == "CURDEF":
+			currency = value
+		case tag == "ACCTID" && current == nil:
+			acctID = value
+		case strings.HasPrefix(tag, "/"):
+		case current != nil && value != "":
+			if _, ok := current.fields[tag]; !ok {
+				current.fields[tag] = value
+			}
+		}
+	}
+	return transactions, nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// ofxStatement is an OFX document with a statement per bank account, each
+// holding one debit with the FITID fitID
+func ofxStatement(fitID string, acctIDs ...string) string {
+	var b strings.Builder
+	b.WriteString("OFXHEADER:100\n<OFX><BANKMSGSRSV1>\n")
+	for _, acctID := range acctIDs {
+		fmt.Fprintf(&b, `<STMTTRNRS><STMTRS><CURDEF>USD
+<BANKACCTFROM><BANKID>123<ACCTID>%s<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240115<TRNAMT>-12.50<FITID>%s<NAME>Coffee</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS>
+`, acctID, fitID)
+	}
+	b.WriteString("</BANKMSGSRSV1></OFX>\n")
+	return b.String()
+}
+
+func TestOFXDuplicatesAreFoundPerAccount(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewImportService(d)
+	accounts := NewAccountService(d)
+
+	var accountIDs []string
+	for _, name := range []string{"Checking", "Savings"} {
+		account, err := accounts.CreateAccount(ctx, AccountParams{Name: name, Currency: "USD", OpeningDate: "2024-01-01"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		accountIDs = append(accountIDs, account.ID)
+	}
+	checking, savings := accountIDs[0], accountIDs[1]
+
+	duplicates := func(statement, account string) []bool {
+		t.Helper()
+		preview, err := s.PreviewOFX(ctx, strings.NewReader(statement), StatementImportOptions{Account: account})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []bool
+		for _, row := range preview.Rows {
+			if len(row.Errors) > 0 {
+				t.Fatalf("line %d: %v", row.Line, row.Errors)
+			}
+			found = append(found, row.Duplicate)
+		}
+		return found
+	}
+
+	// The same FITID from two bank accounts in one file is not a duplicate
+	if got := duplicates(ofxStatement("1001", "111", "222"), checking); fmt.Sprint(got) != "[false false]" {
+		t.Errorf("same FITID under two ACCTIDs: duplicates %v, want [false false]", got)
+	}
+	if got := duplicates(ofxStatement("1001", "111", "111"), checking); fmt.Sprint(got) != "[false true]" {
+		t.Errorf("same FITID twice under one ACCTID: duplicates %v, want [false true]", got)
+	}
+
+	result, err := s.ImportOFX(ctx, strings.NewReader(ofxStatement("1001", "111")), StatementImportOptions{Account: checking})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 {
+		t.Fatalf("imported %d transaction(s), want 1", result.Imported)
+	}
+
+	tests := []struct {
+		name      string
+		account   string
+		duplicate bool
+	}{
+		{"same account", checking, true},
+		{"other account", savings, false},
+		{"no account", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := duplicates(ofxStatement("1001", "222"), tt.account); len(got) != 1 || got[0] != tt.duplicate {
+				t.Errorf("duplicates %v, want [%v]", got, tt.duplicate)
+			}
+		})
+	}
+}
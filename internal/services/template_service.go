@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
+)
+
+type TemplateService struct {
+	db           *database.Database
+	currencies   *CurrencyService
+	transactions *TransactionService
+}
+
+func NewTemplateService(db *database.Database) *TemplateService {
+	return &TemplateService{
+		db:           db,
+		currencies:   NewCurrencyService(db),
+		transactions: NewTransactionService(db),
+	}
+}
+
+// Template request types. Category and PaymentMethod hold IDs, as they do
+// on transactions; an Amount of 0 leaves the amount to be filled in when the
+// template is used.
+type CreateTemplateParams struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Description    string   `json:"description"`
+	Amount         float64  `json:"amount"`
+	Category       string   `json:"category"`
+	Tags           []string `json:"tags"`
+	CustomerVendor string   `json:"customer_vendor"`
+	PaymentMethod  string   `json:"payment_method"`
+	TaxAmount      float64  `json:"tax_amount"`
+	DiscountAmount float64  `json:"discount_amount"`
+	Currency       string   `json:"currency"`
+	Notes          string   `json:"notes"`
+	IsFavorite     bool     `json:"is_favorite"`
+	CreatedBy      string   `json:"created_by"`
+}
+
+type UpdateTemplateParams struct {
+	Name           string   `json:"name"`
+	Type           string   `json:"type"`
+	Description    string   `json:"description"`
+	Amount         float64  `json:"amount"`
+	Category       string   `json:"category"`
+	Tags           []string `json:"tags"`
+	CustomerVendor string   `json:"customer_vendor"`
+	PaymentMethod  string   `json:"payment_method"`
+	TaxAmount      float64  `json:"tax_amount"`
+	DiscountAmount float64  `json:"discount_amount"`
+	Currency       string   `json:"currency"`
+	Notes          string   `json:"notes"`
+	IsFavorite     bool     `json:"is_favorite"`
+}
+
+// CreateTemplate creates a new transaction template
+func (s *TemplateService) CreateTemplate(ctx context.Context, params CreateTemplateParams) (*db.TransactionTemplate, error) {
+	if strings.TrimSpace(params.Name) == "" {
+		return nil, fmt.Errorf("template name is required")
+	}
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	params.Currency = normalizeCurrency(params.Currency)
+	exponent := s.currencies.Exponent(ctx, params.Currency)
+	tagsJSON, _ := json.Marshal(params.Tags)
+
+	template, err := s.db.Queries().CreateTemplate(ctx, db.CreateTemplateParams{
+		Name:           strings.TrimSpace(params.Name),
+		Type:           params.Type,
+		Description:    toSqlNullString(params.Description),
+		Amount:         templateAmount(params.Amount, exponent),
+		Category:       toSqlNullString(params.Category),
+		Tags:           toSqlNullString(string(tagsJSON)),
+		CustomerVendor: toSqlNullString(params.CustomerVendor),
+		PaymentMethod:  toSqlNullString(params.PaymentMethod),
+		TaxAmount:      toSqlNullMinorUnits(params.TaxAmount, exponent),
+		DiscountAmount: toSqlNullMinorUnits(params.DiscountAmount, exponent),
+		Currency:       toSqlNullString(params.Currency),
+		Notes:          toSqlNullString(params.Notes),
+		IsFavorite:     toSqlNullBool(params.IsFavorite),
+		CreatedBy:      params.CreatedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template: %w", err)
+	}
+	return &template, nil
+}
+
+// GetTemplate retrieves a template of createdBy by ID; those of other users
+// are not found
+func (s *TemplateService) GetTemplate(ctx context.Context, createdBy, id string) (*db.TransactionTemplate, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	template, err := s.db.Queries().GetTemplate(ctx, id)
+	if err == sql.ErrNoRows || err == nil && template.CreatedBy != createdBy {
+		return nil, fmt.Errorf("template not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+	return &template, nil
+}
+
+// ListTemplates lists templates, favorites first and then by usage
+func (s *TemplateService) ListTemplates(ctx context.Context, createdBy string, favoritesOnly bool) ([]db.TransactionTemplate, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+
+	templates, err := s.db.Queries().ListTemplates(ctx, db.ListTemplatesParams{
+		CreatedBy:     createdBy,
+		FavoritesOnly: favoritesOnly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, nil
+}
+
+// ListMostUsedTemplates lists the templates used most often
+func (s *TemplateService) ListMostUsedTemplates(ctx context.Context, createdBy string, limit int) ([]db.TransactionTemplate, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	templates, err := s.db.Queries().ListMostUsedTemplates(ctx, db.ListMostUsedTemplatesParams{
+		CreatedBy: createdBy,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list most used templates: %w", err)
+	}
+	return templates, nil
+}
+
+// UpdateTemplate updates an existing template
+func (s *TemplateService) UpdateTemplate(ctx context.Context, createdBy, id string, params UpdateTemplateParams) (*db.TransactionTemplate, error) {
+	if strings.TrimSpace(params.Name) == "" {
+		return nil, fmt.Errorf("template name is required")
+	}
+	if _, err := s.GetTemplate(ctx, createdBy, id); err != nil {
+		return nil, err
+	}
+	params.Currency = normalizeCurrency(params.Currency)
+	exponent := s.currencies.Exponent(ctx, params.Currency)
+	tagsJSON, _ := json.Marshal(params.Tags)
+
+	template, err := s.db.Queries().UpdateTemplate(ctx, db.UpdateTemplateParams{
+		ID:             id,
+		Name:           strings.TrimSpace(params.Name),
+		Type:           params.Type,
+		Description:    toSqlNullString(params.Description),
+		Amount:         templateAmount(params.Amount, exponent),
+		Category:       toSqlNullString(params.Category),
+		Tags:           toSqlNullString(string(tagsJSON)),
+		CustomerVendor: toSqlNullString(params.CustomerVendor),
+		PaymentMethod:  toSqlNullString(params.PaymentMethod),
+		TaxAmount:      toSqlNullMinorUnits(params.TaxAmount, exponent),
+		DiscountAmount: toSqlNullMinorUnits(params.DiscountAmount, exponent),
+		Currency:       toSqlNullString(params.Currency),
+		Notes:          toSqlNullString(params.Notes),
+		IsFavorite:     toSqlNullBool(params.IsFavorite),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("template not found")
+		}
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+	return &template, nil
+}
+
+// SetTemplateFavorite marks or unmarks a template as a favorite
+func (s *TemplateService) SetTemplateFavorite(ctx context.Context, createdBy, id string, isFavorite bool) error {
+	if _, err := s.GetTemplate(ctx, createdBy, id); err != nil {
+		return err
+	}
+	if err := s.db.Queries().SetTemplateFavorite(ctx, db.SetTemplateFavoriteParams{
+		IsFavorite: toSqlNullBool(isFavorite),
+		ID:         id,
+	}); err != nil {
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate deletes a template
+func (s *TemplateService) DeleteTemplate(ctx context.Context, createdBy, id string) error {
+	if _, err := s.GetTemplate(ctx, createdBy, id); err != nil {
+		return err
+	}
+	if err := s.db.Queries().DeleteTemplate(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
+
+// CreateTransactionFromTemplate creates a transaction from a template. Fields
+// set in params take precedence over the template's; the date defaults to
+// today. The template's usage count is incremented in the same database
+// transaction.
+func (s *TemplateService) CreateTransactionFromTemplate(ctx context.Context, id string, params CreateTransactionParams) (*db.Transaction, error) {
+	template, err := s.GetTemplate(ctx, params.CreatedBy, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look the exponent up before the database transaction holds the only
+	// connection
+	params = s.ApplyTemplate(ctx, template, params)
+	s.transactions.currencies.Exponent(ctx, params.Currency)
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	transaction, err := s.transactions.createTransaction(ctx, qtx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := qtx.IncrementTemplateUsage(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to update template usage: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.TransactionCommitted(ctx, nil, transaction)
+	return transaction, nil
+}
+
+// ApplyTemplate fills the fields of params that are not set from the template
+func (s *TemplateService) ApplyTemplate(ctx context.Context, t *db.TransactionTemplate, params CreateTransactionParams) CreateTransactionParams {
+	if params.Type == "" {
+		params.Type = t.Type
+	}
+	if params.Description == "" {
+		params.Description = nullStringOr(t.Description, t.Name)
+	}
+	if params.Currency == "" {
+		params.Currency = nullStringOr(t.Currency, "USD")
+	}
+
+	// Template amounts are in minor units of the template currency
+	exponent := s.currencies.Exponent(ctx, nullStringOr(t.Currency, "USD"))
+	if params.Amount == 0 && t.Amount.Valid {
+		params.Amount = fromMinorUnits(t.Amount.Int64, exponent)
+	}
+	if params.TaxAmount == 0 && t.TaxAmount.Valid {
+		params.TaxAmount = fromMinorUnits(t.TaxAmount.Int64, exponent)
+	}
+	if params.DiscountAmount == 0 && t.DiscountAmount.Valid {
+		params.DiscountAmount = fromMinorUnits(t.DiscountAmount.Int64, exponent)
+	}
+
+	if params.Category == "" {
+		params.Category = t.Category.String
+	}
+	if len(params.Tags) == 0 && t.Tags.Valid && t.Tags.String != "" {
+		json.Unmarshal([]byte(t.Tags.String), &params.Tags)
+	}
+	if params.CustomerVendor == "" {
+		params.CustomerVendor = t.CustomerVendor.String
+	}
+	if params.PaymentMethod == "" {
+		params.PaymentMethod = t.PaymentMethod.String
+	}
+	if params.Notes == "" {
+		params.Notes = t.Notes.String
+	}
+	if params.TransactionDate == "" {
+		params.TransactionDate = time.Now().Format("2006-01-02")
+	}
+	if params.CreatedBy == "" {
+		params.CreatedBy = t.CreatedBy
+	}
+	return params
+}
+
+// templateAmount stores a zero amount as NULL so it is asked for on use
+func templateAmount(amount float64, exponent int) sql.NullInt64 {
+	if amount == 0 {
+		return sql.NullInt64{}
+	}
+	return toSqlNullMinorUnits(amount, exponent)
+}
+
+// nullStringOr returns the string value of ns, or fallback if it is empty
+func nullStringOr(ns sql.NullString, fallback string) string {
+	if ns.Valid && ns.String != "" {
+		return ns.String
+	}
+	return fallback
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cashflow/internal/models"
+)
+
+func TestCreateTransactionFromTemplate(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTemplateService(d)
+
+	template, err := s.CreateTemplate(ctx, CreateTemplateParams{Name: "Rent", Type: "expense", Amount: 500, Currency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transaction, err := s.CreateTransactionFromTemplate(ctx, template.ID, CreateTransactionParams{TransactionDate: "2024-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.Description != "Rent" || transaction.Amount != 50000 || transaction.Currency.String != "EUR" {
+		t.Errorf("got %s %d %s, want Rent 50000 EUR", transaction.Description, transaction.Amount, transaction.Currency.String)
+	}
+	used, err := s.GetTemplate(ctx, DefaultUserID, template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if used.UsageCount.Int64 != 1 {
+		t.Errorf("usage count %d, want 1", used.UsageCount.Int64)
+	}
+
+	// Another user cannot use the template
+	other, err := NewUserService(d).CreateUser(ctx, &models.UserCreateRequest{Name: "Other", Email: "other@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.CreateTransactionFromTemplate(ctx, template.ID, CreateTransactionParams{TransactionDate: "2024-01-01", CreatedBy: other.ID})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("using another user's template: got %v, want not found", err)
+	}
+	transactions, err := NewTransactionService(d).ListTransactions(ctx, ListTransactionParams{CreatedBy: other.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 0 {
+		t.Errorf("the other user has %d transaction(s), want none", len(transactions))
+	}
+	if used, err = s.GetTemplate(ctx, DefaultUserID, template.ID); err != nil || used.UsageCount.Int64 != 1 {
+		t.Errorf("usage count after the other user tried: %d, %v; want 1", used.UsageCount.Int64, err)
+	}
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	db "cashflow/internal/db/sqlc"
+)
+
+// Transaction history actions
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	HistoryRevert  = "revert"
+	HistoryPurge   = "purge"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context that records changes made through it as made
+// by userID. Without one, changes are recorded as made by the owner of the
+// transaction.
+func WithActor(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+func actorFrom(ctx context.Context, fallback string) string {
+	if userID, ok := ctx.Value(actorKey{}).(string); ok && userID != "" {
+		return userID
+	}
+	return fallback
+}
+
+// transactionVersion is what the history keeps of a transaction. Amounts
+// are in minor units and dates are YYYY-MM-DD.
+type transactionVersion struct {
+	Type               string  `json:"type"`
+	Description        string  `json:"description"`
+	Amount             int64   `json:"amount"`
+	TransactionDate    string  `json:"transaction_date"`
+	CategoryID         string  `json:"category_id"`
+	Tags               string  `json:"tags"`
+	CustomerVendor     string  `json:"customer_vendor"`
+	ContactID          string  `json:"contact_id"`
+	PaymentMethodID    string  `json:"payment_method_id"`
+	AccountID          string  `json:"account_id"`
+	PaymentStatus      string  `json:"payment_status"`
+	ReferenceNumber    string  `json:"reference_number"`
+	InvoiceNumber      string  `json:"invoice_number"`
+	Notes              string  `json:"notes"`
+	Attachments        string  `json:"attachments"`
+	TaxAmount          int64   `json:"tax_amount"`
+	DiscountAmount     int64   `json:"discount_amount"`
+	DueAmount          int64   `json:"due_amount"`
+	Currency           string  `json:"currency"`
+	ExchangeRate       float64 `json:"exchange_rate"`
+	IsRecurring        bool    `json:"is_recurring"`
+	RecurringFrequency string  `json:"recurring_frequency"`
+	RecurringEndDate   string  `json:"recurring_end_date"`
+	DueDate            string  `json:"due_date"`
+}
+
+// moneyFields are the fields of a transactionVersion held in minor units
+var moneyFields = map[string]bool{"amount": true, "tax_amount": true, "discount_amount": true, "due_amount": true}
+
+func versionOf(t *db.Transaction) transactionVersion {
+	date := func(nt sql.NullTime) string {
+		if !nt.Valid {
+			return ""
+		}
+		return nt.Time.Format("2006-01-02")
+	}
+	return transactionVersion{
+		Type:               t.Type,
+		Description:        t.Description,
+		Amount:             t.Amount,
+		TransactionDate:    t.TransactionDate.Format("2006-01-02"),
+		CategoryID:         t.CategoryID.String,
+		Tags:               t.Tags.String,
+		CustomerVendor:     t.CustomerVendor.String,
+		ContactID:          t.ContactID.String,
+		PaymentMethodID:    t.PaymentMethodID.String,
+		AccountID:          t.AccountID.String,
+		PaymentStatus:      t.PaymentStatus.String,
+		ReferenceNumber:    t.ReferenceNumber.String,
+		InvoiceNumber:      t.InvoiceNumber.String,
+		Notes:              t.Notes.String,
+		Attachments:        t.Attachments.String,
+		TaxAmount:          t.TaxAmount.Int64,
+		DiscountAmount:     t.DiscountAmount.Int64,
+		DueAmount:          t.DueAmount.Int64,
+		Currency:           t.Currency.String,
+		ExchangeRate:       versionExchangeRate(t.ExchangeRate),
+		IsRecurring:        t.IsRecurring.Bool,
+		RecurringFrequency: t.RecurringFrequency.String,
+		RecurringEndDate:   date(t.RecurringEndDate),
+		DueDate:            date(t.DueDate),
+	}
+}
+
+// versionExchangeRate is the exchange rate kept in the history: a missing
+// rate is kept as 0, which the currency converter also treats as unset
+func versionExchangeRate(rate sql.NullFloat64) float64 {
+	if !rate.Valid || rate.Float64 <= 0 {
+		return 0
+	}
+	return rate.Float64
+}
+
+// FieldChange is one changed field of a transaction. Amounts are decimal
+// amounts in the transaction's currency.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// TransactionHistoryEntry is one recorded change to a transaction
+type TransactionHistoryEntry struct {
+	ID            string        `json:"id"`
+	TransactionID string        `json:"transaction_id"`
+	Version       int64         `json:"version"`
+	Action        string        `json:"action"`
+	Changes       []FieldChange `json:"changes"`
+	ChangedBy     string        `json:"changed_by"`
+	ChangedAt     string        `json:"changed_at"`
+}
+
+// diffVersions lists the fields that differ between two versions, in the
+// order they are declared
+func diffVersions(before, after transactionVersion) []FieldChange {
+	changes := []FieldChange{}
+	b, a := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < b.NumField(); i++ {
+		from, to := b.Field(i).Interface(), a.Field(i).Interface()
+		if from != to {
+			changes = append(changes, FieldChange{Field: b.Type().Field(i).Tag.Get("json"), Old: from, New: to})
+		}
+	}
+	return changes
+}
+
+// recordHistory appends a change to the history of a transaction. before
+// is nil for a new transaction and after is nil for a purged one; nothing
+// is recorded for an update that changed nothing.
+func recordHistory(ctx context.Context, q *db.Queries, action string, before, after *db.Transaction) error {
+	var changes []FieldChange
+	current := after
+	switch {
+	case before == nil:
+		changes = diffVersions(transactionVersion{}, versionOf(after))
+	case after == nil:
+		current = before
+		changes = []FieldChange{}
+	default:
+		changes = diffVersions(versionOf(before), versionOf(after))
+	}
+
+	// Deleting and restoring only change deleted_at, which versions leave out
+	if action == HistoryDelete || action == HistoryRestore {
+		from, to := "", ""
+		if before.DeletedAt.Valid {
+			from = before.DeletedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		if after.DeletedAt.Valid {
+			to = after.DeletedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		changes = append(changes, FieldChange{Field: "deleted_at", Old: from, New: to})
+	}
+	if action == HistoryUpdate && len(changes) == 0 {
+		return nil
+	}
+	return appendHistory(ctx, q, action, current, changes)
+}
+
+// appendHistory records changes to a transaction, now in the state current,
+// as its next version
+func appendHistory(ctx context.Context, q *db.Queries, action string, current *db.Transaction, changes []FieldChange) error {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(versionOf(current))
+	if err != nil {
+		return err
+	}
+	if err := q.CreateTransactionHistory(ctx, db.CreateTransactionHistoryParams{
+		TransactionID: current.ID,
+		Action:        action,
+		Changes:       string(changesJSON),
+		Snapshot:      string(snapshot),
+		ChangedBy:     actorFrom(ctx, current.CreatedBy),
+	}); err != nil {
+		return fmt.Errorf("failed to record transaction history: %w", err)
+	}
+	return nil
+}
+
+// GetTransactionHistory lists the recorded changes to a transaction of
+// createdBy, oldest first. It works for deleted and purged transactions too;
+// a purged transaction belongs to whoever recorded its creation.
+func (s *TransactionService) GetTransactionHistory(ctx context.Context, createdBy, id string) ([]TransactionHistoryEntry, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	rows, err := s.db.Queries().ListTransactionHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction history: %w", err)
+	}
+
+	owner := ""
+	transaction, err := s.db.Queries().GetTransactionWithDeleted(ctx, id)
+	switch {
+	case err == nil:
+		owner = transaction.CreatedBy
+	case err != sql.ErrNoRows:
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	case len(rows) > 0:
+		owner = rows[0].ChangedBy
+	}
+	if owner != createdBy {
+		return nil, fmt.Errorf("transaction not found")
+	}
+
+	entries := make([]TransactionHistoryEntry, 0, len(rows))
+	currency := ""
+	for _, row := range rows {
+		var changes []FieldChange
+		if err := json.Unmarshal([]byte(row.Changes), &changes); err != nil {
+			return nil, fmt.Errorf("failed to parse transaction history: %w", err)
+		}
+		var version transactionVersion
+		if err := json.Unmarshal([]byte(row.Snapshot), &version); err != nil {
+			return nil, fmt.Errorf("failed to parse transaction history: %w", err)
+		}
+
+		// Old amounts are in the currency of the previous version
+		oldExponent := s.currencies.Exponent(ctx, currency)
+		newExponent := s.currencies.Exponent(ctx, version.Currency)
+		for i, change := range changes {
+			if !moneyFields[change.Field] {
+				continue
+			}
+			if minor, ok := change.Old.(float64); ok {
+				changes[i].Old = fromMinorUnits(int64(minor), oldExponent)
+			}
+			if minor, ok := change.New.(float64); ok {
+				changes[i].New = fromMinorUnits(int64(minor), newExponent)
+			}
+		}
+		currency = version.Currency
+
+		entries = append(entries, TransactionHistoryEntry{
+			ID:            row.ID,
+			TransactionID: row.TransactionID,
+			Version:       row.Version,
+			Action:        row.Action,
+			Changes:       changes,
+			ChangedBy:     row.ChangedBy,
+			ChangedAt:     row.ChangedAt.Time.Format(time.RFC3339),
+		})
+	}
+	return entries, nil
+}
+
+// RevertTransaction sets a transaction of createdBy back to how it was
+// after the given version of its history. This is recorded as a new
+// version; payments recorded since still decide what is due.
+func (s *TransactionService) RevertTransaction(ctx context.Context, createdBy, id string, version int64) (*db.Transaction, error) {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	before, err := qtx.GetTransaction(ctx, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("transaction not found; restore it before reverting it")
+	}
+	if before, err = ownedTransaction(before, err, createdBy); err != nil {
+		return nil, err
+	}
+	if err := checkUnlocked(&before); err != nil {
+		return nil, err
+	}
+
+	row, err := qtx.GetTransactionVersion(ctx, db.GetTransactionVersionParams{
+		TransactionID: id,
+		Version:       version,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("transaction version not found")
+		}
+		return nil, fmt.Errorf("failed to get transaction version: %w", err)
+	}
+	var v transactionVersion
+	if err := json.Unmarshal([]byte(row.Snapshot), &v); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction history: %w", err)
+	}
+	transactionDate, err := time.Parse("2006-01-02", v.TransactionDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transaction history: %w", err)
+	}
+	if v.ExchangeRate < 0 {
+		return nil, fmt.Errorf("transaction version %d has an invalid exchange rate %v", version, v.ExchangeRate)
+	}
+
+	// The contact may have been merged or deleted since; the name finds
+	// the one it is now
+	contact, err := resolveContact(ctx, qtx, before.CreatedBy, "", v.CustomerVendor, v.Type)
+	if err != nil {
+		return nil, err
+	}
+	if contact != nil {
+		v.ContactID = contact.ID
+		v.CustomerVendor = contact.Name
+	}
+	if v.AccountID != "" {
+		if _, err := qtx.GetAccount(ctx, v.AccountID); err == sql.ErrNoRows {
+			v.AccountID = ""
+		}
+	}
+
+	transaction, err := qtx.UpdateTransaction(ctx, db.UpdateTransactionParams{
+		ID:                 id,
+		Type:               v.Type,
+		Description:        v.Description,
+		Amount:             v.Amount,
+		TransactionDate:    transactionDate,
+		CategoryID:         toSqlNullString(v.CategoryID),
+		Tags:               toSqlNullString(v.Tags),
+		CustomerVendor:     toSqlNullString(v.CustomerVendor),
+		PaymentMethodID:    toSqlNullString(v.PaymentMethodID),
+		PaymentStatus:      toSqlNullString(v.PaymentStatus),
+		ReferenceNumber:    toSqlNullString(v.ReferenceNumber),
+		InvoiceNumber:      toSqlNullString(v.InvoiceNumber),
+		Notes:              toSqlNullString(v.Notes),
+		Attachments:        toSqlNullString(v.Attachments),
+		TaxAmount:          sql.NullInt64{Int64: v.TaxAmount, Valid: true},
+		DiscountAmount:     sql.NullInt64{Int64: v.DiscountAmount, Valid: true},
+		DueAmount:          sql.NullInt64{Int64: v.DueAmount, Valid: true},
+		Currency:           toSqlNullString(v.Currency),
+		ExchangeRate:       toSqlNullFloat64(v.ExchangeRate),
+		IsRecurring:        toSqlNullBool(v.IsRecurring),
+		RecurringFrequency: toSqlNullString(v.RecurringFrequency),
+		RecurringEndDate:   toSqlNullTime(v.RecurringEndDate),
+		DueDate:            toSqlNullTime(v.DueDate),
+		ContactID:          toSqlNullString(v.ContactID),
+		AccountID:          toSqlNullString(v.AccountID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert transaction: %w", err)
+	}
+	if err := settleTransaction(ctx, qtx, &transaction); err != nil {
+		return nil, err
+	}
+	if err := recordHistory(ctx, qtx, HistoryRevert, &before, &transaction); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.TransactionCommitted(ctx, &before, &transaction)
+	return &transaction, nil
+}
@@ -4,49 +4,101 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"cashflow/internal/database"
 	db "cashflow/internal/db/sqlc"
 )
 
 type TransactionService struct {
-	db *database.Database
+	db          *database.Database
+	currencies  *CurrencyService
+	rates       *ExchangeRateService
+	preferences *PreferencesService
 }
 
 func NewTransactionService(db *database.Database) *TransactionService {
-	return &TransactionService{db: db}
+	return &TransactionService{
+		db:          db,
+		currencies:  NewCurrencyService(db),
+		rates:       NewExchangeRateService(db),
+		preferences: NewPreferencesService(db),
+	}
 }
 
 // CreateTransaction creates a new transaction
 func (s *TransactionService) CreateTransaction(ctx context.Context, params CreateTransactionParams) (*db.Transaction, error) {
+	// Look the exponent up before the database transaction holds the only
+	// connection
+	s.currencies.Exponent(ctx, params.Currency)
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	transaction, err := s.createTransaction(ctx, s.db.Queries().WithTx(tx), params)
+	if err != nil {
+		return transaction, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.TransactionCommitted(ctx, nil, transaction)
+	return transaction, nil
+}
+
+// createTransaction creates a transaction through q, which may be bound to a
+// database transaction
+func (s *TransactionService) createTransaction(ctx context.Context, q *db.Queries, params CreateTransactionParams) (*db.Transaction, error) {
 	// Prepare tags and attachments as JSON strings
 	tagsJSON, _ := json.Marshal(params.Tags)
 	attachmentsJSON, _ := json.Marshal(params.Attachments)
 
 	// Set defaults if not provided
 	if params.CreatedBy == "" {
-		params.CreatedBy = "default"
+		params.CreatedBy = DefaultUserID
 	}
 	if params.Currency == "" {
 		params.Currency = "USD"
 	}
-	if params.ExchangeRate == 0 {
-		params.ExchangeRate = 1.0
-	}
 	if params.PaymentStatus == "" {
 		params.PaymentStatus = "completed"
 	}
 
+	// Link the customer/vendor, whose defaults fill in what was left empty
+	contact, err := resolveContact(ctx, q, params.CreatedBy, params.ContactID, params.CustomerVendor, params.Type)
+	if err != nil {
+		return nil, err
+	}
+	if contact != nil {
+		params.ContactID = contact.ID
+		params.CustomerVendor = contact.Name
+		if params.Category == "" {
+			params.Category = contact.DefaultCategoryID.String
+		}
+		if params.PaymentMethod == "" {
+			params.PaymentMethod = contact.DefaultPaymentMethodID.String
+		}
+	}
+
 	// Parse the transaction date
 	transactionTime, _ := time.Parse("2006-01-02", params.TransactionDate)
 
-	transaction, err := s.db.Queries().CreateTransaction(ctx, db.CreateTransactionParams{
+	// Amounts are stored in minor units of the transaction currency
+	exponent := s.currencies.Exponent(ctx, params.Currency)
+
+	transaction, err := q.CreateTransaction(ctx, db.CreateTransactionParams{
 		Type:                 params.Type,
 		Description:          params.Description,
-		Amount:               params.Amount,
+		Amount:               toMinorUnits(params.Amount, exponent),
 		TransactionDate:      transactionTime,
 		CategoryID:           toSqlNullString(params.Category),
 		Tags:                 toSqlNullString(string(tagsJSON)),
@@ -57,9 +109,9 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, params Creat
 		InvoiceNumber:        toSqlNullString(params.InvoiceNumber),
 		Notes:                toSqlNullString(params.Notes),
 		Attachments:          toSqlNullString(string(attachmentsJSON)),
-		TaxAmount:            toSqlNullFloat64(params.TaxAmount),
-		DiscountAmount:       toSqlNullFloat64(params.DiscountAmount),
-		DueAmount:            toSqlNullFloat64(params.DueAmount),
+		TaxAmount:            toSqlNullMinorUnits(params.TaxAmount, exponent),
+		DiscountAmount:       toSqlNullMinorUnits(params.DiscountAmount, exponent),
+		DueAmount:            toSqlNullMinorUnits(params.DueAmount, exponent),
 		Currency:             toSqlNullString(params.Currency),
 		ExchangeRate:         toSqlNullFloat64(params.ExchangeRate),
 		IsRecurring:          toSqlNullBool(params.IsRecurring),
@@ -67,23 +119,58 @@ func (s *TransactionService) CreateTransaction(ctx context.Context, params Creat
 		RecurringEndDate:     toSqlNullTime(params.RecurringEndDate),
 		ParentTransactionID:  toSqlNullString(params.ParentTransactionID),
 		CreatedBy:            params.CreatedBy,
+		DueDate:              toSqlNullTime(params.DueDate),
+		ContactID:            toSqlNullString(params.ContactID),
+		AccountID:            toSqlNullString(params.Account),
 	})
+	if err != nil {
+		return &transaction, err
+	}
 
-	return &transaction, err
+	if err := recordHistory(ctx, q, HistoryCreate, nil, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
 }
 
-// GetTransaction retrieves a transaction by ID
-func (s *TransactionService) GetTransaction(ctx context.Context, id string) (*db.Transaction, error) {
-	transaction, err := s.db.Queries().GetTransaction(ctx, id)
+// GetTransaction retrieves a transaction of createdBy by ID
+func (s *TransactionService) GetTransaction(ctx context.Context, createdBy, id string) (*db.Transaction, error) {
+	transaction, err := getOwnedTransaction(ctx, s.db.Queries(), createdBy, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("transaction not found")
-		}
 		return nil, err
 	}
 	return &transaction, nil
 }
 
+// getOwnedTransaction gets a transaction that is not deleted through q.
+// Transactions of other users are not found, like missing ones.
+func getOwnedTransaction(ctx context.Context, q *db.Queries, createdBy, id string) (db.Transaction, error) {
+	transaction, err := q.GetTransaction(ctx, id)
+	return ownedTransaction(transaction, err, createdBy)
+}
+
+// getOwnedTransactionWithDeleted is getOwnedTransaction for deleted
+// transactions too
+func getOwnedTransactionWithDeleted(ctx context.Context, q *db.Queries, createdBy, id string) (db.Transaction, error) {
+	transaction, err := q.GetTransactionWithDeleted(ctx, id)
+	return ownedTransaction(transaction, err, createdBy)
+}
+
+// ownedTransaction checks the result of reading a transaction by ID,
+// turning one that does not belong to createdBy into not found
+func ownedTransaction(transaction db.Transaction, err error, createdBy string) (db.Transaction, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	if err == sql.ErrNoRows || err == nil && transaction.CreatedBy != createdBy {
+		return db.Transaction{}, fmt.Errorf("transaction not found")
+	}
+	if err != nil {
+		return db.Transaction{}, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return transaction, nil
+}
+
 // Helper function to convert string array to comma-separated string for SQL filtering
 func arrayToCommaSeparated(arr []string) string {
 	if len(arr) == 0 {
@@ -96,12 +183,24 @@ func arrayToCommaSeparated(arr []string) string {
 func (s *TransactionService) ListTransactions(ctx context.Context, params ListTransactionParams) ([]db.Transaction, error) {
 	// Set defaults
 	if params.CreatedBy == "" {
-		params.CreatedBy = "default"
+		params.CreatedBy = DefaultUserID
 	}
 	if params.Limit == 0 {
 		params.Limit = 50
 	}
 
+	// Without an explicit filter the user's default preset applies
+	if !params.IgnoreDefaultFilter && !params.hasFilter() {
+		if preset, err := s.db.Queries().GetDefaultSavedFilter(ctx, params.CreatedBy); err == nil {
+			if filter, err := decodeFilterConfig(preset.FilterConfig); err == nil {
+				filter.CreatedBy = params.CreatedBy
+				filter.Limit = params.Limit
+				filter.Offset = params.Offset
+				params = filter
+			}
+		}
+	}
+
 	result, err := s.db.Queries().ListTransactions(ctx, db.ListTransactionsParams{
 		CreatedBy:             params.CreatedBy,
 		FromDate:              params.FromDate,
@@ -110,6 +209,8 @@ func (s *TransactionService) ListTransactions(ctx context.Context, params ListTr
 		CategoryFilter:        arrayToCommaSeparated(params.CategoryFilter),
 		PaymentStatusFilter:   arrayToCommaSeparated(params.PaymentStatusFilter),
 		PaymentMethodFilter:   arrayToCommaSeparated(params.PaymentMethodFilter),
+		ContactFilter:         params.ContactFilter,
+		AccountFilter:         params.AccountFilter,
 		CustomerVendorSearch:  params.CustomerVendorSearch,
 		DescriptionSearch:     params.DescriptionSearch,
 		MinDueAmount:          params.MinDueAmount,
@@ -121,8 +222,8 @@ func (s *TransactionService) ListTransactions(ctx context.Context, params ListTr
 	return result, err
 }
 
-// UpdateTransaction updates an existing transaction
-func (s *TransactionService) UpdateTransaction(ctx context.Context, id string, params UpdateTransactionParams) (*db.Transaction, error) {
+// UpdateTransaction updates an existing transaction of createdBy
+func (s *TransactionService) UpdateTransaction(ctx context.Context, createdBy, id string, params UpdateTransactionParams) (*db.Transaction, error) {
 	// Prepare tags and attachments as JSON strings
 	tagsJSON, _ := json.Marshal(params.Tags)
 	attachmentsJSON, _ := json.Marshal(params.Attachments)
@@ -130,11 +231,37 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, id string, p
 	// Parse the transaction date
 	transactionTime, _ := time.Parse("2006-01-02", params.TransactionDate)
 
-	transaction, err := s.db.Queries().UpdateTransaction(ctx, db.UpdateTransactionParams{
+	// Amounts are stored in minor units of the transaction currency
+	exponent := s.currencies.Exponent(ctx, params.Currency)
+
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	before, err := getOwnedTransaction(ctx, qtx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnlocked(&before); err != nil {
+		return nil, err
+	}
+	contact, err := resolveContact(ctx, qtx, before.CreatedBy, params.ContactID, params.CustomerVendor, params.Type)
+	if err != nil {
+		return nil, err
+	}
+	if contact != nil {
+		params.ContactID = contact.ID
+		params.CustomerVendor = contact.Name
+	}
+
+	transaction, err := qtx.UpdateTransaction(ctx, db.UpdateTransactionParams{
 		ID:                   id,
 		Type:                 params.Type,
 		Description:          params.Description,
-		Amount:               params.Amount,
+		Amount:               toMinorUnits(params.Amount, exponent),
 		TransactionDate:      transactionTime,
 		CategoryID:           toSqlNullString(params.Category),
 		Tags:                 toSqlNullString(string(tagsJSON)),
@@ -145,50 +272,363 @@ func (s *TransactionService) UpdateTransaction(ctx context.Context, id string, p
 		InvoiceNumber:        toSqlNullString(params.InvoiceNumber),
 		Notes:                toSqlNullString(params.Notes),
 		Attachments:          toSqlNullString(string(attachmentsJSON)),
-		TaxAmount:            toSqlNullFloat64(params.TaxAmount),
-		DiscountAmount:       toSqlNullFloat64(params.DiscountAmount),
-		DueAmount:            toSqlNullFloat64(params.DueAmount),
+		TaxAmount:            toSqlNullMinorUnits(params.TaxAmount, exponent),
+		DiscountAmount:       toSqlNullMinorUnits(params.DiscountAmount, exponent),
+		DueAmount:            toSqlNullMinorUnits(params.DueAmount, exponent),
 		Currency:             toSqlNullString(params.Currency),
 		ExchangeRate:         toSqlNullFloat64(params.ExchangeRate),
 		IsRecurring:          toSqlNullBool(params.IsRecurring),
 		RecurringFrequency:   toSqlNullString(params.RecurringFrequency),
 		RecurringEndDate:     toSqlNullTime(params.RecurringEndDate),
+		DueDate:              toSqlNullTime(params.DueDate),
+		ContactID:            toSqlNullString(params.ContactID),
+		AccountID:            toSqlNullString(params.Account),
 	})
+	if err != nil {
+		return &transaction, err
+	}
+
+	// Once payments are recorded they decide what is still due
+	if err := settleTransaction(ctx, qtx, &transaction); err != nil {
+		return nil, err
+	}
+	if err := recordHistory(ctx, qtx, HistoryUpdate, &before, &transaction); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.TransactionCommitted(ctx, &before, &transaction)
+	return &transaction, nil
+}
+
+// DeleteTransaction soft deletes a transaction of createdBy
+func (s *TransactionService) DeleteTransaction(ctx context.Context, createdBy, id string) error {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	before, err := getOwnedTransaction(ctx, qtx, createdBy, id)
+	if err != nil {
+		return err
+	}
+	if err := checkUnlocked(&before); err != nil {
+		return err
+	}
+	if err := qtx.DeleteTransaction(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete transaction: %w", err)
+	}
+	after, err := qtx.GetTransactionWithDeleted(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if err := recordHistory(ctx, qtx, HistoryDelete, &before, &after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListDeletedTransactions lists soft-deleted transactions, most recently
+// deleted first
+func (s *TransactionService) ListDeletedTransactions(ctx context.Context, createdBy string, limit, offset int) ([]db.Transaction, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	if limit == 0 {
+		limit = 50
+	}
+
+	transactions, err := s.db.Queries().ListDeletedTransactions(ctx, db.ListDeletedTransactionsParams{
+		CreatedBy: createdBy,
+		Offset:    int64(offset),
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// RestoreTransaction undoes the deletion of a transaction of createdBy
+func (s *TransactionService) RestoreTransaction(ctx context.Context, createdBy, id string) (*db.Transaction, error) {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	before, err := getOwnedTransactionWithDeleted(ctx, qtx, createdBy, id)
+	if err != nil {
+		return nil, err
+	}
+	n, err := qtx.RestoreTransaction(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore transaction: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("deleted transaction not found")
+	}
+	transaction, err := qtx.GetTransaction(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if err := recordHistory(ctx, qtx, HistoryRestore, &before, &transaction); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	s.db.TransactionCommitted(ctx, &before, &transaction)
+	return &transaction, nil
+}
+
+// PurgeParams selects deleted transactions to remove for good: those
+// deleted before the Before date, those in IDs, or, given both, those in
+// IDs deleted before the date
+type PurgeParams struct {
+	CreatedBy string   `json:"created_by"`
+	Before    string   `json:"before,omitempty"`
+	IDs       []string `json:"ids,omitempty"`
+}
 
-	return &transaction, err
+// PurgeTransactions permanently removes deleted transactions with their
+// payments and attachment records, and returns how many were removed.
+// Occurrences of a purged recurring transaction are kept as standalone
+// transactions. The latest occurrence of a recurring transaction still in
+// use is kept even once deleted, as it marks where generating occurrences
+// resumes; it goes once its recurring transaction is purged.
+func (s *TransactionService) PurgeTransactions(ctx context.Context, params PurgeParams) (int, error) {
+	if params.CreatedBy == "" {
+		params.CreatedBy = DefaultUserID
+	}
+	if params.Before == "" && len(params.IDs) == 0 {
+		return 0, fmt.Errorf("a date or transactions to purge are required")
+	}
+	before := ""
+	if params.Before != "" {
+		date, err := time.Parse("2006-01-02", params.Before)
+		if err != nil {
+			return 0, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", params.Before)
+		}
+		before = date.Format("2006-01-02 15:04:05")
+	}
+	return s.purge(ctx, params.CreatedBy, before, params.IDs)
 }
 
-// DeleteTransaction soft deletes a transaction
-func (s *TransactionService) DeleteTransaction(ctx context.Context, id string) error {
-	return s.db.Queries().DeleteTransaction(ctx, id)
+// PurgeExpiredTransactions purges the transactions each user deleted longer
+// ago than their trash retention period
+func (s *TransactionService) PurgeExpiredTransactions(ctx context.Context) (int, error) {
+	users, err := s.db.Queries().ListUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	purged := 0
+	for _, user := range users {
+		prefs, err := s.preferences.GetPreferences(ctx, user.ID)
+		if err != nil {
+			return purged, err
+		}
+		if prefs.TrashRetentionDays <= 0 {
+			continue
+		}
+		// deleted_at holds UTC timestamps
+		cutoff := time.Now().UTC().AddDate(0, 0, -prefs.TrashRetentionDays)
+		n, err := s.purge(ctx, user.ID, cutoff.Format("2006-01-02 15:04:05"), nil)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
 }
 
-// GetTransactionStats gets transaction statistics
-func (s *TransactionService) GetTransactionStats(ctx context.Context, params StatsParams) (*db.GetTransactionStatsRow, error) {
+// purge removes the deleted transactions of a user deleted before the
+// timestamp before, if set, and among ids, if any
+func (s *TransactionService) purge(ctx context.Context, createdBy, before string, ids []string) (int, error) {
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	purgeable, err := qtx.ListPurgeableTransactions(ctx, db.ListPurgeableTransactionsParams{
+		CreatedBy: createdBy,
+		Before:    before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list deleted transactions: %w", err)
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	purged := 0
+	for _, id := range purgeable {
+		if len(ids) > 0 && !selected[id] {
+			continue
+		}
+		transaction, err := qtx.GetTransactionWithDeleted(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get transaction: %w", err)
+		}
+		if err := recordHistory(ctx, qtx, HistoryPurge, &transaction, nil); err != nil {
+			return 0, err
+		}
+		if err := qtx.DetachChildTransactions(ctx, toSqlNullString(id)); err != nil {
+			return 0, fmt.Errorf("failed to purge transaction: %w", err)
+		}
+		n, err := qtx.PurgeTransaction(ctx, id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge transaction: %w", err)
+		}
+		purged += int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return purged, nil
+}
+
+// GetTransactionStats gets transaction statistics in the reporting currency
+func (s *TransactionService) GetTransactionStats(ctx context.Context, params StatsParams) (*TransactionStats, error) {
 	if params.CreatedBy == "" {
-		params.CreatedBy = "default"
+		params.CreatedBy = DefaultUserID
+	}
+
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, err
 	}
 
-	stats, err := s.db.Queries().GetTransactionStats(ctx, db.GetTransactionStatsParams{
+	rows, err := s.db.Queries().GetTransactionStats(ctx, db.GetTransactionStatsParams{
 		CreatedBy: params.CreatedBy,
 		FromDate:  params.FromDate,
 		ToDate:    params.ToDate,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are grouped by currency, date and rate so each group converts
+	// once; those without an exchange rate are left out
+	var income, expenses, net, pendingIncome, pendingExpenses int64
+	stats := &TransactionStats{Currency: converter.target}
+rows:
+	for _, row := range rows {
+		for _, total := range []struct {
+			dst   *int64
+			minor int64
+		}{
+			{&income, row.TotalIncome},
+			{&expenses, row.TotalExpenses},
+			{&net, row.TotalNetAmount},
+			{&pendingIncome, row.PendingIncome},
+			{&pendingExpenses, row.PendingExpenses},
+		} {
+			converted, err := converter.convert(ctx, total.minor, row.Currency, row.TransactionDate, row.ExchangeRate)
+			if err != nil {
+				if err := converter.setAside(err, row.Currency, "income", row.TotalIncome); err != nil {
+					return nil, err
+				}
+				converter.setAside(err, row.Currency, "expense", row.TotalExpenses)
+				continue rows
+			}
+			*total.dst += converted
+		}
+
+		stats.TotalTransactions += int(row.TotalTransactions)
+		stats.TotalIncomeCount += int(row.TotalIncomeCount)
+		stats.TotalExpenseCount += int(row.TotalExpenseCount)
+	}
 
-	return &stats, err
+	exponent := s.currencies.Exponent(ctx, converter.target)
+	stats.TotalIncome = fromMinorUnits(income, exponent)
+	stats.TotalExpenses = fromMinorUnits(expenses, exponent)
+	stats.NetProfit = fromMinorUnits(income-expenses, exponent)
+	stats.PendingIncome = fromMinorUnits(pendingIncome, exponent)
+	stats.PendingExpenses = fromMinorUnits(pendingExpenses, exponent)
+	if stats.TotalTransactions > 0 {
+		stats.AverageTransaction = fromMinorUnits(net, exponent) / float64(stats.TotalTransactions)
+	}
+	stats.Unconverted = converter.unconvertedAmounts(ctx)
+	return stats, nil
 }
 
-// GetTransactionsByCategory gets transactions grouped by category
-func (s *TransactionService) GetTransactionsByCategory(ctx context.Context, params StatsParams) ([]db.GetTransactionsByCategoryRow, error) {
+// GetTransactionsByCategory gets category totals in the reporting currency,
+// largest total first. Amounts that have no exchange rate into it are
+// totalled in their own currency.
+func (s *TransactionService) GetTransactionsByCategory(ctx context.Context, params StatsParams) ([]CategoryTotal, error) {
 	if params.CreatedBy == "" {
-		params.CreatedBy = "default"
+		params.CreatedBy = DefaultUserID
 	}
 
-	return s.db.Queries().GetTransactionsByCategory(ctx, db.GetTransactionsByCategoryParams{
+	converter, err := s.rates.newConverter(ctx, params.CreatedBy, params.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Queries().GetTransactionsByCategory(ctx, db.GetTransactionsByCategoryParams{
 		CreatedBy: params.CreatedBy,
 		FromDate:  params.FromDate,
 		ToDate:    params.ToDate,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are split by currency, date and rate; merge them per category and
+	// type. Amounts without an exchange rate get totals in their own currency.
+	type key struct{ category, txType, currency string }
+	totals := make(map[key]int64)
+	counts := make(map[key]int)
+	var order []key
+	for _, row := range rows {
+		k := key{row.CategoryID.String, row.Type, converter.target}
+		converted, err := converter.convert(ctx, row.TotalAmount, row.Currency, row.TransactionDate, row.ExchangeRate)
+		if err != nil {
+			if !errors.Is(err, errNoExchangeRate) {
+				return nil, err
+			}
+			k.currency = normalizeCurrency(row.Currency)
+			converted = row.TotalAmount
+		}
+		if _, ok := counts[k]; !ok {
+			order = append(order, k)
+		}
+		totals[k] += converted
+		counts[k] += int(row.Count)
+	}
+
+	result := make([]CategoryTotal, 0, len(order))
+	for _, k := range order {
+		result = append(result, CategoryTotal{
+			CategoryID:  k.category,
+			Type:        k.txType,
+			Count:       counts[k],
+			TotalAmount: fromMinorUnits(totals[k], s.currencies.Exponent(ctx, k.currency)),
+			Currency:    k.currency,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if converted := result[i].Currency == converter.target; converted != (result[j].Currency == converter.target) {
+			return converted
+		}
+		return result[i].TotalAmount > result[j].TotalAmount
+	})
+	return result, nil
 }
 
 // GetCategories retrieves all transaction categories
@@ -198,37 +638,210 @@ func (s *TransactionService) GetCategories(ctx context.Context, typeFilter strin
 }
 
 // GetRecentTransactions gets recent transactions
-func (s *TransactionService) GetRecentTransactions(ctx context.Context, limit int) ([]db.Transaction, error) {
+func (s *TransactionService) GetRecentTransactions(ctx context.Context, createdBy string, limit int) ([]db.Transaction, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
 	return s.db.Queries().GetRecentTransactions(ctx, db.GetRecentTransactionsParams{
-		CreatedBy: "default",
+		CreatedBy: createdBy,
+		Limit:     int64(limit),
+	})
+}
+
+// SearchResult is a transaction matching a search, with the best matching
+// part of its text. Snippet is HTML-escaped with matches wrapped in <mark>.
+// Lower scores are better matches.
+type SearchResult struct {
+	Transaction db.Transaction
+	Snippet     string
+	Score       float64
+}
+
+// SearchTransactions searches the description, customer/vendor, reference
+// number, invoice number and notes of transactions using the full-text
+// index, best matches first. Every word must match, as a prefix; text in
+// double quotes must match as a phrase. When SQLite was built without FTS5
+// the whole term, quotes removed, is matched as a substring instead, newest
+// first.
+func (s *TransactionService) SearchTransactions(ctx context.Context, createdBy, searchTerm string, limit, offset int) ([]SearchResult, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
+	if !s.db.FullTextSearch() {
+		return s.searchTransactionsLike(ctx, createdBy, searchTerm, limit, offset)
+	}
+	query := ftsQuery(searchTerm)
+	if query == "" {
+		return []SearchResult{}, nil
+	}
+
+	rows, err := s.db.Queries().SearchTransactions(ctx, db.SearchTransactionsParams{
+		Query:     query,
+		CreatedBy: createdBy,
 		Limit:     int64(limit),
+		Offset:    int64(offset),
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, SearchResult{
+			Transaction: db.Transaction{
+				ID:                  row.ID,
+				Type:                row.Type,
+				Description:         row.Description,
+				Amount:              row.Amount,
+				TransactionDate:     row.TransactionDate,
+				CategoryID:          row.CategoryID,
+				Tags:                row.Tags,
+				CustomerVendor:      row.CustomerVendor,
+				PaymentMethodID:     row.PaymentMethodID,
+				PaymentStatus:       row.PaymentStatus,
+				ReferenceNumber:     row.ReferenceNumber,
+				InvoiceNumber:       row.InvoiceNumber,
+				Notes:               row.Notes,
+				Attachments:         row.Attachments,
+				TaxAmount:           row.TaxAmount,
+				DiscountAmount:      row.DiscountAmount,
+				DueAmount:           row.DueAmount,
+				NetAmount:           row.NetAmount,
+				Currency:            row.Currency,
+				ExchangeRate:        row.ExchangeRate,
+				IsRecurring:         row.IsRecurring,
+				RecurringFrequency:  row.RecurringFrequency,
+				RecurringEndDate:    row.RecurringEndDate,
+				ParentTransactionID: row.ParentTransactionID,
+				CreatedBy:           row.CreatedBy,
+				CreatedAt:           row.CreatedAt,
+				UpdatedAt:           row.UpdatedAt,
+				DeletedAt:           row.DeletedAt,
+				DueDate:             row.DueDate,
+				ContactID:           row.ContactID,
+				AccountID:           row.AccountID,
+				ClearedStatus:       row.ClearedStatus,
+			},
+			Snippet: highlightSnippet(row.Snippet),
+			Score:   row.Score,
+		})
+	}
+	return results, nil
 }
 
-// SearchTransactions searches transactions
-func (s *TransactionService) SearchTransactions(ctx context.Context, searchTerm string, limit, offset int) ([]db.Transaction, error) {
-	return s.db.Queries().SearchTransactions(ctx, db.SearchTransactionsParams{
-		CreatedBy:       "default",
-		Column2:         toSqlNullString(searchTerm),
-		Column3:         toSqlNullString(searchTerm),
-		Column4:         toSqlNullString(searchTerm),
-		Column5:         toSqlNullString(searchTerm),
-		Column6:         toSqlNullString(searchTerm),
-		Limit:           int64(limit),
-		Offset:          int64(offset),
+// searchTransactionsLike is SearchTransactions without the full-text index.
+// The snippet is the first field containing the term.
+func (s *TransactionService) searchTransactionsLike(ctx context.Context, createdBy, searchTerm string, limit, offset int) ([]SearchResult, error) {
+	term := strings.TrimSpace(strings.ReplaceAll(searchTerm, `"`, ""))
+	if term == "" {
+		return []SearchResult{}, nil
+	}
+
+	transactions, err := s.db.Queries().SearchTransactionsLike(ctx, db.SearchTransactionsLikeParams{
+		CreatedBy: createdBy,
+		Search:    term,
+		Limit:     int64(limit),
+		Offset:    int64(offset),
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(transactions))
+	for _, t := range transactions {
+		results = append(results, SearchResult{
+			Transaction: t,
+			Snippet:     likeSnippet(term, t.Description, t.CustomerVendor.String, t.ReferenceNumber.String, t.InvoiceNumber.String, t.Notes.String),
+		})
+	}
+	return results, nil
+}
+
+// likeSnippet returns the first field containing term with the match
+// marked, as highlightSnippet expects. Like SQLite's LIKE, the comparison
+// ignores case for ASCII letters only.
+func likeSnippet(term string, fields ...string) string {
+	lowerTerm := asciiLower(term)
+	for _, field := range fields {
+		if i := strings.Index(asciiLower(field), lowerTerm); i >= 0 {
+			end := i + len(term)
+			return highlightSnippet(field[:i] + "\x02" + field[i:end] + "\x03" + field[end:])
+		}
+	}
+	return ""
+}
+
+// asciiLower lowercases ASCII letters, keeping byte offsets unchanged
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
+
+// ftsQuery turns what the user typed into an FTS5 query: quoted text stays
+// a phrase and every other word becomes a prefix term. Everything is quoted
+// so FTS5 operators and punctuation in the input are matched literally.
+func ftsQuery(term string) string {
+	var terms []string
+	for len(term) > 0 {
+		term = strings.TrimLeftFunc(term, unicode.IsSpace)
+		if term == "" {
+			break
+		}
+
+		var word string
+		prefix := true
+		if term[0] == '"' {
+			end := strings.IndexByte(term[1:], '"')
+			if end < 0 {
+				word, term = term[1:], ""
+			} else {
+				word, term = term[1:end+1], term[end+2:]
+			}
+			prefix = false
+		} else {
+			end := strings.IndexFunc(term, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(term)
+			}
+			word, term = term[:end], term[end:]
+		}
+
+		// Terms without letters or digits have no tokens and would not match
+		if strings.IndexFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		quoted := `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+	return strings.Join(terms, " ")
+}
+
+// highlightSnippet escapes a snippet for HTML and turns the match markers
+// the search query puts around matched words into <mark> elements
+func highlightSnippet(snippet string) string {
+	return strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>").Replace(html.EscapeString(snippet))
 }
 
 // GetDescriptionSuggestions retrieves description suggestions based on search term and type
 func (s *TransactionService) GetDescriptionSuggestions(ctx context.Context, createdBy, transactionType, search string, limit int) ([]SuggestionItem, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
 	if limit <= 0 {
 		limit = 10
 	}
 
 	results, err := s.db.Queries().GetDescriptionSuggestions(ctx, db.GetDescriptionSuggestionsParams{
 		CreatedBy:  createdBy,
-		Column2:    transactionType,
-		Column4:    toSqlNullString(search),
+		TypeFilter: transactionType,
+		Search:     search,
 		Limit:      int64(limit),
 	})
 	if err != nil {
@@ -247,14 +860,17 @@ func (s *TransactionService) GetDescriptionSuggestions(ctx context.Context, crea
 
 // GetCustomerVendorSuggestions retrieves customer/vendor suggestions based on search term and type
 func (s *TransactionService) GetCustomerVendorSuggestions(ctx context.Context, createdBy, transactionType, search string, limit int) ([]SuggestionItem, error) {
+	if createdBy == "" {
+		createdBy = DefaultUserID
+	}
 	if limit <= 0 {
 		limit = 10
 	}
 
 	results, err := s.db.Queries().GetCustomerVendorSuggestions(ctx, db.GetCustomerVendorSuggestionsParams{
 		CreatedBy:  createdBy,
-		Column2:    transactionType,
-		Column4:    toSqlNullString(search),
+		TypeFilter: transactionType,
+		Search:     search,
 		Limit:      int64(limit),
 	})
 	if err != nil {
@@ -281,6 +897,7 @@ type CreateTransactionParams struct {
 	Tags                []string  `json:"tags"`
 	CustomerVendor      string    `json:"customer_vendor"`
 	PaymentMethod       string    `json:"payment_method"`
+	Account             string    `json:"account,omitempty"`
 	PaymentStatus       string    `json:"payment_status"`
 	ReferenceNumber     string    `json:"reference_number"`
 	InvoiceNumber       string    `json:"invoice_number"`
@@ -295,6 +912,8 @@ type CreateTransactionParams struct {
 	RecurringFrequency  string    `json:"recurring_frequency"`
 	RecurringEndDate    string    `json:"recurring_end_date"`
 	ParentTransactionID string    `json:"parent_transaction_id"`
+	DueDate             string    `json:"due_date,omitempty"`
+	ContactID           string    `json:"contact_id,omitempty"`
 	CreatedBy           string    `json:"created_by"`
 }
 
@@ -307,6 +926,7 @@ type UpdateTransactionParams struct {
 	Tags               []string `json:"tags"`
 	CustomerVendor     string   `json:"customer_vendor"`
 	PaymentMethod      string   `json:"payment_method"`
+	Account            string   `json:"account,omitempty"`
 	PaymentStatus      string   `json:"payment_status"`
 	ReferenceNumber    string   `json:"reference_number"`
 	InvoiceNumber      string   `json:"invoice_number"`
@@ -320,6 +940,8 @@ type UpdateTransactionParams struct {
 	IsRecurring        bool     `json:"is_recurring"`
 	RecurringFrequency string   `json:"recurring_frequency"`
 	RecurringEndDate   string   `json:"recurring_end_date"`
+	DueDate            string   `json:"due_date,omitempty"`
+	ContactID          string   `json:"contact_id,omitempty"`
 }
 
 type ListTransactionParams struct {
@@ -330,18 +952,58 @@ type ListTransactionParams struct {
 	CategoryFilter        []string `json:"category"`
 	PaymentStatusFilter   []string `json:"payment_status"`
 	PaymentMethodFilter   []string `json:"payment_method"`
+	ContactFilter         string   `json:"contact,omitempty"`
+	AccountFilter         string   `json:"account,omitempty"`
 	CustomerVendorSearch  string   `json:"customer_vendor"`
 	DescriptionSearch     string   `json:"search"`
 	MinDueAmount          float64  `json:"min_due_amount"`
 	MaxDueAmount          float64  `json:"max_due_amount"`
 	Limit                 int      `json:"limit"`
 	Offset                int      `json:"offset"`
+	IgnoreDefaultFilter   bool     `json:"ignore_default_filter,omitempty"`
+}
+
+// hasFilter reports whether any filter field is set
+func (p ListTransactionParams) hasFilter() bool {
+	return p.FromDate != "" || p.ToDate != "" ||
+		len(p.TypeFilter) > 0 || len(p.CategoryFilter) > 0 ||
+		len(p.PaymentStatusFilter) > 0 || len(p.PaymentMethodFilter) > 0 ||
+		p.ContactFilter != "" || p.AccountFilter != "" ||
+		p.CustomerVendorSearch != "" || p.DescriptionSearch != "" ||
+		p.MinDueAmount != 0 || p.MaxDueAmount != 0
 }
 
 type StatsParams struct {
 	CreatedBy string `json:"created_by"`
 	FromDate  string `json:"from_date"`
 	ToDate    string `json:"to_date"`
+	Currency  string `json:"currency,omitempty"` // reporting currency, defaults to the base currency
+}
+
+// TransactionStats holds aggregate totals in the reporting currency.
+// Unconverted lists what was left out for want of an exchange rate.
+type TransactionStats struct {
+	Currency           string  `json:"currency"`
+	TotalIncome        float64 `json:"total_income"`
+	TotalExpenses      float64 `json:"total_expenses"`
+	NetProfit          float64 `json:"net_profit"`
+	TotalTransactions  int     `json:"total_transactions"`
+	TotalIncomeCount   int     `json:"total_income_count"`
+	TotalExpenseCount  int     `json:"total_expense_count"`
+	AverageTransaction float64 `json:"average_transaction"`
+	PendingIncome      float64 `json:"pending_income"`
+	PendingExpenses    float64 `json:"pending_expenses"`
+
+	Unconverted []UnconvertedAmount `json:"unconverted,omitempty"`
+}
+
+// CategoryTotal is the net amount of one category and transaction type
+type CategoryTotal struct {
+	CategoryID  string  `json:"category_id"`
+	Type        string  `json:"type"`
+	Count       int     `json:"count"`
+	TotalAmount float64 `json:"total_amount"`
+	Currency    string  `json:"currency"`
 }
 
 // SuggestionItem represents a suggestion with frequency
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDateRangeIncludesLastDay(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	s := NewTransactionService(d)
+
+	categories, err := s.GetCategories(ctx, "expense")
+	if err != nil || len(categories) == 0 {
+		t.Fatalf("no expense categories: %v", err)
+	}
+	for _, date := range []string{"2023-12-31", "2024-01-01", "2024-01-31", "2024-02-01"} {
+		createTestTransaction(t, s, 10, func(p *CreateTransactionParams) {
+			p.TransactionDate = date
+			p.Category = categories[0].ID
+		})
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     int
+	}{
+		{"month", "2024-01-01", "2024-01-31", 2},
+		{"first day", "2024-01-01", "2024-01-01", 1},
+		{"last day", "2024-01-31", "2024-01-31", 1},
+		{"across the month end", "2024-01-31", "2024-02-01", 2},
+		{"no transactions", "2024-01-02", "2024-01-30", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, err := s.ListTransactions(ctx, ListTransactionParams{FromDate: tt.from, ToDate: tt.to})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(transactions) != tt.want {
+				t.Errorf("listed %d transactions, want %d", len(transactions), tt.want)
+			}
+
+			stats, err := s.GetTransactionStats(ctx, StatsParams{FromDate: tt.from, ToDate: tt.to})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.TotalTransactions != tt.want || stats.TotalExpenses != float64(10*tt.want) {
+				t.Errorf("stats count %d transactions totalling %.2f, want %d totalling %d", stats.TotalTransactions, stats.TotalExpenses, tt.want, 10*tt.want)
+			}
+
+			totals, err := s.GetTransactionsByCategory(ctx, StatsParams{FromDate: tt.from, ToDate: tt.to})
+			if err != nil {
+				t.Fatal(err)
+			}
+			count := 0
+			for _, category := range totals {
+				count += category.Count
+			}
+			if count != tt.want {
+				t.Errorf("categories count %d transactions, want %d", count, tt.want)
+			}
+		})
+	}
+}
@@ -1,75 +1,184 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"time"
+	"strings"
 
+	"cashflow/internal/database"
+	db "cashflow/internal/db/sqlc"
 	"cashflow/internal/models"
 )
 
+// DefaultUserID is the user every install starts with
+const DefaultUserID = "default"
+
 // UserService handles business logic for users
 type UserService struct {
-	// Add database connection or repository here
+	db *database.Database
 }
 
 // NewUserService creates a new instance of UserService
-func NewUserService() *UserService {
-	return &UserService{}
+func NewUserService(db *database.Database) *UserService {
+	return &UserService{db: db}
 }
 
 // GetUser retrieves a user by ID
-func (s *UserService) GetUser(id string) (*models.User, error) {
-	// Example implementation - replace with actual database logic
-	return &models.User{
-		ID:        id,
-		Name:      "John Doe",
-		Email:     "john@example.com",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}, nil
+func (s *UserService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	user, err := s.db.Queries().GetUser(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return convertUser(&user), nil
+}
+
+// ListUsers lists all users, the default user first
+func (s *UserService) ListUsers(ctx context.Context) ([]models.User, error) {
+	users, err := s.db.Queries().ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	result := make([]models.User, 0, len(users))
+	for _, u := range users {
+		result = append(result, *convertUser(&u))
+	}
+	return result, nil
 }
 
 // CreateUser creates a new user
-func (s *UserService) CreateUser(req *models.UserCreateRequest) (*models.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, req *models.UserCreateRequest) (*models.User, error) {
 	// Validate input
-	if req.Name == "" || req.Email == "" {
+	name := strings.TrimSpace(req.Name)
+	email := strings.TrimSpace(req.Email)
+	if name == "" || email == "" {
 		return nil, fmt.Errorf("name and email are required")
 	}
-
-	// Example implementation - replace with actual database logic
-	user := &models.User{
-		ID:        fmt.Sprintf("user_%d", time.Now().Unix()),
-		Name:      req.Name,
-		Email:     req.Email,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("invalid email address")
 	}
 
-	return user, nil
+	user, err := s.db.Queries().CreateUser(ctx, db.CreateUserParams{
+		Name:  name,
+		Email: toSqlNullString(email),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return convertUser(&user), nil
 }
 
 // UpdateUser updates an existing user
-func (s *UserService) UpdateUser(id string, req *models.UserUpdateRequest) (*models.User, error) {
+func (s *UserService) UpdateUser(ctx context.Context, id string, req *models.UserUpdateRequest) (*models.User, error) {
 	// Get existing user
-	user, err := s.GetUser(id)
+	user, err := s.db.Queries().GetUser(ctx, id)
 	if err != nil {
-		return nil, err
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Update fields if provided
+	name := user.Name
 	if req.Name != "" {
-		user.Name = req.Name
+		name = strings.TrimSpace(req.Name)
 	}
+	email := user.Email
 	if req.Email != "" {
-		user.Email = req.Email
+		if !strings.Contains(req.Email, "@") {
+			return nil, fmt.Errorf("invalid email address")
+		}
+		email = toSqlNullString(strings.TrimSpace(req.Email))
+	}
+
+	user, err = s.db.Queries().UpdateUser(ctx, db.UpdateUserParams{
+		Name:  name,
+		Email: email,
+		ID:    id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return convertUser(&user), nil
+}
+
+// DeleteUser deletes a user together with their templates, saved filters,
+// budgets, contacts and API tokens. Users who still own transactions,
+// payments, accounts, transfers or reconciliations cannot be deleted; the
+// error lists what they own.
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	if id == DefaultUserID {
+		return fmt.Errorf("the default user cannot be deleted")
+	}
+	if _, err := s.GetUser(ctx, id); err != nil {
+		return err
+	}
+
+	counts, err := s.db.Queries().CountUserRecords(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to check user records: %w", err)
+	}
+	var owned []string
+	for _, c := range []struct {
+		count int64
+		name  string
+	}{
+		{counts.Transactions, "transaction(s)"},
+		{counts.Payments, "payment(s)"},
+		{counts.Accounts, "account(s)"},
+		{counts.Transfers, "transfer(s)"},
+		{counts.Reconciliations, "reconciliation(s)"},
+	} {
+		if c.count > 0 {
+			owned = append(owned, fmt.Sprintf("%d %s", c.count, c.name))
+		}
+	}
+	if len(owned) > 0 {
+		return fmt.Errorf("cannot delete user: %s belong to this user", strings.Join(owned, ", "))
 	}
-	user.UpdatedAt = time.Now()
 
-	return user, nil
+	tx, err := s.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := s.db.Queries().WithTx(tx)
+	if err := qtx.DeleteTemplatesByUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user templates: %w", err)
+	}
+	if err := qtx.DeleteSavedFiltersByUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user filters: %w", err)
+	}
+	if err := qtx.DeleteBudgetsByUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user budgets: %w", err)
+	}
+	if err := qtx.DeleteContactsByUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user contacts: %w", err)
+	}
+	// API tokens go with the user through ON DELETE CASCADE
+	if err := qtx.DeleteUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return tx.Commit()
 }
 
-// DeleteUser deletes a user by ID
-func (s *UserService) DeleteUser(id string) error {
-	// Example implementation - replace with actual database logic
-	return nil
-}
\ No newline at end of file
+func convertUser(u *db.User) *models.User {
+	user := &models.User{
+		ID:    u.ID,
+		Name:  u.Name,
+		Email: u.Email.String,
+	}
+	if u.CreatedAt.Valid {
+		user.CreatedAt = u.CreatedAt.Time
+	}
+	if u.UpdatedAt.Valid {
+		user.UpdatedAt = u.UpdatedAt.Time
+	}
+	return user
+}
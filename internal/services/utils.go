@@ -13,11 +13,19 @@ func toSqlNullString(s string) sql.NullString {
 	}
 }
 
-// toSqlNullFloat64 converts a float64 to sql.NullFloat64
+// toSqlNullFloat64 converts a float64 to sql.NullFloat64, 0 being NULL
 func toSqlNullFloat64(f float64) sql.NullFloat64 {
 	return sql.NullFloat64{
 		Float64: f,
-		Valid:   true,
+		Valid:   f != 0,
+	}
+}
+
+// toSqlNullMinorUnits converts a decimal amount to sql.NullInt64 minor units
+func toSqlNullMinorUnits(amount float64, exponent int) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: toMinorUnits(amount, exponent),
+		Valid: true,
 	}
 }
 
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Guarantee a recurring parent produces at most one occurrence per date
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_recurring_occurrence
+    ON transactions(parent_transaction_id, transaction_date)
+    WHERE parent_transaction_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_recurring_occurrence;
@@ -0,0 +1,89 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+func init() {
+	register(3, "transactions_due_amount", upTransactionsDueAmount, downTransactionsDueAmount)
+}
+
+// Databases created by the application before versioned migrations have a
+// transactions table without due_amount and with net_amount generated as
+// amount - discount_amount + tax_amount. Rebuild it into the shape defined in
+// 001_init_schema.sql; on databases that already match this is a plain copy.
+func upTransactionsDueAmount(ctx context.Context, tx *sql.Tx) error {
+	if err := rebuildTable(ctx, tx, "transactions", `
+CREATE TABLE transactions_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT NOT NULL,
+    amount REAL NOT NULL CHECK (amount >= 0),
+    transaction_date DATE NOT NULL,
+    category_id TEXT REFERENCES categories(id),
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    payment_status TEXT DEFAULT 'completed' CHECK (payment_status IN ('pending', 'completed', 'partial', 'cancelled')),
+    reference_number TEXT,
+    invoice_number TEXT,
+    notes TEXT,
+    attachments TEXT,
+    tax_amount REAL DEFAULT 0,
+    discount_amount REAL DEFAULT 0,
+    due_amount REAL DEFAULT 0,
+    net_amount REAL GENERATED ALWAYS AS (amount - discount_amount + tax_amount - due_amount) STORED,
+    currency TEXT DEFAULT 'USD',
+    exchange_rate REAL DEFAULT 1.0,
+    is_recurring BOOLEAN DEFAULT FALSE,
+    recurring_frequency TEXT CHECK (recurring_frequency IN ('daily', 'weekly', 'monthly', 'quarterly', 'yearly')),
+    recurring_end_date DATE,
+    parent_transaction_id TEXT REFERENCES transactions(id),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);`, nil); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS idx_transactions_due_amount ON transactions(due_amount);`)
+	return err
+}
+
+// downTransactionsDueAmount restores the pre-migration table, dropping due_amount
+func downTransactionsDueAmount(ctx context.Context, tx *sql.Tx) error {
+	return rebuildTable(ctx, tx, "transactions", `
+CREATE TABLE transactions_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT NOT NULL,
+    amount REAL NOT NULL CHECK (amount >= 0),
+    transaction_date DATE NOT NULL,
+    category_id TEXT REFERENCES categories(id),
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    payment_status TEXT DEFAULT 'completed' CHECK (payment_status IN ('pending', 'completed', 'partial', 'cancelled')),
+    reference_number TEXT,
+    invoice_number TEXT,
+    notes TEXT,
+    attachments TEXT,
+    tax_amount REAL DEFAULT 0,
+    discount_amount REAL DEFAULT 0,
+    net_amount REAL GENERATED ALWAYS AS (amount - discount_amount + tax_amount) STORED,
+    currency TEXT DEFAULT 'USD',
+    exchange_rate REAL DEFAULT 1.0,
+    is_recurring BOOLEAN DEFAULT FALSE,
+    recurring_frequency TEXT CHECK (recurring_frequency IN ('daily', 'weekly', 'monthly', 'quarterly', 'yearly')),
+    recurring_end_date DATE,
+    parent_transaction_id TEXT REFERENCES transactions(id),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);`, nil)
+}
@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	register(4, "templates_and_filters", upTemplatesAndFilters, downTemplatesAndFilters)
+}
+
+// upTemplatesAndFilters creates the template and saved filter tables, which
+// the application used to create at runtime. Databases from before
+// versioned migrations may already have them, with their users' data.
+func upTemplatesAndFilters(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS transaction_templates (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT,
+    amount REAL,
+    category TEXT,
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method TEXT,
+    tax_amount REAL DEFAULT 0,
+    discount_amount REAL DEFAULT 0,
+    notes TEXT,
+    usage_count INTEGER DEFAULT 0,
+    is_favorite BOOLEAN DEFAULT FALSE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS saved_transaction_filters (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    filter_config TEXT NOT NULL,
+    is_default BOOLEAN DEFAULT FALSE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`)
+	return err
+}
+
+// downTemplatesAndFilters drops the template and saved filter tables, but
+// only while they are empty: on a database adopted from before versioned
+// migrations they held data before this migration ran, which rolling it back
+// must not delete
+func downTemplatesAndFilters(ctx context.Context, tx *sql.Tx) error {
+	var templates, filters int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM transaction_templates`).Scan(&templates); err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_transaction_filters`).Scan(&filters); err != nil {
+		return err
+	}
+	if templates > 0 || filters > 0 {
+		return fmt.Errorf("there are %d template(s) and %d saved filter(s), which rolling back would delete; remove them first", templates, filters)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+DROP TABLE IF EXISTS saved_transaction_filters;
+DROP TABLE IF EXISTS transaction_templates;`)
+	return err
+}
@@ -0,0 +1,61 @@
+-- +goose Up
+-- Currencies and their ISO 4217 exponent (number of minor unit digits).
+-- Money columns store integer minor units; scale converts them to and from
+-- decimal amounts (e.g. 1234 cents / 100 = 12.34 USD).
+
+CREATE TABLE IF NOT EXISTS currencies (
+    code TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    exponent INTEGER NOT NULL DEFAULT 2 CHECK (exponent BETWEEN 0 AND 4),
+    scale INTEGER GENERATED ALWAYS AS (
+        CASE exponent WHEN 0 THEN 1 WHEN 1 THEN 10 WHEN 2 THEN 100 WHEN 3 THEN 1000 ELSE 10000 END
+    ) STORED
+);
+
+INSERT INTO currencies (code, name, exponent) VALUES
+    ('USD', 'US Dollar', 2),
+    ('EUR', 'Euro', 2),
+    ('GBP', 'Pound Sterling', 2),
+    ('JPY', 'Yen', 0),
+    ('CNY', 'Yuan Renminbi', 2),
+    ('INR', 'Indian Rupee', 2),
+    ('BDT', 'Taka', 2),
+    ('PKR', 'Pakistan Rupee', 2),
+    ('CAD', 'Canadian Dollar', 2),
+    ('AUD', 'Australian Dollar', 2),
+    ('NZD', 'New Zealand Dollar', 2),
+    ('CHF', 'Swiss Franc', 2),
+    ('SEK', 'Swedish Krona', 2),
+    ('NOK', 'Norwegian Krone', 2),
+    ('DKK', 'Danish Krone', 2),
+    ('PLN', 'Zloty', 2),
+    ('CZK', 'Czech Koruna', 2),
+    ('HUF', 'Forint', 2),
+    ('ISK', 'Iceland Krona', 0),
+    ('TRY', 'Turkish Lira', 2),
+    ('RUB', 'Russian Ruble', 2),
+    ('KRW', 'Won', 0),
+    ('SGD', 'Singapore Dollar', 2),
+    ('HKD', 'Hong Kong Dollar', 2),
+    ('MYR', 'Malaysian Ringgit', 2),
+    ('THB', 'Baht', 2),
+    ('IDR', 'Rupiah', 2),
+    ('PHP', 'Philippine Peso', 2),
+    ('VND', 'Dong', 0),
+    ('AED', 'UAE Dirham', 2),
+    ('SAR', 'Saudi Riyal', 2),
+    ('KWD', 'Kuwaiti Dinar', 3),
+    ('BHD', 'Bahraini Dinar', 3),
+    ('OMR', 'Rial Omani', 3),
+    ('JOD', 'Jordanian Dinar', 3),
+    ('EGP', 'Egyptian Pound', 2),
+    ('NGN', 'Naira', 2),
+    ('KES', 'Kenyan Shilling', 2),
+    ('ZAR', 'Rand', 2),
+    ('MXN', 'Mexican Peso', 2),
+    ('BRL', 'Brazilian Real', 2),
+    ('CLP', 'Chilean Peso', 0)
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,252 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+)
+
+func init() {
+	register(6, "money_minor_units", upMoneyMinorUnits, downMoneyMinorUnits)
+}
+
+// roundAmountsEnv names the environment variable that lets the migration
+// round amounts it would otherwise stop at
+const roundAmountsEnv = "CASHFLOW_ROUND_AMOUNTS"
+
+// templateCurrency is the currency given to templates, which had none: the
+// one their creator's transactions use most, or the transactions default
+const templateCurrency = `COALESCE((
+    SELECT t.currency FROM transactions t
+    WHERE t.created_by = transaction_templates.created_by AND t.currency IS NOT NULL
+    GROUP BY t.currency
+    ORDER BY COUNT(*) DESC, t.currency
+    LIMIT 1
+), 'USD')`
+
+// scaleOf is the SQL for the minor unit scale of a currency expression;
+// currencies not in the table use 2 digits
+func scaleOf(currency string) string {
+	return fmt.Sprintf("COALESCE((SELECT scale FROM currencies WHERE code = %s), 100)", currency)
+}
+
+// upMoneyMinorUnits stores money as integer minor units of the row's
+// currency instead of REAL so sums are exact (12.34 USD becomes 1234
+// cents). Amounts with more decimal places than their currency has would
+// have to be rounded, so the migration fails and lists them instead, unless
+// CASHFLOW_ROUND_AMOUNTS is set, in which case it logs them and rounds them
+// half away from zero. Templates gain a currency so their amounts can be
+// interpreted.
+func upMoneyMinorUnits(ctx context.Context, tx *sql.Tx) error {
+	transactionCurrency := "COALESCE(transactions.currency, 'USD')"
+	transactionMoney := []string{"amount", "tax_amount", "discount_amount", "due_amount"}
+	templateMoney := []string{"amount", "tax_amount", "discount_amount"}
+
+	var problems []string
+	for _, check := range []struct {
+		table, currency string
+		columns         []string
+	}{
+		{"transactions", transactionCurrency, transactionMoney},
+		{"transaction_templates", templateCurrency, templateMoney},
+	} {
+		found, err := fractionalMinorUnits(ctx, tx, check.table, check.currency, check.columns)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, found...)
+	}
+	if len(problems) > 0 && os.Getenv(roundAmountsEnv) != "" {
+		log.Printf("Rounding %d amount(s) with more decimal places than their currency allows:", len(problems))
+		for _, problem := range problems {
+			log.Printf("  %s", problem)
+		}
+	} else if len(problems) > 0 {
+		listed := problems
+		if len(listed) > 10 {
+			listed = append(listed[:10:10], fmt.Sprintf("and %d more", len(problems)-10))
+		}
+		return fmt.Errorf("%d amount(s) have more decimal places than their currency allows and would be rounded; correct them first, or set %s=1 to round them: %s",
+			len(problems), roundAmountsEnv, strings.Join(listed, "; "))
+	}
+
+	exprs := make(map[string]string)
+	for _, col := range transactionMoney {
+		exprs[col] = fmt.Sprintf("CAST(ROUND(%s * %s) AS INTEGER)", col, scaleOf(transactionCurrency))
+	}
+	if err := rebuildTable(ctx, tx, "transactions", `
+CREATE TABLE transactions_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    transaction_date DATE NOT NULL,
+    category_id TEXT REFERENCES categories(id),
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    payment_status TEXT DEFAULT 'completed' CHECK (payment_status IN ('pending', 'completed', 'partial', 'cancelled')),
+    reference_number TEXT,
+    invoice_number TEXT,
+    notes TEXT,
+    attachments TEXT,
+    tax_amount INTEGER DEFAULT 0,
+    discount_amount INTEGER DEFAULT 0,
+    due_amount INTEGER DEFAULT 0,
+    net_amount INTEGER GENERATED ALWAYS AS (amount - discount_amount + tax_amount - due_amount) STORED,
+    currency TEXT DEFAULT 'USD',
+    exchange_rate REAL DEFAULT 1.0,
+    is_recurring BOOLEAN DEFAULT FALSE,
+    recurring_frequency TEXT CHECK (recurring_frequency IN ('daily', 'weekly', 'monthly', 'quarterly', 'yearly')),
+    recurring_end_date DATE,
+    parent_transaction_id TEXT REFERENCES transactions(id),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);`, exprs); err != nil {
+		return err
+	}
+
+	// The rebuilt transactions are what templateCurrency reads, and they
+	// still have the same currencies
+	exprs = map[string]string{"currency": templateCurrency}
+	for _, col := range templateMoney {
+		exprs[col] = fmt.Sprintf("CAST(ROUND(%s * %s) AS INTEGER)", col, scaleOf(templateCurrency))
+	}
+	return rebuildTable(ctx, tx, "transaction_templates", `
+CREATE TABLE transaction_templates_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT,
+    amount INTEGER,
+    category TEXT,
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method TEXT,
+    tax_amount INTEGER DEFAULT 0,
+    discount_amount INTEGER DEFAULT 0,
+    currency TEXT DEFAULT 'USD',
+    notes TEXT,
+    usage_count INTEGER DEFAULT 0,
+    is_favorite BOOLEAN DEFAULT FALSE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`, exprs)
+}
+
+// fractionalMinorUnits describes the money values of table that are not a
+// whole number of minor units of the currency the SQL expression currency
+// gives each row
+func fractionalMinorUnits(ctx context.Context, tx *sql.Tx, table, currency string, columns []string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT id, %s, %s, %s FROM %s",
+		currency, scaleOf(currency), strings.Join(columns, ", "), table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var id, code string
+		var scale int64
+		values := make([]sql.NullFloat64, len(columns))
+		dest := []any{&id, &code, &scale}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if value.Valid && !wholeMinorUnits(value.Float64, scale) {
+				problems = append(problems, fmt.Sprintf("%s %s: %s %v %s", table, id, columns[i], value.Float64, code))
+			}
+		}
+	}
+	return problems, rows.Err()
+}
+
+// wholeMinorUnits reports whether value is a whole number of minor units at
+// scale, allowing for the error of having stored it as a float
+func wholeMinorUnits(value float64, scale int64) bool {
+	units := value * float64(scale)
+	return math.Abs(units-math.Round(units)) <= math.Max(1e-6, math.Abs(units)*1e-12)
+}
+
+// downMoneyMinorUnits turns money back into REAL amounts and drops the
+// template currency
+func downMoneyMinorUnits(ctx context.Context, tx *sql.Tx) error {
+	transactionScale := scaleOf("COALESCE(transactions.currency, 'USD')")
+	exprs := make(map[string]string)
+	for _, col := range []string{"amount", "tax_amount", "discount_amount", "due_amount"} {
+		exprs[col] = fmt.Sprintf("%s * 1.0 / %s", col, transactionScale)
+	}
+	if err := rebuildTable(ctx, tx, "transactions", `
+CREATE TABLE transactions_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT NOT NULL,
+    amount REAL NOT NULL CHECK (amount >= 0),
+    transaction_date DATE NOT NULL,
+    category_id TEXT REFERENCES categories(id),
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    payment_status TEXT DEFAULT 'completed' CHECK (payment_status IN ('pending', 'completed', 'partial', 'cancelled')),
+    reference_number TEXT,
+    invoice_number TEXT,
+    notes TEXT,
+    attachments TEXT,
+    tax_amount REAL DEFAULT 0,
+    discount_amount REAL DEFAULT 0,
+    due_amount REAL DEFAULT 0,
+    net_amount REAL GENERATED ALWAYS AS (amount - discount_amount + tax_amount - due_amount) STORED,
+    currency TEXT DEFAULT 'USD',
+    exchange_rate REAL DEFAULT 1.0,
+    is_recurring BOOLEAN DEFAULT FALSE,
+    recurring_frequency TEXT CHECK (recurring_frequency IN ('daily', 'weekly', 'monthly', 'quarterly', 'yearly')),
+    recurring_end_date DATE,
+    parent_transaction_id TEXT REFERENCES transactions(id),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);`, exprs); err != nil {
+		return err
+	}
+
+	templateScale := scaleOf("COALESCE(transaction_templates.currency, 'USD')")
+	exprs = make(map[string]string)
+	for _, col := range []string{"amount", "tax_amount", "discount_amount"} {
+		exprs[col] = fmt.Sprintf("%s * 1.0 / %s", col, templateScale)
+	}
+	return rebuildTable(ctx, tx, "transaction_templates", `
+CREATE TABLE transaction_templates_new (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'sale', 'purchase')),
+    description TEXT,
+    amount REAL,
+    category TEXT,
+    tags TEXT,
+    customer_vendor TEXT,
+    payment_method TEXT,
+    tax_amount REAL DEFAULT 0,
+    discount_amount REAL DEFAULT 0,
+    notes TEXT,
+    usage_count INTEGER DEFAULT 0,
+    is_favorite BOOLEAN DEFAULT FALSE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`, exprs)
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import "testing"
+
+func TestWholeMinorUnits(t *testing.T) {
+	tests := []struct {
+		value float64
+		scale int64
+		want  bool
+	}{
+		{12.34, 100, true},
+		{0.29, 100, true},
+		{0.1 + 0.2, 100, true},
+		{1234567.89, 100, true},
+		{99999999.99, 100, true},
+		{12.345, 100, false},
+		{0.001, 100, false},
+		{1500, 1, true},
+		{1500.5, 1, false},
+		{1.234, 1000, true},
+		{1.2345, 1000, false},
+		{0, 100, true},
+	}
+	for _, tt := range tests {
+		if got := wholeMinorUnits(tt.value, tt.scale); got != tt.want {
+			t.Errorf("wholeMinorUnits(%v, %d) = %v, want %v", tt.value, tt.scale, got, tt.want)
+		}
+	}
+}
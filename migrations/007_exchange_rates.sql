@@ -0,0 +1,21 @@
+-- +goose Up
+-- Dated exchange rates: one unit of from_currency is worth rate units of
+-- to_currency from rate_date until the next rate for the pair.
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    from_currency TEXT NOT NULL,
+    to_currency TEXT NOT NULL,
+    rate REAL NOT NULL CHECK (rate > 0),
+    rate_date DATE NOT NULL,
+    source TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (from_currency, to_currency, rate_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_date ON exchange_rates(from_currency, to_currency, rate_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_exchange_rates_pair_date;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Files attached to transactions. The content lives in a content-addressed
+-- store under ~/.cashflow/attachments keyed by sha256, so identical files
+-- attached twice share one blob.
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    transaction_id TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    sha256 TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_transaction ON attachments(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_sha256 ON attachments(sha256);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_attachments_sha256;
+DROP INDEX IF EXISTS idx_attachments_transaction;
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Statement imports look transactions up by the bank's transaction ID
+CREATE INDEX IF NOT EXISTS idx_transactions_reference ON transactions(created_by, reference_number);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_reference;
@@ -0,0 +1,129 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+func init() {
+	register(10, "transactions_fts", upTransactionsFTS, downTransactionsFTS)
+}
+
+// upTransactionsFTS creates the full-text search index when SQLite has
+// FTS5. Without it the migration changes nothing and search falls back to
+// LIKE; the index is created the first time a build with FTS5 opens the
+// database (see EnsureTransactionsFTS).
+func upTransactionsFTS(ctx context.Context, tx *sql.Tx) error {
+	_, err := EnsureTransactionsFTS(ctx, tx)
+	return err
+}
+
+// EnsureTransactionsFTS creates the transactions_fts index if SQLite has
+// FTS5 and the index does not exist yet, and reports whether the index can
+// be searched. An existing index cannot be used, or even kept up to date by
+// its triggers, without FTS5, so a build without it drops the triggers and
+// searches with LIKE; the next build with FTS5 puts them back and rebuilds
+// the index.
+func EnsureTransactionsFTS(ctx context.Context, tx *sql.Tx) (bool, error) {
+	var enabled bool
+	if err := tx.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled); err != nil {
+		return false, err
+	}
+	var exists, triggers int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'transactions_fts'`).Scan(&exists); err != nil {
+		return false, err
+	}
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'transactions_fts_%'`).Scan(&triggers); err != nil {
+		return false, err
+	}
+
+	switch {
+	case exists > 0 && !enabled:
+		if err := dropTransactionsFTSTriggers(ctx, tx); err != nil {
+			return false, fmt.Errorf("failed to stop updating the search index: %w", err)
+		}
+		return false, nil
+	case exists > 0 && triggers < 3:
+		if err := dropTransactionsFTSTriggers(ctx, tx); err != nil {
+			return false, err
+		}
+		if err := createTransactionsFTSTriggers(ctx, tx); err != nil {
+			return false, err
+		}
+		return true, nil
+	case exists > 0:
+		return true, nil
+	case !enabled:
+		return false, nil
+	}
+	if err := createTransactionsFTS(ctx, tx); err != nil {
+		return false, err
+	}
+	if err := createTransactionsFTSTriggers(ctx, tx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// createTransactionsFTS creates an FTS5 table over the searchable text of
+// transactions that reads its content from transactions
+func createTransactionsFTS(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE VIRTUAL TABLE transactions_fts USING fts5(
+    description,
+    customer_vendor,
+    reference_number,
+    invoice_number,
+    notes,
+    content = 'transactions',
+    content_rowid = 'rowid',
+    tokenize = 'unicode61 remove_diacritics 2'
+);`)
+	return err
+}
+
+// createTransactionsFTSTriggers adds the triggers that keep the search index
+// in step with inserts, updates and deletes, and indexes the existing rows
+// by rebuilding it
+func createTransactionsFTSTriggers(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TRIGGER transactions_fts_insert AFTER INSERT ON transactions BEGIN
+    INSERT INTO transactions_fts (rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES (new.rowid, new.description, new.customer_vendor, new.reference_number, new.invoice_number, new.notes);
+END;
+
+CREATE TRIGGER transactions_fts_delete AFTER DELETE ON transactions BEGIN
+    INSERT INTO transactions_fts (transactions_fts, rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES ('delete', old.rowid, old.description, old.customer_vendor, old.reference_number, old.invoice_number, old.notes);
+END;
+
+CREATE TRIGGER transactions_fts_update AFTER UPDATE OF description, customer_vendor, reference_number, invoice_number, notes ON transactions BEGIN
+    INSERT INTO transactions_fts (transactions_fts, rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES ('delete', old.rowid, old.description, old.customer_vendor, old.reference_number, old.invoice_number, old.notes);
+    INSERT INTO transactions_fts (rowid, description, customer_vendor, reference_number, invoice_number, notes)
+    VALUES (new.rowid, new.description, new.customer_vendor, new.reference_number, new.invoice_number, new.notes);
+END;
+
+INSERT INTO transactions_fts (transactions_fts) VALUES ('rebuild');`)
+	return err
+}
+
+// dropTransactionsFTSTriggers drops the triggers of the search index, which
+// does not need FTS5
+func dropTransactionsFTSTriggers(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+DROP TRIGGER IF EXISTS transactions_fts_update;
+DROP TRIGGER IF EXISTS transactions_fts_delete;
+DROP TRIGGER IF EXISTS transactions_fts_insert;`)
+	return err
+}
+
+// downTransactionsFTS drops the search index and its triggers
+func downTransactionsFTS(ctx context.Context, tx *sql.Tx) error {
+	if err := dropTransactionsFTSTriggers(ctx, tx); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS transactions_fts;`)
+	return err
+}
@@ -0,0 +1,6 @@
+-- +goose Up
+-- When an invoice or bill is due; aging reports count overdue days from it
+ALTER TABLE transactions ADD COLUMN due_date DATE;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN due_date;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Individual payments made against a transaction, in minor units of the
+-- transaction's currency. Voided payments are kept for the record but no
+-- longer count towards what has been paid.
+
+CREATE TABLE IF NOT EXISTS payments (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    transaction_id TEXT NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    payment_date DATE NOT NULL,
+    payment_method_id TEXT REFERENCES payment_methods(id),
+    reference TEXT,
+    notes TEXT,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    voided_at TIMESTAMP,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_transaction ON payments(transaction_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_payments_transaction;
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Spending limits per category and month, quarter or year, in minor units
+-- of the budget's currency. A budget covers its category's subcategories
+-- too. With rollover, what is left unspent in one period is added to the
+-- next, counting from the period containing start_date.
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    category_id TEXT NOT NULL,
+    period TEXT NOT NULL CHECK (period IN ('month', 'quarter', 'year')),
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    currency TEXT NOT NULL,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    alert_threshold REAL NOT NULL DEFAULT 1.0 CHECK (alert_threshold > 0),
+    start_date DATE NOT NULL,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+    UNIQUE (created_by, category_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS budgets;
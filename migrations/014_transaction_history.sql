@@ -0,0 +1,66 @@
+-- +goose Up
+-- Every change to a transaction, numbered per transaction. changes lists
+-- the fields that changed with their old and new values; snapshot is the
+-- transaction as it was after the change, so any version can be restored.
+-- Amounts are in minor units, as in transactions. Rows are never updated
+-- or deleted, and outlive the transactions they describe.
+
+CREATE TABLE IF NOT EXISTS transaction_history (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    transaction_id TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore', 'revert', 'purge')),
+    changes TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    changed_by TEXT NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, version)
+);
+
+CREATE TRIGGER IF NOT EXISTS transaction_history_no_update BEFORE UPDATE ON transaction_history BEGIN
+    SELECT RAISE(ABORT, 'transaction history is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS transaction_history_no_delete BEFORE DELETE ON transaction_history BEGIN
+    SELECT RAISE(ABORT, 'transaction history is append-only');
+END;
+
+-- Existing transactions start their history at their current state
+INSERT INTO transaction_history (transaction_id, version, action, changes, snapshot, changed_by, changed_at)
+SELECT
+    id,
+    1,
+    'create',
+    '[]',
+    json_object(
+        'type', type,
+        'description', description,
+        'amount', amount,
+        'transaction_date', date(transaction_date),
+        'category_id', COALESCE(category_id, ''),
+        'tags', COALESCE(tags, ''),
+        'customer_vendor', COALESCE(customer_vendor, ''),
+        'payment_method_id', COALESCE(payment_method_id, ''),
+        'payment_status', COALESCE(payment_status, ''),
+        'reference_number', COALESCE(reference_number, ''),
+        'invoice_number', COALESCE(invoice_number, ''),
+        'notes', COALESCE(notes, ''),
+        'attachments', COALESCE(attachments, ''),
+        'tax_amount', COALESCE(tax_amount, 0),
+        'discount_amount', COALESCE(discount_amount, 0),
+        'due_amount', COALESCE(due_amount, 0),
+        'currency', COALESCE(currency, ''),
+        'exchange_rate', CASE WHEN exchange_rate > 0 THEN exchange_rate ELSE 1.0 END,
+        'is_recurring', json(CASE WHEN is_recurring THEN 'true' ELSE 'false' END),
+        'recurring_frequency', COALESCE(recurring_frequency, ''),
+        'recurring_end_date', COALESCE(date(recurring_end_date), ''),
+        'due_date', COALESCE(date(due_date), '')
+    ),
+    created_by,
+    COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM transactions;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS transaction_history_no_delete;
+DROP TRIGGER IF EXISTS transaction_history_no_update;
+DROP TABLE IF EXISTS transaction_history;
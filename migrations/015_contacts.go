@@ -0,0 +1,141 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	register(15, "contacts", upContacts, downContacts)
+}
+
+// upContacts adds contacts and links transactions to them. The free-text
+// customer_vendor values already in use become contacts, one per user and
+// name, where names differing only in case, punctuation or spacing ("Acme
+// Ltd", "ACME Ltd.") count as the same. Each contact takes the spelling used
+// most, which its transactions are then given too.
+func upContacts(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `
+CREATE TABLE contacts (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'both' CHECK (type IN ('customer', 'vendor', 'both')),
+    email TEXT,
+    phone TEXT,
+    address TEXT,
+    tax_id TEXT,
+    default_category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+    default_payment_method_id TEXT REFERENCES payment_methods(id) ON DELETE SET NULL,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_contacts_created_by ON contacts(created_by, name);
+
+ALTER TABLE transactions ADD COLUMN contact_id TEXT REFERENCES contacts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_contact_id ON transactions(contact_id);`); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+SELECT created_by, customer_vendor, type, COUNT(*)
+FROM transactions
+WHERE customer_vendor IS NOT NULL AND trim(customer_vendor) != ''
+GROUP BY created_by, customer_vendor, type
+ORDER BY created_by, MIN(created_at), customer_vendor`)
+	if err != nil {
+		return err
+	}
+
+	// A contact found among the transactions of one user
+	type found struct {
+		createdBy string
+		spellings []string
+		uses      map[string]int
+		customer  bool
+		vendor    bool
+	}
+	var contacts []*found
+	byKey := map[string]*found{}
+	for rows.Next() {
+		var createdBy, name, txType string
+		var count int
+		if err := rows.Scan(&createdBy, &name, &txType, &count); err != nil {
+			rows.Close()
+			return err
+		}
+		key := createdBy + "\x00" + contactKey(name)
+		c := byKey[key]
+		if c == nil {
+			c = &found{createdBy: createdBy, uses: map[string]int{}}
+			byKey[key] = c
+			contacts = append(contacts, c)
+		}
+		if _, ok := c.uses[name]; !ok {
+			c.spellings = append(c.spellings, name)
+		}
+		c.uses[name] += count
+		switch txType {
+		case "income", "sale":
+			c.customer = true
+		default:
+			c.vendor = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range contacts {
+		name := c.spellings[0]
+		for _, spelling := range c.spellings[1:] {
+			if c.uses[spelling] > c.uses[name] {
+				name = spelling
+			}
+		}
+		contactType := "both"
+		if !c.vendor {
+			contactType = "customer"
+		} else if !c.customer {
+			contactType = "vendor"
+		}
+
+		var id string
+		if err := tx.QueryRowContext(ctx, `INSERT INTO contacts (name, type, created_by) VALUES (?, ?, ?) RETURNING id`,
+			strings.TrimSpace(name), contactType, c.createdBy).Scan(&id); err != nil {
+			return fmt.Errorf("failed to create contact %q: %w", name, err)
+		}
+		for _, spelling := range c.spellings {
+			if _, err := tx.ExecContext(ctx, `UPDATE transactions SET contact_id = ?, customer_vendor = ? WHERE created_by = ? AND customer_vendor = ?`,
+				id, strings.TrimSpace(name), c.createdBy, spelling); err != nil {
+				return fmt.Errorf("failed to link transactions to contact %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// downContacts unlinks transactions and drops contacts. Transactions keep
+// the names they were given.
+func downContacts(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+DROP INDEX IF EXISTS idx_transactions_contact_id;
+ALTER TABLE transactions DROP COLUMN contact_id;
+DROP TABLE IF EXISTS contacts;`)
+	return err
+}
+
+// contactKey reduces a name to what tells contacts apart: lower case
+// letters and digits separated by single spaces. It is a copy of the rule
+// the contact service applies, frozen as it was when this migration ran.
+func contactKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
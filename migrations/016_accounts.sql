@@ -0,0 +1,48 @@
+-- +goose Up
+-- Bank, cash and card accounts money moves in and out of. The opening
+-- balance, in minor units of the account's currency, is what the account
+-- held at the start of opening_date; transactions before that are already
+-- part of it. Transfers move money between two accounts of a user and count
+-- as neither income nor expense; to_amount is what arrived, in the currency
+-- of the receiving account.
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    name TEXT NOT NULL COLLATE NOCASE,
+    type TEXT NOT NULL DEFAULT 'bank' CHECK (type IN ('bank', 'cash', 'card', 'other')),
+    currency TEXT NOT NULL,
+    opening_balance INTEGER NOT NULL DEFAULT 0,
+    opening_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (created_by, name)
+);
+
+CREATE TABLE IF NOT EXISTS transfers (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    from_account_id TEXT NOT NULL REFERENCES accounts(id),
+    to_account_id TEXT NOT NULL REFERENCES accounts(id),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    to_amount INTEGER NOT NULL CHECK (to_amount > 0),
+    transfer_date DATE NOT NULL,
+    description TEXT,
+    reference TEXT,
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_account_id != to_account_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transfers_from_account_id ON transfers(from_account_id);
+CREATE INDEX IF NOT EXISTS idx_transfers_to_account_id ON transfers(to_account_id);
+
+ALTER TABLE transactions ADD COLUMN account_id TEXT REFERENCES accounts(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions(account_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_account_id;
+ALTER TABLE transactions DROP COLUMN account_id;
+DROP TABLE IF EXISTS transfers;
+DROP TABLE IF EXISTS accounts;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Bank reconciliation. A reconciliation compares an account with one bank
+-- statement: its period, its ending balance and its lines, in minor units
+-- of the account's currency, with money in positive and money out negative.
+-- Statement lines are matched to the transactions they stand for. Each
+-- transaction is uncleared, cleared (seen on a statement being reconciled)
+-- or reconciled (part of a finished reconciliation), and reconciled
+-- transactions are locked against changes.
+
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    account_id TEXT NOT NULL REFERENCES accounts(id),
+    statement_start_date DATE NOT NULL,
+    statement_end_date DATE NOT NULL,
+    ending_balance INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed')),
+    created_by TEXT NOT NULL DEFAULT 'default' REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    CHECK (statement_start_date <= statement_end_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliations_account_id ON reconciliations(account_id, statement_end_date);
+
+CREATE TABLE IF NOT EXISTS statement_lines (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+    reconciliation_id TEXT NOT NULL REFERENCES reconciliations(id) ON DELETE CASCADE,
+    line_date DATE NOT NULL,
+    description TEXT,
+    amount INTEGER NOT NULL,
+    reference TEXT,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    match_type TEXT CHECK (match_type IN ('auto', 'manual')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_statement_lines_reconciliation_id ON statement_lines(reconciliation_id, line_date);
+CREATE INDEX IF NOT EXISTS idx_statement_lines_transaction_id ON statement_lines(transaction_id);
+
+ALTER TABLE transactions ADD COLUMN cleared_status TEXT NOT NULL DEFAULT 'uncleared' CHECK (cleared_status IN ('uncleared', 'cleared', 'reconciled'));
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN cleared_status;
+DROP TABLE IF EXISTS statement_lines;
+DROP TABLE IF EXISTS reconciliations;
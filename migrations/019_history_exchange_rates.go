@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+func init() {
+	register(19, "history_exchange_rates", upHistoryExchangeRates, downHistoryExchangeRates)
+}
+
+// upHistoryExchangeRates gives history snapshots recorded without an
+// exchange rate, stored as 0, the rate 1 instead, which is the column
+// default and means the same to the currency converter, so those versions
+// can be reverted to. The history is otherwise append-only, so the trigger
+// guarding it is lifted for the repair.
+func upHistoryExchangeRates(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+DROP TRIGGER IF EXISTS transaction_history_no_update;
+
+UPDATE transaction_history
+SET snapshot = json_set(snapshot, '$.exchange_rate', 1.0)
+WHERE COALESCE(json_extract(snapshot, '$.exchange_rate'), 0) <= 0;
+
+CREATE TRIGGER IF NOT EXISTS transaction_history_no_update BEFORE UPDATE ON transaction_history BEGIN
+    SELECT RAISE(ABORT, 'transaction history is append-only');
+END;`)
+	return err
+}
+
+// downHistoryExchangeRates leaves the repaired snapshots as they are; a rate
+// of 1 means the same as none
+func downHistoryExchangeRates(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
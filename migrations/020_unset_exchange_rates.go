@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+func init() {
+	register(20, "unset_exchange_rates", upUnsetExchangeRates, downUnsetExchangeRates)
+}
+
+// upUnsetExchangeRates stores exchange rates that were never entered as
+// NULL. Until now a missing rate was saved as 1, and the currency converter
+// took 1 to mean none, so the rate of 1 on transactions and in history
+// snapshots is cleared; from here on 1 is an actual rate. The history is
+// otherwise append-only, so the trigger guarding it is lifted for the
+// repair.
+func upUnsetExchangeRates(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+UPDATE transactions SET exchange_rate = NULL WHERE exchange_rate = 1 OR exchange_rate <= 0;
+
+DROP TRIGGER IF EXISTS transaction_history_no_update;
+
+UPDATE transaction_history
+SET snapshot = json_set(snapshot, '$.exchange_rate', 0)
+WHERE json_extract(snapshot, '$.exchange_rate') = 1;
+
+CREATE TRIGGER IF NOT EXISTS transaction_history_no_update BEFORE UPDATE ON transaction_history BEGIN
+    SELECT RAISE(ABORT, 'transaction history is append-only');
+END;`)
+	return err
+}
+
+// downUnsetExchangeRates leaves the rates as they are; a NULL rate is what
+// the earlier schema saved as 1
+func downUnsetExchangeRates(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+// Package migrations holds the versioned database schema.
+//
+// SQL migrations are the numbered *.sql files in this directory, split into
+// "-- +goose Up" and "-- +goose Down" sections. Changes that cannot be
+// expressed in plain SQL (such as rebuilding a table whose existing shape
+// varies between installs) are registered as Go migrations from files named
+// after the same numbering scheme.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+)
+
+// FS contains the SQL migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+// MigrationFunc applies one direction of a Go migration inside a transaction
+type MigrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+// GoMigration is a migration implemented in Go
+type GoMigration struct {
+	Version int64
+	Name    string
+	Up      MigrationFunc
+	Down    MigrationFunc
+}
+
+var goMigrations []GoMigration
+
+// register adds a Go migration; it is called from init functions
+func register(version int64, name string, up, down MigrationFunc) {
+	goMigrations = append(goMigrations, GoMigration{
+		Version: version,
+		Name:    name,
+		Up:      up,
+		Down:    down,
+	})
+}
+
+// GoMigrations returns every registered Go migration
+func GoMigrations() []GoMigration {
+	return append([]GoMigration(nil), goMigrations...)
+}
@@ -0,0 +1,150 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// rebuildTable replaces table with the definition in newTableSQL, which must
+// create "<table>_new". This is the SQLite-recommended way to change things
+// ALTER TABLE cannot, such as the expression of a generated column.
+//
+// Every column the old and new tables share is copied over; exprs can supply
+// a SELECT expression (evaluated against the old table) for any new column.
+// Indexes and triggers of the old table are recreated afterwards, except
+// those referring to columns that no longer exist. Copying assigns new
+// rowids, so a "<table>_fts" full-text index reading from the table is
+// rebuilt. Foreign key enforcement is switched off by the migration runner
+// while this runs.
+func rebuildTable(ctx context.Context, tx *sql.Tx, table, newTableSQL string, exprs map[string]string) error {
+	tmp := table + "_new"
+
+	oldCols, err := storedColumns(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+
+	// Remember dependent schema objects; DROP TABLE removes them
+	rows, err := tx.QueryContext(ctx, `SELECT name, sql FROM sqlite_master
+WHERE tbl_name = ? AND type IN ('index', 'trigger') AND sql IS NOT NULL
+ORDER BY type, name`, table)
+	if err != nil {
+		return fmt.Errorf("failed to read schema of %s: %w", table, err)
+	}
+	type schemaObject struct{ name, sql string }
+	var dependents []schemaObject
+	for rows.Next() {
+		var obj schemaObject
+		if err := rows.Scan(&obj.name, &obj.sql); err != nil {
+			rows.Close()
+			return err
+		}
+		dependents = append(dependents, obj)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, newTableSQL); err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+
+	newCols, err := columnNames(ctx, tx, tmp)
+	if err != nil {
+		return err
+	}
+
+	var targets, sources []string
+	for _, col := range newCols {
+		if expr, ok := exprs[col]; ok {
+			targets = append(targets, col)
+			sources = append(sources, expr)
+		} else if oldCols[col] {
+			targets = append(targets, col)
+			sources = append(sources, col)
+		}
+	}
+
+	copySQL := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+		tmp, strings.Join(targets, ", "), strings.Join(sources, ", "), table)
+	if _, err := tx.ExecContext(ctx, copySQL); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", table, err)
+	}
+
+	// legacy_alter_table stops RENAME from validating triggers on other
+	// tables that refer to the table while it is briefly missing
+	stmts := []string{
+		fmt.Sprintf("DROP TABLE %s", table),
+		"PRAGMA legacy_alter_table = ON",
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmp, table),
+		"PRAGMA legacy_alter_table = OFF",
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", table, err)
+		}
+	}
+
+	for _, obj := range dependents {
+		if _, err := tx.ExecContext(ctx, obj.sql); err != nil {
+			if strings.Contains(err.Error(), "no such column") {
+				continue
+			}
+			return fmt.Errorf("failed to recreate %s: %w", obj.name, err)
+		}
+	}
+
+	fts := table + "_fts"
+	var indexed int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, fts).Scan(&indexed); err != nil {
+		return err
+	}
+	if indexed > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES ('rebuild')", fts, fts)); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", fts, err)
+		}
+	}
+	return nil
+}
+
+// storedColumns returns the non-generated columns of table
+func storedColumns(ctx context.Context, tx *sql.Tx, table string) (map[string]bool, error) {
+	cols, err := columnNames(ctx, tx, table)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		set[col] = true
+	}
+	return set, nil
+}
+
+// columnNames lists the non-generated columns of table in declaration order
+func columnNames(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_xinfo(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var (
+			cid, notNull, pk, hidden int
+			name, colType            string
+			dflt                     sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk, &hidden); err != nil {
+			return nil, err
+		}
+		// hidden is 2 or 3 for virtual and stored generated columns
+		if hidden == 0 {
+			cols = append(cols, name)
+		}
+	}
+	return cols, rows.Err()
+}